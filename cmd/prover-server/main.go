@@ -0,0 +1,88 @@
+// Command prover-server serves the remote proving API backed by the Miden VM
+// CLI installed on this machine.
+//
+// The server listens on the loopback interface by default.  Listening on other
+// addresses requires a token, which clients send as a bearer token; the token is
+// read from the PROVER_TOKEN environment variable unless -token is set.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"runtime"
+	"syscall"
+	"time"
+
+	"github.com/qredo/verifiable-oracles/pkg/miden"
+	"github.com/qredo/verifiable-oracles/pkg/miden/remote"
+)
+
+func main() {
+	var (
+		addr      = flag.String("addr", "127.0.0.1:8080", "address to listen on")
+		token     = flag.String("token", os.Getenv("PROVER_TOKEN"), "bearer token required from clients")
+		workers   = flag.Int("workers", runtime.NumCPU(), "number of concurrent jobs")
+		maxJobs   = flag.Int("max-jobs", 64, "maximum number of unfinished jobs")
+		maxBytes  = flag.Int64("max-request-bytes", 16<<20, "maximum size of request bodies in bytes")
+		retention = flag.Duration("retention", 10*time.Minute, "how long finished jobs can be polled")
+	)
+	flag.Parse()
+
+	if *token == "" && !isLoopback(*addr) {
+		log.Fatalf("refusing to serve on %s without a token", *addr)
+	}
+
+	version, err := miden.Version(context.Background())
+	if err != nil {
+		log.Fatalf("miden not available: %v", err)
+	}
+
+	server := remote.NewServer(miden.Local{}, remote.ServerConfig{
+		Workers:         *workers,
+		MaxJobs:         *maxJobs,
+		Retention:       *retention,
+		MaxRequestBytes: *maxBytes,
+		Token:           *token,
+	})
+	defer server.Close()
+
+	httpServer := &http.Server{
+		Addr:              *addr,
+		Handler:           server,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		httpServer.Shutdown(shutdownCtx)
+	}()
+
+	log.Printf("serving %s with %d workers on %s", version, *workers, *addr)
+	if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Fatal(err)
+	}
+}
+
+// Reports whether addr only accepts connections from this machine.
+func isLoopback(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -25,6 +25,9 @@ func (d *Decoder) Decode(x any) (int, error) {
 	return d.DecodeValue(reflect.ValueOf(x))
 }
 
+// DecodeValue decodes into the value x points to, the inverse of
+// Encoder.EncodeValue.  Slices are decoded to their current length, or to the
+// size of their struct field tag, and nil pointers are allocated.
 func (d *Decoder) DecodeValue(x reflect.Value) (int, error) {
 	if !x.IsValid() {
 		return 0, errors.New("decode invalid reflect.Value")
@@ -34,25 +37,105 @@ func (d *Decoder) DecodeValue(x reflect.Value) (int, error) {
 	default:
 		return 0, fmt.Errorf("decode to unsupported kind %s", x.Kind().String())
 	case reflect.Pointer:
-		switch x.Elem().Kind() {
-		default:
-			return 0, fmt.Errorf("decode to unsupported type %s", x.Type().String())
-		case reflect.Uint64:
-			v := x.Interface().(*uint64)
-			return 1, d.decodeUint64(v)
-		case reflect.Uint32:
-			v := x.Interface().(*uint32)
-			return 1, d.decodeUint32(v)
-		case reflect.Uint16:
-			v := x.Interface().(*uint16)
-			return 1, d.decodeUint16(v)
-		case reflect.Uint8:
-			v := x.Interface().(*uint8)
-			return 1, d.decodeUint8(v)
+		if x.IsNil() {
+			return 0, errors.New("decode nil")
 		}
+		return d.decodeValue(x.Elem())
 	}
 }
 
+func (d *Decoder) decodeValue(x reflect.Value) (int, error) {
+	if x.Type() == _elementType {
+		v := make(Vector, 1)
+		if n, err := d.r.ReadVector(v); err != nil {
+			return 0, err
+		} else if n < 1 {
+			return 0, errors.New("not enough elements")
+		}
+		x.Set(reflect.ValueOf(v[0]))
+		return 1, nil
+	}
+
+	switch x.Kind() {
+	default:
+		return 0, fmt.Errorf("decode to unsupported type %s", x.Type().String())
+	case reflect.Uint64:
+		var v uint64
+		err := d.decodeUint64(&v)
+		x.SetUint(v)
+		return 1, err
+	case reflect.Uint32:
+		var v uint32
+		err := d.decodeUint32(&v)
+		x.SetUint(uint64(v))
+		return 1, err
+	case reflect.Uint16:
+		var v uint16
+		err := d.decodeUint16(&v)
+		x.SetUint(uint64(v))
+		return 1, err
+	case reflect.Uint8:
+		var v uint8
+		err := d.decodeUint8(&v)
+		x.SetUint(uint64(v))
+		return 1, err
+	case reflect.Struct:
+		return d.decodeStruct(x)
+	case reflect.Array, reflect.Slice:
+		return d.decodeSequence(x)
+	case reflect.Pointer:
+		if x.IsNil() {
+			x.Set(reflect.New(x.Type().Elem()))
+		}
+		return d.decodeValue(x.Elem())
+	}
+}
+
+func (d *Decoder) decodeStruct(x reflect.Value) (int, error) {
+	fields, err := structFields(x.Type())
+	if err != nil {
+		return 0, err
+	}
+
+	n := 0
+	for _, f := range fields {
+		v := x.Field(f.index)
+		if f.size >= 0 && v.Kind() == reflect.Slice {
+			v.Set(reflect.MakeSlice(v.Type(), f.size, f.size))
+		}
+
+		m, err := d.decodeValue(v)
+		n += m
+		if err != nil {
+			return n, fmt.Errorf("field %s: %w", f.name, err)
+		}
+	}
+	return n, nil
+}
+
+func (d *Decoder) decodeSequence(x reflect.Value) (int, error) {
+	if isBytes(x.Type()) {
+		b, err := d.decodeNBytes(x.Len())
+		if err != nil {
+			return 0, err
+		}
+		for i := range b {
+			x.Index(i).SetUint(uint64(b[i]))
+		}
+		return 1, nil
+	}
+
+	n := 0
+	for i := 0; i < x.Len(); i++ {
+		m, err := d.decodeValue(x.Index(i))
+		n += m
+		if err != nil {
+			return n, fmt.Errorf("index %d: %w", i, err)
+		}
+	}
+	return n, nil
+}
+
 func (d *Decoder) DecodeBytes(b []byte) (int, error) {
 	l := len(b)
 	m := l / _bytesInElement
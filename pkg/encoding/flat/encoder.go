@@ -27,17 +27,24 @@ func (e *Encoder) Encode(x any) (int, error) {
 	return e.EncodeValue(reflect.ValueOf(x))
 }
 
+// EncodeValue encodes x and returns the number of values encoded: one per
+// unsigned integer, element or byte sequence, and the sum of the values of
+// their fields or items for structs, arrays and slices.  Byte arrays and
+// slices are encoded as EncodeBytes does, and pointers as their target.
 func (e *Encoder) EncodeValue(x reflect.Value) (int, error) {
 	if !x.IsValid() {
 		return 0, errors.New("invalid reflect.Value")
 
 	}
+	if x.Type() == _elementType {
+		return 1, e.EncodeElement(x.Interface().(Element))
+	}
 
 	switch x.Kind() {
 	default:
 		return 0, fmt.Errorf("uknown Kind %s", x.Kind().String())
 	case reflect.Uint64:
-		v := x.Interface().(uint64)
+		v := x.Uint()
 		return 1, e.encodeUint64(v)
 	case reflect.Uint32:
 		v := uint32(x.Uint())
@@ -48,7 +55,59 @@ func (e *Encoder) EncodeValue(x reflect.Value) (int, error) {
 	case reflect.Uint8:
 		v := uint8(x.Uint())
 		return 1, e.encodeUint8(v)
+	case reflect.Struct:
+		return e.encodeStruct(x)
+	case reflect.Array, reflect.Slice:
+		return e.encodeSequence(x)
+	case reflect.Pointer:
+		if x.IsNil() {
+			return 0, errors.New("encode nil pointer")
+		}
+		return e.EncodeValue(x.Elem())
+	}
+}
+
+func (e *Encoder) encodeStruct(x reflect.Value) (int, error) {
+	fields, err := structFields(x.Type())
+	if err != nil {
+		return 0, err
+	}
+
+	n := 0
+	for _, f := range fields {
+		v := x.Field(f.index)
+		if f.size >= 0 && v.Len() != f.size {
+			return n, fmt.Errorf("field %s: length %d, want %d", f.name, v.Len(), f.size)
+		}
+
+		m, err := e.EncodeValue(v)
+		n += m
+		if err != nil {
+			return n, fmt.Errorf("field %s: %w", f.name, err)
+		}
+	}
+	return n, nil
+}
+
+func (e *Encoder) encodeSequence(x reflect.Value) (int, error) {
+	if isBytes(x.Type()) {
+		b := make([]byte, x.Len())
+		for i := range b {
+			b[i] = byte(x.Index(i).Uint())
+		}
+		_, err := e.EncodeBytes(b)
+		return 1, err
+	}
+
+	n := 0
+	for i := 0; i < x.Len(); i++ {
+		m, err := e.EncodeValue(x.Index(i))
+		n += m
+		if err != nil {
+			return n, fmt.Errorf("index %d: %w", i, err)
+		}
 	}
+	return n, nil
 }
 
 // Note that there will be padding added at the end
@@ -0,0 +1,80 @@
+package flat
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Structs are encoded as the concatenation of the encodings of their exported
+// fields, in declaration order, recursively.  The encoding of a field can be
+// changed with a tag of the form
+//
+//	`flat:"name,size=n"`
+//
+// where name replaces the field name in errors, and size fixes the length of
+// a slice, so that it can be decoded.  Both are optional.  The tag `flat:"-"`
+// skips the field.
+
+// Encoding of a struct field
+type structField struct {
+	index int
+	name  string
+	// Fixed length of slices and arrays, -1 if not specified
+	size int
+}
+
+var _elementType = reflect.TypeOf(Element{})
+
+// Returns the encoded fields of the struct type t.
+func structFields(t reflect.Type) ([]structField, error) {
+	fields := make([]structField, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+
+		tag := f.Tag.Get("flat")
+		if tag == "-" {
+			continue
+		}
+
+		sf := structField{index: i, name: f.Name, size: -1}
+		name, opts, _ := strings.Cut(tag, ",")
+		if name != "" {
+			sf.name = name
+		}
+		for opts != "" {
+			var opt string
+			opt, opts, _ = strings.Cut(opts, ",")
+
+			key, value, _ := strings.Cut(opt, "=")
+			switch key {
+			default:
+				return nil, fmt.Errorf("field %s: unknown flat tag option %q", f.Name, opt)
+			case "size":
+				size, err := strconv.Atoi(value)
+				if err != nil || size < 0 {
+					return nil, fmt.Errorf("field %s: invalid size %q", f.Name, value)
+				}
+				kind := f.Type.Kind()
+				if kind != reflect.Slice && kind != reflect.Array {
+					return nil, fmt.Errorf("field %s: size of a %s", f.Name, kind)
+				}
+				if kind == reflect.Array && f.Type.Len() != size {
+					return nil, fmt.Errorf("field %s: size %d of an array of length %d", f.Name, size, f.Type.Len())
+				}
+				sf.size = size
+			}
+		}
+		fields = append(fields, sf)
+	}
+	return fields, nil
+}
+
+// Returns whether sequences of t are encoded as bytes.
+func isBytes(t reflect.Type) bool {
+	return t.Elem().Kind() == reflect.Uint8
+}
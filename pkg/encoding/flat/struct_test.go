@@ -0,0 +1,185 @@
+package flat_test
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/qredo/verifiable-oracles/pkg/elements"
+	"github.com/qredo/verifiable-oracles/pkg/encoding/flat"
+	field "github.com/qredo/verifiable-oracles/pkg/goldilocks"
+	"github.com/qredo/verifiable-oracles/pkg/oracle"
+	"github.com/qredo/verifiable-oracles/pkg/prover"
+)
+
+type inner struct {
+	A uint16
+	B [2]uint32
+}
+
+type outer struct {
+	Number  uint64
+	Inner   inner
+	Pointer *inner
+	Felt    Element
+	Felts   Vector `flat:"felts,size=2"`
+	Bytes   []byte `flat:",size=5"`
+	Skipped uint64 `flat:"-"`
+	private uint64
+}
+
+func encode(t *testing.T, x any) (Vector, int) {
+	var buff elements.ElementBuffer
+	n, err := flat.NewEncoder(&buff).Encode(x)
+	require.NoError(t, err)
+	return buff.Vector(), n
+}
+
+func TestStructRoundTrip(t *testing.T) {
+	assert := assert.New(t)
+
+	x := outer{
+		Number:  0x12_34_56_78_9A,
+		Inner:   inner{A: 1, B: [2]uint32{2, 3}},
+		Pointer: &inner{A: 4, B: [2]uint32{5, 6}},
+		Felt:    field.NewElement(7),
+		Felts:   Vector{field.NewElement(8), field.NewElement(9)},
+		Bytes:   []byte{0x12, 0x34, 0x56, 0x78, 0x9A},
+		Skipped: 10,
+		private: 11,
+	}
+
+	v, n := encode(t, x)
+	assert.Equal(11, n)
+	assert.Equal(Vector{
+		field.NewElement(0x12), field.NewElement(0x34_56_78_9A),
+		field.NewElement(1), field.NewElement(2), field.NewElement(3),
+		field.NewElement(4), field.NewElement(5), field.NewElement(6),
+		field.NewElement(7),
+		field.NewElement(8), field.NewElement(9),
+		field.NewElement(0x12_34_56_78), field.NewElement(0x9A),
+	}, v)
+
+	var buff elements.ElementBuffer
+	_, err := buff.WriteVector(v)
+	require.NoError(t, err)
+
+	var y outer
+	m, err := flat.NewDecoder(&buff).Decode(&y)
+	assert.NoError(err)
+	assert.Equal(n, m)
+
+	x.Skipped, x.private = 0, 0
+	assert.Equal(x, y)
+}
+
+func TestStructErrors(t *testing.T) {
+	table := map[string]struct {
+		input any
+		err   string
+	}{
+		"size mismatch": {
+			input: outer{Pointer: &inner{}, Felts: Vector{{}}, Bytes: make([]byte, 5)},
+			err:   "field felts: length 1, want 2",
+		},
+		"nil pointer": {
+			input: outer{Felts: make(Vector, 2), Bytes: make([]byte, 5)},
+			err:   "field Pointer: encode nil pointer",
+		},
+		"unsupported field": {
+			input: struct{ S string }{},
+			err:   "field S: uknown Kind string",
+		},
+		"unknown option": {
+			input: struct {
+				A uint64 `flat:",fixed"`
+			}{},
+			err: `field A: unknown flat tag option "fixed"`,
+		},
+		"invalid size": {
+			input: struct {
+				A []uint64 `flat:",size=-1"`
+			}{},
+			err: `field A: invalid size "-1"`,
+		},
+		"size of scalar": {
+			input: struct {
+				A uint64 `flat:",size=1"`
+			}{},
+			err: "field A: size of a uint64",
+		},
+		"array size": {
+			input: struct {
+				A [2]uint64 `flat:",size=3"`
+			}{},
+			err: "field A: size 3 of an array of length 2",
+		},
+	}
+	for name, tc := range table {
+		t.Run(name, func(t *testing.T) {
+			var buff elements.ElementBuffer
+			_, err := flat.NewEncoder(&buff).Encode(tc.input)
+			assert.EqualError(t, err, tc.err)
+		})
+	}
+
+	// Decoding a struct with missing elements
+	var buff elements.ElementBuffer
+	_, err := flat.NewEncoder(&buff).Encode(inner{A: 1})
+	require.NoError(t, err)
+	_, err = buff.ReadVector(make(Vector, 1))
+	require.NoError(t, err)
+	var x inner
+	_, err = flat.NewDecoder(&buff).Decode(&x)
+	assert.Error(t, err)
+}
+
+func TestBlockFact(t *testing.T) {
+	assert := assert.New(t)
+
+	fact := oracle.BlockFact{
+		BlockNumber: 17_000_000,
+		BlockHash:   common.HexToHash("0x0102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f20"),
+	}
+	v, n := encode(t, fact)
+	assert.Equal(2, n)
+	assert.Len(v, 10)
+	assert.Equal(field.NewElement(17_000_000), v[1])
+	assert.Equal(field.NewElement(0x01_02_03_04), v[2])
+	assert.Equal(field.NewElement(0x1d_1e_1f_20), v[9])
+
+	var buff elements.ElementBuffer
+	_, err := buff.WriteVector(v)
+	require.NoError(t, err)
+	var decoded oracle.BlockFact
+	_, err = flat.NewDecoder(&buff).Decode(&decoded)
+	assert.NoError(err)
+	assert.Equal(fact, decoded)
+}
+
+func TestTranscript(t *testing.T) {
+	assert := assert.New(t)
+
+	transcript := prover.Transcript{
+		BlockNumber:      17_000_000,
+		BlockHash:        common.HexToHash("0x01"),
+		TransactionIndex: 42,
+		TransactionHash:  common.HexToHash("0x02"),
+	}
+	v, n := encode(t, transcript)
+	assert.Equal(4, n)
+	assert.Len(v, 20)
+	assert.Equal(field.NewElement(1), v[9])
+	assert.Equal(field.NewElement(42), v[11])
+	assert.Equal(field.NewElement(2), v[19])
+
+	var buff elements.ElementBuffer
+	_, err := buff.WriteVector(v)
+	require.NoError(t, err)
+	var decoded prover.Transcript
+	_, err = flat.NewDecoder(&buff).Decode(&decoded)
+	assert.NoError(err)
+	assert.Equal(transcript, decoded)
+}
@@ -0,0 +1,243 @@
+package goldilocks
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+)
+
+// E2 is an element of the quadratic extension of the Goldilocks field
+// F[φ]/(φ² - φ + 2), A0 + A1·φ, as used by Winterfell and Miden VM.
+type E2 struct {
+	A0, A1 Element
+}
+
+// NewE2 returns a0 + a1·φ.
+func NewE2(a0, a1 uint64) E2 {
+	return E2{A0: NewElement(a0), A1: NewElement(a1)}
+}
+
+// SetZero sets z to 0 and returns z.
+func (z *E2) SetZero() *E2 {
+	z.A0.SetZero()
+	z.A1.SetZero()
+	return z
+}
+
+// SetOne sets z to 1 and returns z.
+func (z *E2) SetOne() *E2 {
+	z.A0.SetOne()
+	z.A1.SetZero()
+	return z
+}
+
+// SetElement sets z to the base field element x and returns z.
+func (z *E2) SetElement(x *Element) *E2 {
+	z.A0.Set(x)
+	z.A1.SetZero()
+	return z
+}
+
+// Set sets z to x and returns z.
+func (z *E2) Set(x *E2) *E2 {
+	*z = *x
+	return z
+}
+
+// SetRandom sets z to a uniform random value and returns z.
+func (z *E2) SetRandom() (*E2, error) {
+	if _, err := z.A0.SetRandom(); err != nil {
+		return nil, err
+	}
+	if _, err := z.A1.SetRandom(); err != nil {
+		return nil, err
+	}
+	return z, nil
+}
+
+// Equal returns z == x.
+func (z *E2) Equal(x *E2) bool {
+	return z.A0.Equal(&x.A0) && z.A1.Equal(&x.A1)
+}
+
+// IsZero returns z == 0.
+func (z *E2) IsZero() bool {
+	return z.A0.IsZero() && z.A1.IsZero()
+}
+
+// IsOne returns z == 1.
+func (z *E2) IsOne() bool {
+	return z.A0.IsOne() && z.A1.IsZero()
+}
+
+// InBaseField returns whether z is an element of the base field.
+func (z *E2) InBaseField() bool {
+	return z.A1.IsZero()
+}
+
+// Add sets z to x + y and returns z.
+func (z *E2) Add(x, y *E2) *E2 {
+	z.A0.Add(&x.A0, &y.A0)
+	z.A1.Add(&x.A1, &y.A1)
+	return z
+}
+
+// Sub sets z to x - y and returns z.
+func (z *E2) Sub(x, y *E2) *E2 {
+	z.A0.Sub(&x.A0, &y.A0)
+	z.A1.Sub(&x.A1, &y.A1)
+	return z
+}
+
+// Double sets z to 2x and returns z.
+func (z *E2) Double(x *E2) *E2 {
+	z.A0.Double(&x.A0)
+	z.A1.Double(&x.A1)
+	return z
+}
+
+// Neg sets z to -x and returns z.
+func (z *E2) Neg(x *E2) *E2 {
+	z.A0.Neg(&x.A0)
+	z.A1.Neg(&x.A1)
+	return z
+}
+
+// Mul sets z to x·y and returns z.
+func (z *E2) Mul(x, y *E2) *E2 {
+	// (a0 + a1·φ)(b0 + b1·φ) = a0·b0 - 2·a1·b1 + ((a0 + a1)(b0 + b1) - a0·b0)·φ
+	var a, b, c, d Element
+	a.Mul(&x.A0, &y.A0)
+	b.Mul(&x.A1, &y.A1)
+	c.Add(&x.A0, &x.A1)
+	d.Add(&y.A0, &y.A1)
+
+	z.A1.Mul(&c, &d).Sub(&z.A1, &a)
+	z.A0.Double(&b)
+	z.A0.Sub(&a, &z.A0)
+	return z
+}
+
+// MulByElement sets z to x·y, where y is in the base field, and returns z.
+func (z *E2) MulByElement(x *E2, y *Element) *E2 {
+	z.A0.Mul(&x.A0, y)
+	z.A1.Mul(&x.A1, y)
+	return z
+}
+
+// Square sets z to x² and returns z.
+func (z *E2) Square(x *E2) *E2 {
+	return z.Mul(x, x)
+}
+
+// Frobenius sets z to x^q, the conjugate of x, and returns z.
+func (z *E2) Frobenius(x *E2) *E2 {
+	// φ^q = 1 - φ
+	var a0 Element
+	a0.Add(&x.A0, &x.A1)
+	z.A1.Neg(&x.A1)
+	z.A0 = a0
+	return z
+}
+
+// Norm returns x·x^q, which is in the base field.
+func (z *E2) Norm() Element {
+	// a0² + a0·a1 + 2·a1²
+	var n, t Element
+	t.Add(&z.A0, &z.A1)
+	n.Mul(&z.A0, &t)
+	t.Square(&z.A1).Double(&t)
+	return *n.Add(&n, &t)
+}
+
+// Inverse sets z to x⁻¹ and returns z.  The inverse of 0 is 0.
+func (z *E2) Inverse(x *E2) *E2 {
+	n := x.Norm()
+	n.Inverse(&n)
+
+	var conj E2
+	conj.Frobenius(x)
+	return z.MulByElement(&conj, &n)
+}
+
+// Exp sets z to xᵏ and returns z.
+func (z *E2) Exp(x E2, k *big.Int) *E2 {
+	if k.Sign() == -1 {
+		x.Inverse(&x)
+		k = new(big.Int).Neg(k)
+	}
+
+	z.SetOne()
+	for i := k.BitLen() - 1; i >= 0; i-- {
+		z.Square(z)
+		if k.Bit(i) == 1 {
+			z.Mul(z, &x)
+		}
+	}
+	return z
+}
+
+// String returns the decimal representation of z as "A0+A1*φ".
+func (z *E2) String() string {
+	return z.A0.String() + "+" + z.A1.String() + "*φ"
+}
+
+// MarshalJSON encodes z as a JSON array of its coefficients.
+func (z *E2) MarshalJSON() ([]byte, error) {
+	return json.Marshal([2]*Element{&z.A0, &z.A1})
+}
+
+// UnmarshalJSON decodes z from a JSON array of its coefficients.
+func (z *E2) UnmarshalJSON(data []byte) error {
+	var a [2]Element
+	if err := json.Unmarshal(data, &a); err != nil {
+		return err
+	}
+	z.A0, z.A1 = a[0], a[1]
+	return nil
+}
+
+// MarshalBinary encodes z as the big-endian bytes of its coefficients.
+func (z *E2) MarshalBinary() ([]byte, error) {
+	a0, a1 := z.A0.Bytes(), z.A1.Bytes()
+	return append(a0[:], a1[:]...), nil
+}
+
+// UnmarshalBinary decodes z from the big-endian bytes of its coefficients,
+// which must be canonical.
+func (z *E2) UnmarshalBinary(data []byte) error {
+	if len(data) != 2*Bytes {
+		return errors.New("invalid E2 encoding length")
+	}
+	if err := z.A0.SetBytesCanonical(data[:Bytes]); err != nil {
+		return err
+	}
+	return z.A1.SetBytesCanonical(data[Bytes:])
+}
+
+// BatchInvertE2 returns a new slice with every element inverted.  Uses
+// Montgomery batch inversion trick.
+func BatchInvertE2(a []E2) []E2 {
+	res := make([]E2, len(a))
+
+	var accumulator E2
+	accumulator.SetOne()
+	for i := range a {
+		if a[i].IsZero() {
+			continue
+		}
+		res[i] = accumulator
+		accumulator.Mul(&accumulator, &a[i])
+	}
+
+	accumulator.Inverse(&accumulator)
+
+	for i := len(a) - 1; i >= 0; i-- {
+		if a[i].IsZero() {
+			continue
+		}
+		res[i].Mul(&res[i], &accumulator)
+		accumulator.Mul(&accumulator, &a[i])
+	}
+	return res
+}
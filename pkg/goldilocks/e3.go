@@ -0,0 +1,280 @@
+package goldilocks
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+)
+
+// E3 is an element of the cubic extension of the Goldilocks field
+// F[φ]/(φ³ - φ - 1), A0 + A1·φ + A2·φ², as used by Winterfell.
+type E3 struct {
+	A0, A1, A2 Element
+}
+
+// φ^q and φ^2q, the images of φ and φ² under the Frobenius automorphism
+var _frobeniusE3 = func() (f [2]E3) {
+	phi := E3{A1: One()}
+	f[0].expUint64(phi, q)
+	f[1].Square(&f[0])
+	return
+}()
+
+// NewE3 returns a0 + a1·φ + a2·φ².
+func NewE3(a0, a1, a2 uint64) E3 {
+	return E3{A0: NewElement(a0), A1: NewElement(a1), A2: NewElement(a2)}
+}
+
+// SetZero sets z to 0 and returns z.
+func (z *E3) SetZero() *E3 {
+	*z = E3{}
+	return z
+}
+
+// SetOne sets z to 1 and returns z.
+func (z *E3) SetOne() *E3 {
+	*z = E3{A0: One()}
+	return z
+}
+
+// SetElement sets z to the base field element x and returns z.
+func (z *E3) SetElement(x *Element) *E3 {
+	*z = E3{A0: *x}
+	return z
+}
+
+// Set sets z to x and returns z.
+func (z *E3) Set(x *E3) *E3 {
+	*z = *x
+	return z
+}
+
+// SetRandom sets z to a uniform random value and returns z.
+func (z *E3) SetRandom() (*E3, error) {
+	for _, a := range []*Element{&z.A0, &z.A1, &z.A2} {
+		if _, err := a.SetRandom(); err != nil {
+			return nil, err
+		}
+	}
+	return z, nil
+}
+
+// Equal returns z == x.
+func (z *E3) Equal(x *E3) bool {
+	return z.A0.Equal(&x.A0) && z.A1.Equal(&x.A1) && z.A2.Equal(&x.A2)
+}
+
+// IsZero returns z == 0.
+func (z *E3) IsZero() bool {
+	return z.A0.IsZero() && z.A1.IsZero() && z.A2.IsZero()
+}
+
+// IsOne returns z == 1.
+func (z *E3) IsOne() bool {
+	return z.A0.IsOne() && z.A1.IsZero() && z.A2.IsZero()
+}
+
+// InBaseField returns whether z is an element of the base field.
+func (z *E3) InBaseField() bool {
+	return z.A1.IsZero() && z.A2.IsZero()
+}
+
+// Add sets z to x + y and returns z.
+func (z *E3) Add(x, y *E3) *E3 {
+	z.A0.Add(&x.A0, &y.A0)
+	z.A1.Add(&x.A1, &y.A1)
+	z.A2.Add(&x.A2, &y.A2)
+	return z
+}
+
+// Sub sets z to x - y and returns z.
+func (z *E3) Sub(x, y *E3) *E3 {
+	z.A0.Sub(&x.A0, &y.A0)
+	z.A1.Sub(&x.A1, &y.A1)
+	z.A2.Sub(&x.A2, &y.A2)
+	return z
+}
+
+// Double sets z to 2x and returns z.
+func (z *E3) Double(x *E3) *E3 {
+	z.A0.Double(&x.A0)
+	z.A1.Double(&x.A1)
+	z.A2.Double(&x.A2)
+	return z
+}
+
+// Neg sets z to -x and returns z.
+func (z *E3) Neg(x *E3) *E3 {
+	z.A0.Neg(&x.A0)
+	z.A1.Neg(&x.A1)
+	z.A2.Neg(&x.A2)
+	return z
+}
+
+// Mul sets z to x·y and returns z.
+func (z *E3) Mul(x, y *E3) *E3 {
+	// With φ³ = φ + 1 and φ⁴ = φ² + φ:
+	//   c0 = a0·b0 + a1·b2 + a2·b1
+	//   c1 = a0·b1 + a1·b0 + a1·b2 + a2·b1 + a2·b2
+	//   c2 = a0·b2 + a1·b1 + a2·b0 + a2·b2
+	var a0b0, a0b1, a0b2, a1b0, a1b1, a1b2, a2b0, a2b1, a2b2, t Element
+	a0b0.Mul(&x.A0, &y.A0)
+	a0b1.Mul(&x.A0, &y.A1)
+	a0b2.Mul(&x.A0, &y.A2)
+	a1b0.Mul(&x.A1, &y.A0)
+	a1b1.Mul(&x.A1, &y.A1)
+	a1b2.Mul(&x.A1, &y.A2)
+	a2b0.Mul(&x.A2, &y.A0)
+	a2b1.Mul(&x.A2, &y.A1)
+	a2b2.Mul(&x.A2, &y.A2)
+
+	t.Add(&a1b2, &a2b1)
+	z.A0.Add(&a0b0, &t)
+	z.A1.Add(&a0b1, &a1b0).Add(&z.A1, &t).Add(&z.A1, &a2b2)
+	z.A2.Add(&a0b2, &a1b1).Add(&z.A2, &a2b0).Add(&z.A2, &a2b2)
+	return z
+}
+
+// MulByElement sets z to x·y, where y is in the base field, and returns z.
+func (z *E3) MulByElement(x *E3, y *Element) *E3 {
+	z.A0.Mul(&x.A0, y)
+	z.A1.Mul(&x.A1, y)
+	z.A2.Mul(&x.A2, y)
+	return z
+}
+
+// Square sets z to x² and returns z.
+func (z *E3) Square(x *E3) *E3 {
+	return z.Mul(x, x)
+}
+
+// Frobenius sets z to x^q and returns z.
+func (z *E3) Frobenius(x *E3) *E3 {
+	var a1, a2 E3
+	a1.MulByElement(&_frobeniusE3[0], &x.A1)
+	a2.MulByElement(&_frobeniusE3[1], &x.A2)
+
+	a0 := x.A0
+	z.Add(&a1, &a2)
+	z.A0.Add(&z.A0, &a0)
+	return z
+}
+
+// Norm returns x·x^q·x^q², which is in the base field.
+func (z *E3) Norm() Element {
+	var n E3
+	n.Mul(z, z.conjugates())
+	return n.A0
+}
+
+// Returns x^q·x^q².
+func (z *E3) conjugates() *E3 {
+	var c, c2 E3
+	c.Frobenius(z)
+	c2.Frobenius(&c)
+	return c.Mul(&c, &c2)
+}
+
+// Inverse sets z to x⁻¹ and returns z.  The inverse of 0 is 0.
+func (z *E3) Inverse(x *E3) *E3 {
+	c := x.conjugates()
+	n := x.Norm()
+	n.Inverse(&n)
+	return z.MulByElement(c, &n)
+}
+
+// Exp sets z to xᵏ and returns z.
+func (z *E3) Exp(x E3, k *big.Int) *E3 {
+	if k.Sign() == -1 {
+		x.Inverse(&x)
+		k = new(big.Int).Neg(k)
+	}
+
+	z.SetOne()
+	for i := k.BitLen() - 1; i >= 0; i-- {
+		z.Square(z)
+		if k.Bit(i) == 1 {
+			z.Mul(z, &x)
+		}
+	}
+	return z
+}
+
+func (z *E3) expUint64(x E3, k uint64) *E3 {
+	z.SetOne()
+	for i := 63; i >= 0; i-- {
+		z.Square(z)
+		if k>>i&1 == 1 {
+			z.Mul(z, &x)
+		}
+	}
+	return z
+}
+
+// String returns the decimal representation of z as "A0+A1*φ+A2*φ²".
+func (z *E3) String() string {
+	return z.A0.String() + "+" + z.A1.String() + "*φ+" + z.A2.String() + "*φ²"
+}
+
+// MarshalJSON encodes z as a JSON array of its coefficients.
+func (z *E3) MarshalJSON() ([]byte, error) {
+	return json.Marshal([3]*Element{&z.A0, &z.A1, &z.A2})
+}
+
+// UnmarshalJSON decodes z from a JSON array of its coefficients.
+func (z *E3) UnmarshalJSON(data []byte) error {
+	var a [3]Element
+	if err := json.Unmarshal(data, &a); err != nil {
+		return err
+	}
+	z.A0, z.A1, z.A2 = a[0], a[1], a[2]
+	return nil
+}
+
+// MarshalBinary encodes z as the big-endian bytes of its coefficients.
+func (z *E3) MarshalBinary() ([]byte, error) {
+	a0, a1, a2 := z.A0.Bytes(), z.A1.Bytes(), z.A2.Bytes()
+	return append(append(a0[:], a1[:]...), a2[:]...), nil
+}
+
+// UnmarshalBinary decodes z from the big-endian bytes of its coefficients,
+// which must be canonical.
+func (z *E3) UnmarshalBinary(data []byte) error {
+	if len(data) != 3*Bytes {
+		return errors.New("invalid E3 encoding length")
+	}
+	if err := z.A0.SetBytesCanonical(data[:Bytes]); err != nil {
+		return err
+	}
+	if err := z.A1.SetBytesCanonical(data[Bytes : 2*Bytes]); err != nil {
+		return err
+	}
+	return z.A2.SetBytesCanonical(data[2*Bytes:])
+}
+
+// BatchInvertE3 returns a new slice with every element inverted.  Uses
+// Montgomery batch inversion trick.
+func BatchInvertE3(a []E3) []E3 {
+	res := make([]E3, len(a))
+
+	var accumulator E3
+	accumulator.SetOne()
+	for i := range a {
+		if a[i].IsZero() {
+			continue
+		}
+		res[i] = accumulator
+		accumulator.Mul(&accumulator, &a[i])
+	}
+
+	accumulator.Inverse(&accumulator)
+
+	for i := len(a) - 1; i >= 0; i-- {
+		if a[i].IsZero() {
+			continue
+		}
+		res[i].Mul(&res[i], &accumulator)
+		accumulator.Mul(&accumulator, &a[i])
+	}
+	return res
+}
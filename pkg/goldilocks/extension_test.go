@@ -0,0 +1,300 @@
+package goldilocks
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/prop"
+	"github.com/stretchr/testify/assert"
+)
+
+func genE2() gopter.Gen {
+	return gopter.CombineGens(genFull(), genFull()).Map(func(v []interface{}) E2 {
+		return E2{A0: v[0].(Element), A1: v[1].(Element)}
+	})
+}
+
+func genE3() gopter.Gen {
+	return gopter.CombineGens(genFull(), genFull(), genFull()).Map(func(v []interface{}) E3 {
+		return E3{A0: v[0].(Element), A1: v[1].(Element), A2: v[2].(Element)}
+	})
+}
+
+func extensionParameters() *gopter.TestParameters {
+	parameters := gopter.DefaultTestParameters()
+	if testing.Short() {
+		parameters.MinSuccessfulTests = nbFuzzShort
+	} else {
+		parameters.MinSuccessfulTests = nbFuzz
+	}
+	return parameters
+}
+
+func TestE2Reduction(t *testing.T) {
+	assert := assert.New(t)
+
+	// φ² = φ - 2
+	phi := NewE2(0, 1)
+	var z, want E2
+	z.Square(&phi)
+	want.A0.SetInt64(-2)
+	want.A1.SetOne()
+	assert.Equal(want, z)
+}
+
+func TestE2(t *testing.T) {
+	t.Parallel()
+	properties := gopter.NewProperties(extensionParameters())
+
+	properties.Property("mul is commutative and associative", prop.ForAll(
+		func(a, b, c E2) bool {
+			var ab, ba, abc, bca E2
+			ab.Mul(&a, &b)
+			ba.Mul(&b, &a)
+			abc.Mul(&ab, &c)
+			bca.Mul(&b, &c).Mul(&a, &bca)
+			return ab.Equal(&ba) && abc.Equal(&bca)
+		},
+		genE2(), genE2(), genE2(),
+	))
+
+	properties.Property("mul distributes over add", prop.ForAll(
+		func(a, b, c E2) bool {
+			var l, r, t E2
+			l.Add(&b, &c).Mul(&a, &l)
+			r.Mul(&a, &b)
+			t.Mul(&a, &c)
+			r.Add(&r, &t)
+			return l.Equal(&r)
+		},
+		genE2(), genE2(), genE2(),
+	))
+
+	properties.Property("square == mul, sub(add) == id, neg == 0 - x", prop.ForAll(
+		func(a, b E2) bool {
+			var s, m, d, n, zero E2
+			s.Square(&a)
+			m.Mul(&a, &a)
+			d.Add(&a, &b).Sub(&d, &b)
+			n.Neg(&a)
+			zero.Sub(&zero, &a)
+			return s.Equal(&m) && d.Equal(&a) && n.Equal(&zero)
+		},
+		genE2(), genE2(),
+	))
+
+	properties.Property("x·x⁻¹ == 1 and x⁻¹ == x^-1", prop.ForAll(
+		func(a E2) bool {
+			if a.IsZero() {
+				return true
+			}
+			var inv, e, one E2
+			inv.Inverse(&a)
+			e.Exp(a, big.NewInt(-1))
+			one.Mul(&a, &inv)
+			return one.IsOne() && e.Equal(&inv)
+		},
+		genE2(),
+	))
+
+	properties.Property("frobenius == x^q, and is an involution", prop.ForAll(
+		func(a E2) bool {
+			var f, e, ff E2
+			f.Frobenius(&a)
+			e.Exp(a, Modulus())
+			ff.Frobenius(&f)
+			return f.Equal(&e) && ff.Equal(&a)
+		},
+		genE2(),
+	))
+
+	properties.Property("norm == x·x^q", prop.ForAll(
+		func(a E2) bool {
+			var f, n E2
+			f.Frobenius(&a)
+			n.Mul(&a, &f)
+			norm := a.Norm()
+			return n.InBaseField() && n.A0.Equal(&norm)
+		},
+		genE2(),
+	))
+
+	properties.Property("batch inversion", prop.ForAll(
+		func(a, b, c E2) bool {
+			v := []E2{a, b, {}, c}
+			inv := BatchInvertE2(v)
+			for i := range v {
+				var want E2
+				want.Inverse(&v[i])
+				if !inv[i].Equal(&want) {
+					return false
+				}
+			}
+			return true
+		},
+		genE2(), genE2(), genE2(),
+	))
+
+	properties.Property("JSON and binary round trip", prop.ForAll(
+		func(a E2) bool {
+			var j, b E2
+			data, err := json.Marshal(&a)
+			if err != nil || json.Unmarshal(data, &j) != nil {
+				return false
+			}
+			data, err = a.MarshalBinary()
+			if err != nil || b.UnmarshalBinary(data) != nil {
+				return false
+			}
+			return j.Equal(&a) && b.Equal(&a)
+		},
+		genE2(),
+	))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+}
+
+func TestE3Reduction(t *testing.T) {
+	assert := assert.New(t)
+
+	// φ³ = φ + 1
+	phi := NewE3(0, 1, 0)
+	var z E3
+	z.Square(&phi).Mul(&z, &phi)
+	assert.Equal(NewE3(1, 1, 0), z)
+}
+
+func TestE3(t *testing.T) {
+	t.Parallel()
+	properties := gopter.NewProperties(extensionParameters())
+
+	properties.Property("mul is commutative and associative", prop.ForAll(
+		func(a, b, c E3) bool {
+			var ab, ba, abc, bca E3
+			ab.Mul(&a, &b)
+			ba.Mul(&b, &a)
+			abc.Mul(&ab, &c)
+			bca.Mul(&b, &c).Mul(&a, &bca)
+			return ab.Equal(&ba) && abc.Equal(&bca)
+		},
+		genE3(), genE3(), genE3(),
+	))
+
+	properties.Property("mul distributes over add", prop.ForAll(
+		func(a, b, c E3) bool {
+			var l, r, t E3
+			l.Add(&b, &c).Mul(&a, &l)
+			r.Mul(&a, &b)
+			t.Mul(&a, &c)
+			r.Add(&r, &t)
+			return l.Equal(&r)
+		},
+		genE3(), genE3(), genE3(),
+	))
+
+	properties.Property("x·x⁻¹ == 1 and x⁻¹ == x^-1", prop.ForAll(
+		func(a E3) bool {
+			if a.IsZero() {
+				return true
+			}
+			var inv, e, one E3
+			inv.Inverse(&a)
+			e.Exp(a, big.NewInt(-1))
+			one.Mul(&a, &inv)
+			return one.IsOne() && e.Equal(&inv)
+		},
+		genE3(),
+	))
+
+	properties.Property("frobenius == x^q, and has order 3", prop.ForAll(
+		func(a E3) bool {
+			var f, e, fff E3
+			f.Frobenius(&a)
+			e.Exp(a, Modulus())
+			fff.Frobenius(&f).Frobenius(&fff)
+			return f.Equal(&e) && !f.Equal(&a) && fff.Equal(&a)
+		},
+		genE3(),
+	))
+
+	properties.Property("norm is in the base field", prop.ForAll(
+		func(a E3) bool {
+			var n E3
+			n.Mul(&a, a.conjugates())
+			return n.InBaseField()
+		},
+		genE3(),
+	))
+
+	properties.Property("batch inversion", prop.ForAll(
+		func(a, b E3) bool {
+			v := []E3{a, {}, b}
+			inv := BatchInvertE3(v)
+			for i := range v {
+				var want E3
+				want.Inverse(&v[i])
+				if !inv[i].Equal(&want) {
+					return false
+				}
+			}
+			return true
+		},
+		genE3(), genE3(),
+	))
+
+	properties.Property("JSON and binary round trip", prop.ForAll(
+		func(a E3) bool {
+			var j, b E3
+			data, err := json.Marshal(&a)
+			if err != nil || json.Unmarshal(data, &j) != nil {
+				return false
+			}
+			data, err = a.MarshalBinary()
+			if err != nil || b.UnmarshalBinary(data) != nil {
+				return false
+			}
+			return j.Equal(&a) && b.Equal(&a)
+		},
+		genE3(),
+	))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+}
+
+func TestExtensionUnmarshalBinary_Error(t *testing.T) {
+	assert := assert.New(t)
+
+	var e2 E2
+	assert.Error(e2.UnmarshalBinary(make([]byte, Bytes)))
+	nonCanonical := []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0, 0, 0, 0, 0, 0, 0, 0}
+	assert.Error(e2.UnmarshalBinary(nonCanonical))
+
+	var e3 E3
+	assert.Error(e3.UnmarshalBinary(nonCanonical))
+}
+
+func BenchmarkE2Mul(b *testing.B) {
+	x, y := NewE2(3, 5), NewE2(7, 11)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		x.Mul(&x, &y)
+	}
+}
+
+func BenchmarkE2Inverse(b *testing.B) {
+	x := NewE2(3, 5)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		x.Inverse(&x)
+	}
+}
+
+func BenchmarkE3Mul(b *testing.B) {
+	x, y := NewE3(3, 5, 7), NewE3(11, 13, 17)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		x.Mul(&x, &y)
+	}
+}
@@ -0,0 +1,347 @@
+package goldilocks
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/leanovate/gopter"
+	ggen "github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+	"github.com/stretchr/testify/assert"
+)
+
+// Felt is tested against Element: every operation must give the same value
+// for the same inputs, over random and special values.  Element.BitLen is the
+// length of the Montgomery form, so Felt.BitLen is tested against big.Int.
+
+func toFelt(e Element) (f Felt) {
+	f.SetElement(&e)
+	return
+}
+
+// Special values of Element and their canonical forms
+func feltTestValues() []Element {
+	values := append([]Element(nil), staticTestValues...)
+	for _, v := range []uint64{3, 1 << 31, 1 << 32, 1<<32 - 1, 1<<32 + 1, (q - 1) / 2, (q + 1) / 2, q - 2} {
+		values = append(values, NewElement(v))
+	}
+	return values
+}
+
+func TestFeltConversion(t *testing.T) {
+	properties := gopter.NewProperties(extensionParameters())
+
+	properties.Property("Element(SetElement(x)) == x", prop.ForAll(
+		func(a testPairElement) bool {
+			f := toFelt(a.element)
+			e := f.Element()
+			return e.Equal(&a.element)
+		},
+		gen(),
+	))
+
+	properties.Property("the word of a Felt is its value", prop.ForAll(
+		func(a testPairElement) bool {
+			f := toFelt(a.element)
+			return f[0] == a.element.Uint64() && a.bigint.IsUint64() && f[0] == a.bigint.Uint64()
+		},
+		gen(),
+	))
+
+	properties.Property("ToFelts and FromFelts are inverse", prop.ForAll(
+		func(v []Element) bool {
+			return vectorEqual(FromFelts(ToFelts(v)), v)
+		},
+		ggen.SliceOf(genFull()),
+	))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+}
+
+func TestFeltArithmetic(t *testing.T) {
+	binary := map[string]struct {
+		felt    func(z, x, y *Felt) *Felt
+		element func(z, x, y *Element) *Element
+	}{
+		"Add": {(*Felt).Add, (*Element).Add},
+		"Sub": {(*Felt).Sub, (*Element).Sub},
+		"Mul": {(*Felt).Mul, (*Element).Mul},
+		"Div": {(*Felt).Div, (*Element).Div},
+	}
+	unary := map[string]struct {
+		felt    func(z, x *Felt) *Felt
+		element func(z, x *Element) *Element
+	}{
+		"Double":  {(*Felt).Double, (*Element).Double},
+		"Neg":     {(*Felt).Neg, (*Element).Neg},
+		"Square":  {(*Felt).Square, (*Element).Square},
+		"Inverse": {(*Felt).Inverse, (*Element).Inverse},
+		"Halve": {
+			func(z, x *Felt) *Felt { z.Set(x).Halve(); return z },
+			func(z, x *Element) *Element { z.Set(x).Halve(); return z },
+		},
+	}
+
+	properties := gopter.NewProperties(extensionParameters())
+	values := feltTestValues()
+
+	for name, op := range binary {
+		op := op
+		check := func(a, b Element) bool {
+			var e Element
+			var f Felt
+			fa, fb := toFelt(a), toFelt(b)
+			op.element(&e, &a, &b)
+			op.felt(&f, &fa, &fb)
+			return f.Equal(ptr(toFelt(e))) && f[0] < q
+		}
+		properties.Property(name+" matches Element", prop.ForAll(
+			func(a, b Element) bool {
+				for _, v := range values {
+					if !check(a, v) || !check(v, a) {
+						return false
+					}
+				}
+				return check(a, b)
+			},
+			genFull(),
+			genFull(),
+		))
+		properties.Property(name+" on special values matches Element", prop.ForAll(
+			func(i, j int) bool {
+				return check(values[i], values[j])
+			},
+			ggen.IntRange(0, len(values)-1),
+			ggen.IntRange(0, len(values)-1),
+		))
+	}
+
+	for name, op := range unary {
+		op := op
+		check := func(a Element) bool {
+			var e Element
+			var f Felt
+			fa := toFelt(a)
+			op.element(&e, &a)
+			op.felt(&f, &fa)
+			return f.Equal(ptr(toFelt(e))) && f[0] < q
+		}
+		properties.Property(name+" matches Element", prop.ForAll(
+			func(a Element) bool {
+				for _, v := range values {
+					if !check(v) {
+						return false
+					}
+				}
+				return check(a)
+			},
+			genFull(),
+		))
+	}
+
+	properties.Property("Exp matches Element", prop.ForAll(
+		func(a Element, k int64) bool {
+			var e Element
+			var f Felt
+			e.Exp(a, big.NewInt(k))
+			f.Exp(toFelt(a), big.NewInt(k))
+			return f.Equal(ptr(toFelt(e)))
+		},
+		genFull(),
+		ggen.Int64(),
+	))
+
+	properties.Property("Sqrt matches Element", prop.ForAll(
+		func(a Element) bool {
+			var e Element
+			var f Felt
+			fa := toFelt(a)
+			er, fr := e.Sqrt(&a), f.Sqrt(&fa)
+			if er == nil || fr == nil {
+				return er == nil && fr == nil
+			}
+			return f.Equal(ptr(toFelt(e)))
+		},
+		genFull(),
+	))
+
+	properties.Property("Select matches Element", prop.ForAll(
+		func(a, b Element, c int) bool {
+			var e Element
+			var f Felt
+			e.Select(c, &a, &b)
+			f.Select(c, ptr(toFelt(a)), ptr(toFelt(b)))
+			return f.Equal(ptr(toFelt(e)))
+		},
+		genFull(),
+		genFull(),
+		ggen.IntRange(-2, 2),
+	))
+
+	properties.Property("BatchInvertFelts matches BatchInvert", prop.ForAll(
+		func(v []Element) bool {
+			return vectorEqual(FromFelts(BatchInvertFelts(ToFelts(v))), BatchInvert(v))
+		},
+		ggen.SliceOf(ggen.OneGenOf(genFull(), ggen.Const(Element{}))),
+	))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+}
+
+func TestFeltValues(t *testing.T) {
+	properties := gopter.NewProperties(extensionParameters())
+	values := feltTestValues()
+
+	check := func(a, b Element) bool {
+		fa, fb := toFelt(a), toFelt(b)
+
+		var eBig, fBig big.Int
+		a.BigInt(&eBig)
+		fa.BigInt(&fBig)
+
+		eJSON, _ := json.Marshal(&a)
+		fJSON, _ := json.Marshal(&fa)
+
+		return fa.Cmp(&fb) == a.Cmp(&b) &&
+			fa.Legendre() == a.Legendre() &&
+			fa.LexicographicallyLargest() == a.LexicographicallyLargest() &&
+			fa.BitLen() == eBig.BitLen() &&
+			fa.IsZero() == a.IsZero() &&
+			fa.IsOne() == a.IsOne() &&
+			fa.Uint64() == a.Uint64() &&
+			fa.String() == a.String() &&
+			fa.Text(16) == a.Text(16) &&
+			fa.Bytes() == a.Bytes() &&
+			fBig.Cmp(&eBig) == 0 &&
+			string(fJSON) == string(eJSON)
+	}
+
+	properties.Property("values match Element", prop.ForAll(
+		func(a, b Element) bool {
+			for _, v := range values {
+				if !check(v, a) {
+					return false
+				}
+			}
+			return check(a, b)
+		},
+		genFull(),
+		genFull(),
+	))
+
+	properties.Property("setters match Element", prop.ForAll(
+		func(v uint64, i int64) bool {
+			var e Element
+			var f Felt
+			b := new(big.Int).SetUint64(v)
+			b.Mul(b, b)
+
+			return f.SetUint64(v).Equal(ptr(toFelt(*e.SetUint64(v)))) &&
+				f.SetInt64(i).Equal(ptr(toFelt(*e.SetInt64(i)))) &&
+				f.SetBigInt(b).Equal(ptr(toFelt(*e.SetBigInt(b)))) &&
+				f.SetBytes(b.Bytes()).Equal(ptr(toFelt(*e.SetBytes(b.Bytes())))) &&
+				f.SetBytes(e.Marshal()).Equal(ptr(toFelt(*e.SetBytes(e.Marshal()))))
+		},
+		ggen.UInt64(),
+		ggen.Int64(),
+	))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+}
+
+func TestFeltEncoding(t *testing.T) {
+	assert := assert.New(t)
+
+	var f Felt
+	assert.Error(f.SetBytesCanonical([]byte{0xff, 0xff, 0xff, 0xff, 0, 0, 0, 1}))
+	assert.Error(f.SetBytesCanonical([]byte{1}))
+	assert.NoError(f.SetBytesCanonical([]byte{0xff, 0xff, 0xff, 0xff, 0, 0, 0, 0}))
+	assert.Equal(Felt{q - 1}, f)
+
+	for _, s := range []string{"-1", "0x10", "18446744069414584322"} {
+		var e Element
+		e.SetString(s)
+		_, err := f.SetString(s)
+		assert.NoError(err)
+		assert.Equal(toFelt(e), f, s)
+
+		assert.NoError(json.Unmarshal([]byte(`"`+s+`"`), &f))
+		assert.Equal(toFelt(e), f, s)
+	}
+	_, err := f.SetString("x")
+	assert.Error(err)
+
+	_, err = f.SetInterface(Felt{5})
+	assert.NoError(err)
+	assert.Equal(Felt{5}, f)
+	_, err = f.SetInterface("7")
+	assert.NoError(err)
+	assert.Equal(Felt{7}, f)
+	_, err = f.SetInterface(3.5)
+	assert.Error(err)
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+var benchResFelt Felt
+
+func BenchmarkFeltMul(b *testing.B) {
+	x := Felt{18446744065119617025}
+	benchResFelt.SetOne()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		benchResFelt.Mul(&benchResFelt, &x)
+	}
+}
+
+func BenchmarkFeltSquare(b *testing.B) {
+	benchResFelt.SetRandom()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		benchResFelt.Square(&benchResFelt)
+	}
+}
+
+func BenchmarkFeltAdd(b *testing.B) {
+	var x Felt
+	x.SetRandom()
+	benchResFelt.SetOne()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		benchResFelt.Add(&x, &benchResFelt)
+	}
+}
+
+func BenchmarkFeltInverse(b *testing.B) {
+	var x Felt
+	x.SetRandom()
+	benchResFelt.SetRandom()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		benchResFelt.Inverse(&x)
+	}
+}
+
+func BenchmarkFeltExp(b *testing.B) {
+	var x Felt
+	x.SetRandom()
+	b1, _ := rand.Int(rand.Reader, Modulus())
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		benchResFelt.Exp(x, b1)
+	}
+}
+
+func BenchmarkFeltSqrt(b *testing.B) {
+	var a Felt
+	a.SetUint64(4)
+	a.Neg(&a)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		benchResFelt.Sqrt(&a)
+	}
+}
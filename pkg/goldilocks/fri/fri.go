@@ -0,0 +1,185 @@
+// Package fri implements the FRI low-degree test over the Goldilocks field,
+// after the FRI protocol of Winterfell: the prover commits to evaluations of
+// a polynomial over a coset domain, folds them layer by layer with random
+// challenges, and sends the low-degree remainder; the verifier checks the
+// folding at random query positions and the degree of the remainder.
+//
+// Every layer is committed with an RPO Merkle tree whose leaves are the
+// hashes of the rows of evaluations folded together, and challenges are
+// drawn from a random coin, so that a proof is non-interactive.
+package fri
+
+import (
+	"errors"
+	"math/big"
+
+	field "github.com/qredo/verifiable-oracles/pkg/goldilocks"
+	"github.com/qredo/verifiable-oracles/pkg/goldilocks/merkle"
+	"github.com/qredo/verifiable-oracles/pkg/goldilocks/poly"
+	"github.com/qredo/verifiable-oracles/pkg/goldilocks/rpo"
+)
+
+// Offset of the evaluation domain, the generator of the multiplicative group
+// as in Winterfell
+const _domainOffset = 7
+
+var (
+	// ErrOptions is returned for invalid options.
+	ErrOptions = errors.New("fri: invalid options")
+	// ErrDomainSize is returned when the evaluation domain is not a power of
+	// two larger than BlowupFactor·(MaxRemainderDegree+1).
+	ErrDomainSize = errors.New("fri: invalid evaluation domain size")
+	// ErrMalformedProof is returned for proofs without the expected layers,
+	// rows or remainder.
+	ErrMalformedProof = errors.New("fri: malformed proof")
+	// ErrCommitment is returned when the first layer is not the committed
+	// one, or queried rows do not match the commitment of their layer.
+	ErrCommitment = errors.New("fri: rows do not match the layer commitment")
+	// ErrConsistency is returned when folded evaluations do not match the
+	// next layer or the remainder.
+	ErrConsistency = errors.New("fri: folded evaluations do not match the next layer")
+	// ErrRemainderDegree is returned when the degree of the remainder is
+	// larger than the folded degree bound.
+	ErrRemainderDegree = errors.New("fri: remainder degree too large")
+	// ErrProofOfWork is returned when the nonce does not prove enough work.
+	ErrProofOfWork = errors.New("fri: insufficient proof of work")
+)
+
+// Options are the parameters of the protocol, shared by the prover and the
+// verifier.
+type Options struct {
+	// Ratio of the size of the evaluation domain to the degree bound, a
+	// power of two, at least 2
+	BlowupFactor int
+	// Number of evaluations folded into one per layer: 2, 4, 8 or 16
+	FoldingFactor int
+	// Folding stops once the degree bound is at most MaxRemainderDegree+1,
+	// a power of two, at least FoldingFactor
+	MaxRemainderDegree int
+	// Number of query positions, smaller than the size of the evaluation
+	// domain
+	NumQueries int
+	// Number of bits of proof of work before drawing query positions
+	GrindingFactor int
+}
+
+func (o *Options) validate() error {
+	switch o.FoldingFactor {
+	case 2, 4, 8, 16:
+	default:
+		return ErrOptions
+	}
+	r := o.MaxRemainderDegree + 1
+	if o.BlowupFactor < 2 || !isPowerOfTwo(o.BlowupFactor) ||
+		r < o.FoldingFactor || !isPowerOfTwo(r) ||
+		o.NumQueries <= 0 || o.GrindingFactor < 0 || o.GrindingFactor > 64 {
+		return ErrOptions
+	}
+	return nil
+}
+
+// Domain returns the evaluation domain of polynomials of degree at most
+// maxDegree, where maxDegree+1 is a power of two: the coset of size
+// BlowupFactor·(maxDegree+1), offset by the generator of the multiplicative
+// group.
+func (o *Options) Domain(maxDegree int) (*poly.Domain, error) {
+	if err := o.validate(); err != nil {
+		return nil, err
+	}
+	if !isPowerOfTwo(maxDegree + 1) {
+		return nil, ErrDomainSize
+	}
+	size := (maxDegree + 1) * o.BlowupFactor
+	return poly.NewCosetDomain(size, field.NewElement(_domainOffset))
+}
+
+// Returns the number of layers committed for an evaluation domain of size n.
+func (o *Options) numLayers(n int) int {
+	layers := 0
+	for ; n > (o.MaxRemainderDegree+1)*o.BlowupFactor; n /= o.FoldingFactor {
+		layers++
+	}
+	return layers
+}
+
+// Proof is a FRI proof: the commitments of the layers with the rows opened at
+// the query positions, and the remainder.
+type Proof struct {
+	Layers []LayerProof
+	// Coefficients of the polynomial of the last folded evaluations, as many
+	// as the size of their domain.  Its degree must be lower than the size of
+	// the domain divided by BlowupFactor.
+	Remainder poly.Polynomial
+	// Proof-of-work nonce absorbed before drawing query positions
+	Nonce uint64
+}
+
+// LayerProof is the commitment of a layer and its rows opened at the query
+// positions.
+type LayerProof struct {
+	// Root of the Merkle tree of the hashes of the rows
+	Commitment rpo.Digest
+	// Rows opened, in order of their first query, with their authentication
+	// paths
+	Rows  []field.Vector
+	Paths []merkle.Path
+}
+
+// Layer evaluations over a domain of size n are folded as n/f rows: row r
+// holds the evaluations at r, r + n/f, ..., r + (f-1)·n/f, the points x·ζ^j
+// where x is the element r of the domain and ζ is a primitive f-th root of
+// unity.  Splitting p(X) = Σ X^k·p_k(X^f), the folded polynomial is
+// Σ α^k·p_k(Y), of degree divided by f, evaluated over the domain of size
+// n/f, the f-th powers of the first n/f elements.
+
+// Returns the rows of evaluations for the folding factor f.
+func rows(evaluations field.Vector, f int) []field.Vector {
+	n := len(evaluations) / f
+	rows := make([]field.Vector, n)
+	for r := range rows {
+		rows[r] = make(field.Vector, f)
+		for j := range rows[r] {
+			rows[r][j] = evaluations[r+j*n]
+		}
+	}
+	return rows
+}
+
+// Returns the folded evaluation of row at the point x of the domain given
+// its inverse.  The coefficients of the interpolant of the row over ⟨ζ⟩ are
+// x^k·p_k(x^f), so the folded evaluation is the interpolant at α/x.
+func fold(foldDomain *poly.Domain, row field.Vector, alpha, xInv *field.Element) field.Element {
+	var z field.Element
+	z.Mul(alpha, xInv)
+	return foldDomain.Interpolate(row).Eval(z)
+}
+
+// Returns the Merkle leaf of a row.
+func rowLeaf(row field.Vector) rpo.Digest {
+	return rpo.HashElements(row)
+}
+
+// Returns the domain of the layer folded from d with the factor f.
+func foldedDomain(d *poly.Domain, f int) (*poly.Domain, error) {
+	var offset field.Element
+	offset.Exp(d.Offset, big.NewInt(int64(f)))
+	return poly.NewCosetDomain(d.Size/f, offset)
+}
+
+// Returns the row indices of the queried positions of a layer with n rows,
+// without duplicates, in order of first query.
+func rowIndices(positions []int, n int) []int {
+	seen := make(map[int]bool, len(positions))
+	indices := make([]int, 0, len(positions))
+	for _, p := range positions {
+		if r := p % n; !seen[r] {
+			seen[r] = true
+			indices = append(indices, r)
+		}
+	}
+	return indices
+}
+
+func isPowerOfTwo(n int) bool {
+	return n > 0 && n&(n-1) == 0
+}
@@ -0,0 +1,241 @@
+package fri_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	field "github.com/qredo/verifiable-oracles/pkg/goldilocks"
+	"github.com/qredo/verifiable-oracles/pkg/goldilocks/fri"
+	"github.com/qredo/verifiable-oracles/pkg/goldilocks/poly"
+	"github.com/qredo/verifiable-oracles/pkg/goldilocks/randomcoin"
+	"github.com/qredo/verifiable-oracles/pkg/goldilocks/rpo"
+)
+
+func randomPolynomial(degree int) poly.Polynomial {
+	p := make(poly.Polynomial, degree+1)
+	for i := range p {
+		p[i].SetRandom()
+	}
+	if p[degree].IsZero() {
+		p[degree].SetOne()
+	}
+	return p
+}
+
+func newCoin() *randomcoin.RandomCoin {
+	return randomcoin.New(field.Vector{field.NewElement(1), field.NewElement(2)})
+}
+
+// Returns the commitment and the proof of the evaluations of p for
+// polynomials of degree at most maxDegree.
+func prove(t *testing.T, options fri.Options, p poly.Polynomial, maxDegree int) (rpo.Digest, *fri.Proof) {
+	domain, err := options.Domain(maxDegree)
+	require.NoError(t, err)
+	// p may be of degree larger than maxDegree, but must fit the domain
+	evaluations := make(field.Vector, domain.Size)
+	copy(evaluations, p)
+	domain.NTT(evaluations)
+
+	commitment, err := fri.Commit(options, evaluations)
+	require.NoError(t, err)
+	proof, err := fri.Prove(options, evaluations, newCoin())
+	require.NoError(t, err)
+	return commitment, proof
+}
+
+func TestProveVerify(t *testing.T) {
+	table := map[string]struct {
+		options   fri.Options
+		maxDegree int
+		degree    int
+	}{
+		"fold 2": {
+			options:   fri.Options{BlowupFactor: 8, FoldingFactor: 2, MaxRemainderDegree: 7, NumQueries: 16},
+			maxDegree: 255,
+			degree:    255,
+		},
+		"fold 4": {
+			options:   fri.Options{BlowupFactor: 4, FoldingFactor: 4, MaxRemainderDegree: 15, NumQueries: 24},
+			maxDegree: 1023,
+			degree:    1000,
+		},
+		"fold 8": {
+			options:   fri.Options{BlowupFactor: 2, FoldingFactor: 8, MaxRemainderDegree: 7, NumQueries: 32, GrindingFactor: 4},
+			maxDegree: 1023,
+			degree:    999,
+		},
+		"fold 16": {
+			options:   fri.Options{BlowupFactor: 4, FoldingFactor: 16, MaxRemainderDegree: 31, NumQueries: 20},
+			maxDegree: 4095,
+			degree:    77,
+		},
+		"one layer": {
+			options:   fri.Options{BlowupFactor: 2, FoldingFactor: 4, MaxRemainderDegree: 3, NumQueries: 4},
+			maxDegree: 7,
+			degree:    6,
+		},
+	}
+	for name, tc := range table {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			commitment, proof := prove(t, tc.options, randomPolynomial(tc.degree), tc.maxDegree)
+			assert.NotEmpty(proof.Layers)
+			assert.NoError(fri.Verify(tc.options, tc.maxDegree, commitment, proof, newCoin()))
+
+			// The proof is bound to the coin
+			assert.Error(fri.Verify(tc.options, tc.maxDegree, commitment, proof, randomcoin.New(nil)))
+		})
+	}
+}
+
+func TestVerify_Degree(t *testing.T) {
+	assert := assert.New(t)
+
+	options := fri.Options{BlowupFactor: 4, FoldingFactor: 4, MaxRemainderDegree: 3, NumQueries: 16}
+
+	// Polynomials of degree above the bound fold to a remainder of too large
+	// a degree
+	for _, degree := range []int{64, 100, 255} {
+		commitment, proof := prove(t, options, randomPolynomial(degree), 63)
+		assert.ErrorIs(fri.Verify(options, 63, commitment, proof, newCoin()), fri.ErrRemainderDegree, degree)
+	}
+
+	// Or evaluations which are not those of a polynomial
+	domain, err := options.Domain(63)
+	require.NoError(t, err)
+	evaluations := make(field.Vector, domain.Size)
+	for i := range evaluations {
+		evaluations[i].SetRandom()
+	}
+	commitment, err := fri.Commit(options, evaluations)
+	require.NoError(t, err)
+	proof, err := fri.Prove(options, evaluations, newCoin())
+	require.NoError(t, err)
+	assert.Error(fri.Verify(options, 63, commitment, proof, newCoin()))
+
+	// Truncating the remainder changes the query positions and breaks the
+	// folding checks
+	commitment, proof = prove(t, options, randomPolynomial(64), 63)
+	for i := len(proof.Remainder) / options.BlowupFactor; i < len(proof.Remainder); i++ {
+		proof.Remainder[i].SetZero()
+	}
+	assert.Error(fri.Verify(options, 63, commitment, proof, newCoin()))
+
+	_, err = options.Domain(62)
+	assert.ErrorIs(err, fri.ErrDomainSize)
+}
+
+func TestVerify_Tampered(t *testing.T) {
+	options := fri.Options{BlowupFactor: 4, FoldingFactor: 4, MaxRemainderDegree: 3, NumQueries: 8, GrindingFactor: 6}
+	const maxDegree = 255
+	p := randomPolynomial(maxDegree)
+
+	table := map[string]struct {
+		tamper func(*fri.Proof)
+		err    error
+	}{
+		"row": {
+			tamper: func(proof *fri.Proof) { proof.Layers[1].Rows[0][1].SetOne() },
+			err:    fri.ErrCommitment,
+		},
+		"path": {
+			tamper: func(proof *fri.Proof) { proof.Layers[0].Paths[2][0][0].SetOne() },
+			err:    fri.ErrCommitment,
+		},
+		"missing row": {
+			tamper: func(proof *fri.Proof) { proof.Layers[0].Rows = proof.Layers[0].Rows[1:] },
+			err:    fri.ErrMalformedProof,
+		},
+		"short row": {
+			tamper: func(proof *fri.Proof) { proof.Layers[0].Rows[0] = proof.Layers[0].Rows[0][1:] },
+			err:    fri.ErrMalformedProof,
+		},
+		"first commitment": {
+			tamper: func(proof *fri.Proof) { proof.Layers[0].Commitment[0].SetOne() },
+			err:    fri.ErrCommitment,
+		},
+		"missing layer": {
+			tamper: func(proof *fri.Proof) { proof.Layers = proof.Layers[1:] },
+			err:    fri.ErrMalformedProof,
+		},
+		"remainder length": {
+			tamper: func(proof *fri.Proof) { proof.Remainder = proof.Remainder[1:] },
+			err:    fri.ErrMalformedProof,
+		},
+		"nonce": {
+			tamper: func(proof *fri.Proof) { proof.Nonce++ },
+		},
+	}
+	for name, tc := range table {
+		t.Run(name, func(t *testing.T) {
+			commitment, proof := prove(t, options, p, maxDegree)
+			tc.tamper(proof)
+			err := fri.Verify(options, maxDegree, commitment, proof, newCoin())
+			if tc.err != nil {
+				assert.ErrorIs(t, err, tc.err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestVerify_OtherPolynomial(t *testing.T) {
+	options := fri.Options{BlowupFactor: 4, FoldingFactor: 4, MaxRemainderDegree: 3, NumQueries: 8}
+	const maxDegree = 63
+
+	// A valid proof of another polynomial does not prove the committed one
+	commitment, _ := prove(t, options, randomPolynomial(maxDegree), maxDegree)
+	other, proof := prove(t, options, randomPolynomial(maxDegree), maxDegree)
+	require.NoError(t, fri.Verify(options, maxDegree, other, proof, newCoin()))
+	assert.ErrorIs(t, fri.Verify(options, maxDegree, commitment, proof, newCoin()), fri.ErrCommitment)
+}
+
+func TestOptions(t *testing.T) {
+	valid := fri.Options{BlowupFactor: 4, FoldingFactor: 4, MaxRemainderDegree: 7, NumQueries: 8}
+	table := map[string]func(*fri.Options){
+		"blowup 1":          func(o *fri.Options) { o.BlowupFactor = 1 },
+		"blowup 6":          func(o *fri.Options) { o.BlowupFactor = 6 },
+		"folding 3":         func(o *fri.Options) { o.FoldingFactor = 3 },
+		"folding 32":        func(o *fri.Options) { o.FoldingFactor = 32 },
+		"remainder 6":       func(o *fri.Options) { o.MaxRemainderDegree = 5 },
+		"small remainder":   func(o *fri.Options) { o.MaxRemainderDegree = 1 },
+		"no queries":        func(o *fri.Options) { o.NumQueries = 0 },
+		"negative grinding": func(o *fri.Options) { o.GrindingFactor = -1 },
+	}
+	for name, modify := range table {
+		t.Run(name, func(t *testing.T) {
+			options := valid
+			modify(&options)
+			_, err := options.Domain(63)
+			assert.ErrorIs(t, err, fri.ErrOptions)
+			_, err = fri.Prove(options, make(field.Vector, 256), newCoin())
+			assert.ErrorIs(t, err, fri.ErrOptions)
+			_, err = fri.Commit(options, make(field.Vector, 256))
+			assert.ErrorIs(t, err, fri.ErrOptions)
+		})
+	}
+
+	assert := assert.New(t)
+
+	domain, err := valid.Domain(63)
+	require.NoError(t, err)
+	assert.Equal(256, domain.Size)
+	domain, err = valid.Domain(127)
+	require.NoError(t, err)
+	assert.Equal(512, domain.Size)
+	_, err = valid.Domain(64)
+	assert.ErrorIs(err, fri.ErrDomainSize)
+
+	// The domain must be larger than the remainder domain
+	_, err = fri.Prove(valid, make(field.Vector, 32), newCoin())
+	assert.ErrorIs(err, fri.ErrDomainSize)
+	_, err = fri.Prove(valid, make(field.Vector, 96), newCoin())
+	assert.ErrorIs(err, fri.ErrDomainSize)
+	_, err = fri.Commit(valid, make(field.Vector, 32))
+	assert.ErrorIs(err, fri.ErrDomainSize)
+	assert.ErrorIs(fri.Verify(valid, 7, rpo.Digest{}, &fri.Proof{}, newCoin()), fri.ErrDomainSize)
+}
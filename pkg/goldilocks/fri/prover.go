@@ -0,0 +1,117 @@
+package fri
+
+import (
+	field "github.com/qredo/verifiable-oracles/pkg/goldilocks"
+	"github.com/qredo/verifiable-oracles/pkg/goldilocks/merkle"
+	"github.com/qredo/verifiable-oracles/pkg/goldilocks/poly"
+	"github.com/qredo/verifiable-oracles/pkg/goldilocks/randomcoin"
+	"github.com/qredo/verifiable-oracles/pkg/goldilocks/rpo"
+)
+
+// A committed layer of the prover
+type layer struct {
+	rows []field.Vector
+	tree *merkle.Tree
+}
+
+// Commit returns the commitment of the first layer of the proof of
+// evaluations, which the verifier checks the proof against.
+func Commit(options Options, evaluations field.Vector) (rpo.Digest, error) {
+	if err := options.validate(); err != nil {
+		return rpo.Digest{}, err
+	}
+	n := len(evaluations)
+	if !isPowerOfTwo(n) || n <= (options.MaxRemainderDegree+1)*options.BlowupFactor {
+		return rpo.Digest{}, ErrDomainSize
+	}
+
+	l, err := commitLayer(evaluations, options.FoldingFactor)
+	if err != nil {
+		return rpo.Digest{}, err
+	}
+	return l.tree.Root(), nil
+}
+
+// Commits to the rows of evaluations for the folding factor f.
+func commitLayer(evaluations field.Vector, f int) (l layer, err error) {
+	l.rows = rows(evaluations, f)
+	leaves := make([]field.Vector, len(l.rows))
+	for r, row := range l.rows {
+		leaves[r] = rowLeaf(row).Vector()
+	}
+	l.tree, err = merkle.NewTree(leaves)
+	return
+}
+
+// Prove returns a proof that evaluations, over the domain returned by
+// options.Domain, are those of a polynomial of degree lower than
+// len(evaluations) / options.BlowupFactor.  coin must be seeded as the coin
+// of the verifier; it absorbs the commitments, the remainder and the
+// proof-of-work nonce, and draws the folding challenges and the query
+// positions.
+func Prove(options Options, evaluations field.Vector, coin *randomcoin.RandomCoin) (*Proof, error) {
+	if err := options.validate(); err != nil {
+		return nil, err
+	}
+	n := len(evaluations)
+	if !isPowerOfTwo(n) || n <= (options.MaxRemainderDegree+1)*options.BlowupFactor {
+		return nil, ErrDomainSize
+	}
+	domain, err := poly.NewCosetDomain(n, field.NewElement(_domainOffset))
+	if err != nil {
+		return nil, err
+	}
+	f := options.FoldingFactor
+	foldDomain, err := poly.NewDomain(f)
+	if err != nil {
+		return nil, err
+	}
+
+	// Commit phase
+	proof := &Proof{Layers: make([]LayerProof, options.numLayers(n))}
+	layers := make([]layer, len(proof.Layers))
+	for i := range layers {
+		l := &layers[i]
+		if *l, err = commitLayer(evaluations, f); err != nil {
+			return nil, err
+		}
+		proof.Layers[i].Commitment = l.tree.Root()
+		coin.Reseed(l.tree.Root())
+		alpha := coin.Draw()
+
+		xInv := field.BatchInvert(domain.Elements()[:len(l.rows)])
+		evaluations = make(field.Vector, len(l.rows))
+		for r, row := range l.rows {
+			evaluations[r] = fold(foldDomain, row, &alpha, &xInv[r])
+		}
+		if domain, err = foldedDomain(domain, f); err != nil {
+			return nil, err
+		}
+	}
+
+	proof.Remainder = domain.Interpolate(evaluations)
+	coin.ReseedElements(field.Vector(proof.Remainder))
+
+	// Query phase
+	proof.Nonce = coin.Grind(options.GrindingFactor)
+	coin.ReseedWithInt(proof.Nonce)
+	positions, err := coin.DrawIntegers(options.NumQueries, n)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, l := range layers {
+		indices := rowIndices(positions, len(l.rows))
+		lp := &proof.Layers[i]
+		lp.Rows = make([]field.Vector, len(indices))
+		lp.Paths = make([]merkle.Path, len(indices))
+		for k, r := range indices {
+			lp.Rows[k] = l.rows[r]
+			if lp.Paths[k], err = l.tree.Path(uint64(r)); err != nil {
+				return nil, err
+			}
+		}
+		positions = indices
+	}
+	return proof, nil
+}
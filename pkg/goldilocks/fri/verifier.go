@@ -0,0 +1,114 @@
+package fri
+
+import (
+	field "github.com/qredo/verifiable-oracles/pkg/goldilocks"
+	"github.com/qredo/verifiable-oracles/pkg/goldilocks/poly"
+	"github.com/qredo/verifiable-oracles/pkg/goldilocks/randomcoin"
+	"github.com/qredo/verifiable-oracles/pkg/goldilocks/rpo"
+)
+
+// Verify checks that proof proves that the evaluations with the given
+// commitment, as returned by Commit, are those of a polynomial of degree at
+// most maxDegree, where maxDegree+1 is a power of two, over the domain
+// returned by options.Domain(maxDegree).  coin must be seeded as the coin of
+// the prover.
+func Verify(options Options, maxDegree int, commitment rpo.Digest, proof *Proof, coin *randomcoin.RandomCoin) error {
+	domain, err := options.Domain(maxDegree)
+	if err != nil {
+		return err
+	}
+	n := domain.Size
+	f := options.FoldingFactor
+	foldDomain, err := poly.NewDomain(f)
+	if err != nil {
+		return err
+	}
+
+	// Replay the commit phase
+	if options.numLayers(n) == 0 {
+		return ErrDomainSize
+	}
+	if len(proof.Layers) != options.numLayers(n) {
+		return ErrMalformedProof
+	}
+	if proof.Layers[0].Commitment != commitment {
+		return ErrCommitment
+	}
+	alphas := make(field.Vector, len(proof.Layers))
+	remainderSize := n
+	for i := range proof.Layers {
+		coin.Reseed(proof.Layers[i].Commitment)
+		alphas[i] = coin.Draw()
+		remainderSize /= f
+	}
+
+	if len(proof.Remainder) != remainderSize {
+		return ErrMalformedProof
+	}
+	if proof.Remainder.Degree() >= remainderSize/options.BlowupFactor {
+		return ErrRemainderDegree
+	}
+	coin.ReseedElements(field.Vector(proof.Remainder))
+
+	coin.ReseedWithInt(proof.Nonce)
+	if coin.LeadingZeros() < options.GrindingFactor {
+		return ErrProofOfWork
+	}
+	positions, err := coin.DrawIntegers(options.NumQueries, n)
+	if err != nil {
+		return err
+	}
+
+	// Check the folding at the query positions.  evaluations holds the
+	// values of the current layer at positions, folded from the previous
+	// layer.
+	var evaluations map[int]field.Element
+	for i, lp := range proof.Layers {
+		nRows := domain.Size / f
+		indices := rowIndices(positions, nRows)
+		if len(lp.Rows) != len(indices) || len(lp.Paths) != len(indices) {
+			return ErrMalformedProof
+		}
+
+		rowOf := make(map[int]field.Vector, len(indices))
+		folded := make(map[int]field.Element, len(indices))
+		for k, r := range indices {
+			row := lp.Rows[k]
+			if len(row) != f {
+				return ErrMalformedProof
+			}
+			if !lp.Paths[k].Verify(lp.Commitment, uint64(r), rowLeaf(row)) {
+				return ErrCommitment
+			}
+			rowOf[r] = row
+
+			var xInv field.Element
+			x := domain.Element(r)
+			xInv.Inverse(&x)
+			folded[r] = fold(foldDomain, row, &alphas[i], &xInv)
+		}
+
+		if i > 0 {
+			for _, p := range positions {
+				v := rowOf[p%nRows][p/nRows]
+				if want := evaluations[p]; !v.Equal(&want) {
+					return ErrConsistency
+				}
+			}
+		}
+
+		positions, evaluations = indices, folded
+		if domain, err = foldedDomain(domain, f); err != nil {
+			return err
+		}
+	}
+
+	// Check the last folded evaluations against the remainder
+	for _, p := range positions {
+		v := proof.Remainder.Eval(domain.Element(p))
+		if want := evaluations[p]; !v.Equal(&want) {
+			return ErrConsistency
+		}
+	}
+	return nil
+}
@@ -0,0 +1,310 @@
+package merkle_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	field "github.com/qredo/verifiable-oracles/pkg/goldilocks"
+	"github.com/qredo/verifiable-oracles/pkg/goldilocks/merkle"
+	"github.com/qredo/verifiable-oracles/pkg/goldilocks/rpo"
+)
+
+func digest(v ...uint64) (d rpo.Digest) {
+	for i := range d {
+		d[i].SetUint64(v[i])
+	}
+	return
+}
+
+func word(v ...uint64) field.Vector {
+	d := digest(v...)
+	return d.Vector()
+}
+
+func leaves(n int) []field.Vector {
+	l := make([]field.Vector, n)
+	for i := range l {
+		l[i] = word(uint64(4*i), uint64(4*i+1), uint64(4*i+2), uint64(4*i+3))
+	}
+	return l
+}
+
+func TestEmptyRoot(t *testing.T) {
+	assert := assert.New(t)
+
+	// Roots of empty subtrees from the Miden standard library collections::smt
+	assert.Equal(digest(17483286922353768131, 353378057542380712, 1935183237414585408, 4820339620987989650), merkle.EmptyRoot(64-16))
+	assert.Equal(digest(11677748883385181208, 15891398395707500576, 3790704659934033620, 2126099371106695189), merkle.EmptyRoot(64-32))
+	assert.Equal(digest(10650694022550988030, 5634734408638476525, 9233115969432897632, 1437907447409278328), merkle.EmptyRoot(64-48))
+
+	assert.Equal(rpo.Digest{}, merkle.EmptyRoot(0))
+	assert.Equal(merkle.EmptyRoot(64), merkle.NewSMT().Root())
+
+	tree, err := merkle.NewTree(make([]field.Vector, 8))
+	assert.ErrorIs(err, merkle.ErrWordSize)
+	assert.Nil(tree)
+	zero := make([]field.Vector, 8)
+	for i := range zero {
+		zero[i] = make(field.Vector, 4)
+	}
+	tree, err = merkle.NewTree(zero)
+	require.NoError(t, err)
+	assert.Equal(merkle.EmptyRoot(3), tree.Root())
+}
+
+func TestTree(t *testing.T) {
+	assert := assert.New(t)
+
+	l := leaves(4)
+	tree, err := merkle.NewTree(l)
+	require.NoError(t, err)
+	assert.Equal(2, tree.Depth())
+	assert.Equal(uint64(4), tree.Leaves())
+
+	w := make([]rpo.Digest, len(l))
+	for i := range l {
+		w[i], _ = merkle.Word(l[i])
+	}
+	root := rpo.Merge(rpo.Merge(w[0], w[1]), rpo.Merge(w[2], w[3]))
+	assert.Equal(root, tree.Root())
+
+	node, err := tree.Node(1, 1)
+	assert.NoError(err)
+	assert.Equal(rpo.Merge(w[2], w[3]), node)
+	node, err = tree.Node(0, 0)
+	assert.NoError(err)
+	assert.Equal(root, node)
+
+	path, err := tree.Path(2)
+	assert.NoError(err)
+	assert.Equal(merkle.Path{w[3], rpo.Merge(w[0], w[1])}, path)
+
+	leaf, err := tree.Leaf(3)
+	assert.NoError(err)
+	assert.Equal(l[3], leaf)
+
+	_, err = tree.Node(1, 2)
+	assert.ErrorIs(err, merkle.ErrIndex)
+	_, err = tree.Path(4)
+	assert.ErrorIs(err, merkle.ErrIndex)
+	_, err = tree.Leaf(4)
+	assert.ErrorIs(err, merkle.ErrIndex)
+
+	for _, n := range []int{0, 1, 3, 6} {
+		_, err := merkle.NewTree(leaves(n))
+		assert.ErrorIs(err, merkle.ErrLeafCount, n)
+	}
+}
+
+func TestTreePath(t *testing.T) {
+	assert := assert.New(t)
+
+	l := leaves(16)
+	tree, err := merkle.NewTree(l)
+	require.NoError(t, err)
+
+	for i := range l {
+		path, err := tree.Path(uint64(i))
+		assert.NoError(err)
+		assert.Len(path, 4)
+
+		w, _ := merkle.Word(l[i])
+		assert.True(path.Verify(tree.Root(), uint64(i), w), i)
+		assert.False(path.Verify(tree.Root(), uint64(i^1), w), i)
+		assert.False(path.Verify(tree.Root(), uint64(i+16), w), i)
+
+		path[len(path)-1][0].SetUint64(1)
+		assert.False(path.Verify(tree.Root(), uint64(i), w), i)
+	}
+
+	// Paths of inner nodes
+	node, _ := tree.Node(2, 3)
+	path, err := tree.NodePath(2, 3)
+	assert.NoError(err)
+	assert.True(path.Verify(tree.Root(), 3, node))
+}
+
+func TestTreeUpdateLeaf(t *testing.T) {
+	assert := assert.New(t)
+
+	l := leaves(8)
+	tree, err := merkle.NewTree(l)
+	require.NoError(t, err)
+
+	l[5] = word(1, 2, 3, 4)
+	assert.NoError(tree.UpdateLeaf(5, l[5]))
+	want, err := merkle.NewTree(l)
+	require.NoError(t, err)
+	assert.Equal(want.Root(), tree.Root())
+
+	assert.ErrorIs(tree.UpdateLeaf(8, l[5]), merkle.ErrIndex)
+	assert.ErrorIs(tree.UpdateLeaf(0, l[5][:3]), merkle.ErrWordSize)
+}
+
+// Key with the specified most significant element
+func key(seed, path uint64) rpo.Digest {
+	return digest(seed, seed+1, seed+2, path)
+}
+
+func TestSMTSingleLeaf(t *testing.T) {
+	assert := assert.New(t)
+
+	k := key(1, 0xabcd_0123_4567_89ab)
+	v := word(5, 6, 7, 8)
+
+	smt := merkle.NewSMT()
+	require.NoError(t, smt.Set(k, v))
+	assert.Equal(1, smt.Len())
+	assert.Equal(v, smt.Get(k))
+
+	// The leaf is at depth 16, every other node is empty
+	remaining := key(1, 0x0123_4567_89ab)
+	value, _ := merkle.Word(v)
+	var domain field.Element
+	domain.SetUint64(16)
+	node := rpo.MergeInDomain(remaining, value, domain)
+	path := make(merkle.Path, 16)
+	for i := range path {
+		path[i] = merkle.EmptyRoot(64 - 16 + i)
+	}
+	root, err := path.Root(0xabcd, node)
+	assert.NoError(err)
+	assert.Equal(root, smt.Root())
+
+	p := smt.Prove(k)
+	assert.Equal(16, p.Depth)
+	assert.Equal(remaining, p.RemainingKey)
+	assert.Equal(path, p.Path)
+	got, ok := p.Verify(smt.Root(), k)
+	assert.True(ok)
+	assert.Equal(v, got)
+
+	// Removing the key empties the tree
+	require.NoError(t, smt.Set(k, make(field.Vector, 4)))
+	assert.Equal(0, smt.Len())
+	assert.Equal(merkle.EmptyRoot(64), smt.Root())
+}
+
+func TestSMTTiers(t *testing.T) {
+	// Leaves of std::collections::smt tests
+	table := map[string]struct {
+		path  uint64
+		depth int
+	}{
+		"a": {path: 0b00000000_00000000_11111111_11111111_11111111_11111111_11111111_11111111, depth: 16},
+		"b": {path: 0b10000000_00000000_01111111_11111111_11111111_11111111_11111111_11111111, depth: 32},
+		"c": {path: 0b10000000_00000000_11111111_11111111_11111111_11111111_11111111_11111111, depth: 32},
+		"d": {path: 0b11000000_00000000_00000000_00000000_01111111_11111111_11111111_11111111, depth: 48},
+		"e": {path: 0b11000000_00000000_00000000_00000000_11111111_11111111_11111111_11111111, depth: 48},
+	}
+	// Absent keys sharing the path of present keys up to their leaves
+	absent := map[string]uint64{
+		"x": 0b00000000_00000000_10111111_11111111_11111111_11111111_11111111_11111111,
+		"y": 0b10000000_00000000_10111111_11111111_11111111_11111111_11111111_11111111,
+		"z": 0b11000000_00000000_00000000_00000000_10111111_11111111_11111111_11111111,
+		"w": 0b01000000_00000000_00000000_00000000_10111111_11111111_11111111_11111111,
+	}
+
+	smt := merkle.NewSMT()
+	seed := uint64(0)
+	keys := make(map[string]rpo.Digest)
+	for name, tc := range table {
+		seed += 10
+		keys[name] = key(seed, tc.path)
+		require.NoError(t, smt.Set(keys[name], word(seed, seed, seed, seed)))
+	}
+
+	for name, tc := range table {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			p := smt.Prove(keys[name])
+			assert.Equal(tc.depth, p.Depth)
+			v, ok := p.Verify(smt.Root(), keys[name])
+			assert.True(ok)
+			assert.Equal(smt.Get(keys[name]), v)
+			assert.NotEqual(make(field.Vector, 4), v)
+
+			_, ok = p.Verify(merkle.EmptyRoot(64), keys[name])
+			assert.False(ok)
+		})
+	}
+	for name, path := range absent {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			k := key(1000, path)
+			p := smt.Prove(k)
+			v, ok := p.Verify(smt.Root(), k)
+			assert.True(ok)
+			assert.Equal(make(field.Vector, 4), v)
+		})
+	}
+}
+
+func TestSMTOrder(t *testing.T) {
+	assert := assert.New(t)
+
+	paths := []uint64{
+		0x0001_0000_0000_0000,
+		0x0001_0001_0000_0000,
+		0x0001_0001_0001_0000,
+		0x0001_0001_0002_0000,
+		0x0002_0000_0000_0000,
+		0xffff_ffff_ffff_ffff,
+	}
+	build := func(order []int) *merkle.SMT {
+		smt := merkle.NewSMT()
+		for _, i := range order {
+			v := uint64(i + 1)
+			require.NoError(t, smt.Set(key(v, paths[i]), word(v, 0, 0, 0)))
+		}
+		return smt
+	}
+
+	a := build([]int{0, 1, 2, 3, 4, 5})
+	b := build([]int{5, 3, 1, 4, 2, 0})
+	assert.Equal(a.Root(), b.Root())
+	assert.Equal(6, a.Len())
+
+	// Removing keys moves the remaining leaves up
+	for _, i := range []int{2, 1} {
+		v := uint64(i + 1)
+		require.NoError(t, a.Set(key(v, paths[i]), make(field.Vector, 4)))
+	}
+	c := build([]int{0, 3, 4, 5})
+	assert.Equal(c.Root(), a.Root())
+	assert.Equal(32, a.Prove(key(4, paths[3])).Depth)
+
+	// Updating a value
+	require.NoError(t, a.Set(key(1, paths[0]), word(9, 9, 9, 9)))
+	assert.NotEqual(c.Root(), a.Root())
+	require.NoError(t, c.Set(key(1, paths[0]), word(9, 9, 9, 9)))
+	assert.Equal(c.Root(), a.Root())
+}
+
+func TestSMTErrors(t *testing.T) {
+	assert := assert.New(t)
+
+	smt := merkle.NewSMT()
+	assert.ErrorIs(smt.Set(key(1, 1), word(1, 2, 3, 4)[:3]), merkle.ErrWordSize)
+
+	// Keys sharing 48 bits of their path would need the bottom tier
+	assert.NoError(smt.Set(key(1, 0x1234_5678_9abc_0001), word(1, 2, 3, 4)))
+	root := smt.Root()
+	assert.ErrorIs(smt.Set(key(2, 0x1234_5678_9abc_0002), word(1, 2, 3, 4)), merkle.ErrKeyCollision)
+	assert.Equal(root, smt.Root())
+	assert.Equal(1, smt.Len())
+
+	// Malformed proofs
+	p := smt.Prove(key(1, 0x1234_5678_9abc_0001))
+	p.Depth = 17
+	_, ok := p.Verify(root, key(1, 0x1234_5678_9abc_0001))
+	assert.False(ok)
+	p = smt.Prove(key(1, 0x1234_5678_9abc_0001))
+	p.Path = p.Path[1:]
+	_, ok = p.Verify(root, key(1, 0x1234_5678_9abc_0001))
+	assert.False(ok)
+}
@@ -0,0 +1,275 @@
+package merkle
+
+import (
+	"errors"
+
+	field "github.com/qredo/verifiable-oracles/pkg/goldilocks"
+	"github.com/qredo/verifiable-oracles/pkg/goldilocks/rpo"
+)
+
+// Depths of the nodes which hold the leaves of a sparse Merkle tree.  Miden
+// VM 0.6 does not support the bottom tier, at depth 64.
+var _tiers = [...]int{16, 32, 48}
+
+// ErrKeyCollision is returned when inserting a key whose most significant
+// element shares its 48 most significant bits with another key, as the leaf
+// would belong to the unsupported bottom tier.
+var ErrKeyCollision = errors.New("merkle: key collides with another key beyond depth 48")
+
+type nodeIndex struct {
+	depth int
+	value uint64
+}
+
+func (i nodeIndex) parent() nodeIndex {
+	return nodeIndex{i.depth - 1, i.value >> 1}
+}
+
+// Returns the index of the node of the specified depth on the path of key
+func keyIndex(key rpo.Digest, depth int) nodeIndex {
+	return nodeIndex{depth, key[3].Uint64() >> (MaxDepth - depth)}
+}
+
+// SMT is a sparse Merkle tree of depth 64 mapping keys to values, both words,
+// as the tiered sparse Merkle tree of Miden VM read by the get procedure of
+// the std::collections::smt module.
+//
+// The path of a key is given by its most significant element, key[3].  The
+// leaf of a key is at the shallowest tier of depth d, 16, 32 or 48, where no
+// other key shares the d most significant bits of key[3].  The leaf is the
+// merge, in domain d, of the remaining key, the key with the d most
+// significant bits of key[3] cleared, and of the value.  All other nodes
+// without leaves below are roots of empty subtrees.
+//
+// Zero values are not stored: setting a key to zero removes it.
+type SMT struct {
+	values map[rpo.Digest]rpo.Digest
+	// Keys under each node of the tiers
+	keys map[nodeIndex]map[rpo.Digest]struct{}
+	// Key of the leaf at each node of the tiers
+	leaves map[nodeIndex]rpo.Digest
+	// Nodes which are not empty subtrees
+	nodes map[nodeIndex]rpo.Digest
+	root  rpo.Digest
+}
+
+// NewSMT returns an empty sparse Merkle tree.
+func NewSMT() *SMT {
+	return &SMT{
+		values: make(map[rpo.Digest]rpo.Digest),
+		keys:   make(map[nodeIndex]map[rpo.Digest]struct{}),
+		leaves: make(map[nodeIndex]rpo.Digest),
+		nodes:  make(map[nodeIndex]rpo.Digest),
+		root:   EmptyRoot(MaxDepth),
+	}
+}
+
+// Root returns the root of the tree.
+func (s *SMT) Root() rpo.Digest {
+	return s.root
+}
+
+// Len returns the number of keys with a value.
+func (s *SMT) Len() int {
+	return len(s.values)
+}
+
+// Get returns the value of key, the zero word if key has no value.
+func (s *SMT) Get(key rpo.Digest) field.Vector {
+	v := s.values[key]
+	return v.Vector()
+}
+
+// Set sets the value of key, removing key if value is zero, and updates the
+// root.
+func (s *SMT) Set(key rpo.Digest, value field.Vector) error {
+	w, err := Word(value)
+	if err != nil {
+		return err
+	}
+	if old, ok := s.values[key]; ok && old == w {
+		return nil
+	}
+
+	// Only the leaves of keys sharing the first tier node can move
+	first := keyIndex(key, _tiers[0])
+	group := []rpo.Digest{key}
+	for k := range s.keys[first] {
+		if k != key {
+			group = append(group, k)
+		}
+	}
+
+	remove := w == rpo.Digest{}
+	if _, ok := s.values[key]; !ok && !remove && len(s.keys[keyIndex(key, _tiers[len(_tiers)-1])]) > 0 {
+		return ErrKeyCollision
+	}
+
+	before := make([]int, len(group))
+	for i, k := range group {
+		before[i] = s.tier(k)
+	}
+
+	if remove {
+		delete(s.values, key)
+		for _, d := range _tiers {
+			i := keyIndex(key, d)
+			if delete(s.keys[i], key); len(s.keys[i]) == 0 {
+				delete(s.keys, i)
+			}
+		}
+	} else {
+		s.values[key] = w
+		for _, d := range _tiers {
+			i := keyIndex(key, d)
+			if s.keys[i] == nil {
+				s.keys[i] = make(map[rpo.Digest]struct{})
+			}
+			s.keys[i][key] = struct{}{}
+		}
+	}
+
+	// Clear the leaves which move, then set the leaves which moved or changed
+	after := make([]int, len(group))
+	for i, k := range group {
+		after[i] = s.tier(k)
+		if before[i] >= 0 && after[i] != before[i] {
+			i := keyIndex(k, before[i])
+			delete(s.leaves, i)
+			s.setNode(i, EmptyRoot(MaxDepth-i.depth))
+		}
+	}
+	for i, k := range group {
+		if after[i] >= 0 && (after[i] != before[i] || k == key) {
+			i := keyIndex(k, after[i])
+			s.leaves[i] = k
+			s.setNode(i, leafNode(k, s.values[k], i.depth))
+		}
+	}
+	return nil
+}
+
+// Returns the depth of the leaf of key, -1 if key has no value
+func (s *SMT) tier(key rpo.Digest) int {
+	if _, ok := s.values[key]; !ok {
+		return -1
+	}
+	for _, d := range _tiers {
+		if len(s.keys[keyIndex(key, d)]) == 1 {
+			return d
+		}
+	}
+	return -1
+}
+
+// Returns whether depth is the depth of a tier
+func isTier(depth int) bool {
+	for _, d := range _tiers {
+		if d == depth {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the node of the leaf of key at depth
+func leafNode(key, value rpo.Digest, depth int) rpo.Digest {
+	var domain field.Element
+	domain.SetUint64(uint64(depth))
+	return rpo.MergeInDomain(remainingKey(key, depth), value, domain)
+}
+
+// Returns key with the depth most significant bits of key[3] cleared
+func remainingKey(key rpo.Digest, depth int) rpo.Digest {
+	key[3].SetUint64(key[3].Uint64() << depth >> depth)
+	return key
+}
+
+// Returns the node at i
+func (s *SMT) node(i nodeIndex) rpo.Digest {
+	if n, ok := s.nodes[i]; ok {
+		return n
+	}
+	return EmptyRoot(MaxDepth - i.depth)
+}
+
+// Sets the node at i and updates its ancestors
+func (s *SMT) setNode(i nodeIndex, n rpo.Digest) {
+	for ; i.depth > 0; i = i.parent() {
+		if n == EmptyRoot(MaxDepth-i.depth) {
+			delete(s.nodes, i)
+		} else {
+			s.nodes[i] = n
+		}
+
+		sibling := s.node(nodeIndex{i.depth, i.value ^ 1})
+		if i.value&1 == 0 {
+			n = rpo.Merge(n, sibling)
+		} else {
+			n = rpo.Merge(sibling, n)
+		}
+	}
+	s.root = n
+}
+
+// SMTProof is the opening of a key of a sparse Merkle tree: the node at a
+// tier on the path of the key, either a leaf or an empty subtree, with its
+// authentication path.  It holds the advice read by the get procedure of
+// std::collections::smt.
+type SMTProof struct {
+	// Depth of the node: 16, 32 or 48
+	Depth int
+	// Remaining key and value of the leaf, zero for an empty subtree.  The
+	// leaf may be that of another key, in which case the key has no value.
+	RemainingKey rpo.Digest
+	Value        rpo.Digest
+	// Authentication path of the node
+	Path Path
+}
+
+// Prove returns the opening of key, proving either its value or that it has
+// none.
+func (s *SMT) Prove(key rpo.Digest) SMTProof {
+	var p SMTProof
+	for _, d := range _tiers {
+		i := keyIndex(key, d)
+		k, leaf := s.leaves[i]
+		if !leaf && len(s.keys[i]) > 0 {
+			continue
+		}
+
+		p.Depth = d
+		if leaf {
+			p.RemainingKey, p.Value = remainingKey(k, d), s.values[k]
+		}
+		p.Path = make(Path, 0, d)
+		for ; i.depth > 0; i = i.parent() {
+			p.Path = append(p.Path, s.node(nodeIndex{i.depth, i.value ^ 1}))
+		}
+		break
+	}
+	return p
+}
+
+// Verify returns the value of key proven by p in the tree with the specified
+// root, the zero word if key has no value, and whether p is valid.
+func (p *SMTProof) Verify(root, key rpo.Digest) (field.Vector, bool) {
+	if !isTier(p.Depth) || len(p.Path) != p.Depth {
+		return nil, false
+	}
+
+	i := keyIndex(key, p.Depth)
+	node := EmptyRoot(MaxDepth - p.Depth)
+	if p.Value != (rpo.Digest{}) {
+		node = leafNode(p.RemainingKey, p.Value, p.Depth)
+	}
+	if !p.Path.Verify(root, i.value, node) {
+		return nil, false
+	}
+
+	var v rpo.Digest
+	if p.RemainingKey == remainingKey(key, p.Depth) {
+		v = p.Value
+	}
+	return v.Vector(), true
+}
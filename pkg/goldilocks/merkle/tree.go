@@ -0,0 +1,186 @@
+// Package merkle implements Merkle trees over words of four field elements,
+// with RPO256 digests as nodes, in the layout of the Merkle trees of Miden
+// VM: roots, authentication paths and node indices match those checked by
+// the mtree_get and mtree_verify instructions.
+package merkle
+
+import (
+	"errors"
+	"math/bits"
+
+	field "github.com/qredo/verifiable-oracles/pkg/goldilocks"
+	"github.com/qredo/verifiable-oracles/pkg/goldilocks/rpo"
+)
+
+// MaxDepth is the depth of the deepest trees of Miden VM.
+const MaxDepth = 64
+
+var (
+	// ErrLeafCount is returned when building a tree from a number of leaves
+	// which is not a power of two greater than one.
+	ErrLeafCount = errors.New("merkle: number of leaves must be a power of two greater than one")
+	// ErrWordSize is returned for leaves or values which are not words of
+	// four field elements.
+	ErrWordSize = errors.New("merkle: words must have 4 elements")
+	// ErrIndex is returned for node indices out of the tree.
+	ErrIndex = errors.New("merkle: node index out of range")
+)
+
+// Tree is a Merkle tree, stored as in Miden VM: leaves are words, used as
+// nodes without hashing, and every inner node is the RPO merge of its
+// children.
+type Tree struct {
+	// nodes[1] is the root, nodes[i] has children nodes[2i] and nodes[2i+1],
+	// and the leaves are the second half
+	nodes []rpo.Digest
+	depth int
+}
+
+// NewTree returns the tree with the specified leaves.
+func NewTree(leaves []field.Vector) (*Tree, error) {
+	n := len(leaves)
+	if n < 2 || n&(n-1) != 0 {
+		return nil, ErrLeafCount
+	}
+
+	t := &Tree{
+		nodes: make([]rpo.Digest, 2*n),
+		depth: bits.Len(uint(n)) - 1,
+	}
+	for i, leaf := range leaves {
+		w, err := Word(leaf)
+		if err != nil {
+			return nil, err
+		}
+		t.nodes[n+i] = w
+	}
+	for i := n - 1; i > 0; i-- {
+		t.nodes[i] = rpo.Merge(t.nodes[2*i], t.nodes[2*i+1])
+	}
+	return t, nil
+}
+
+// Word returns the four elements of v as a digest, the type of the nodes of
+// trees.
+func Word(v field.Vector) (rpo.Digest, error) {
+	var w rpo.Digest
+	if len(v) != rpo.DigestSize {
+		return w, ErrWordSize
+	}
+	copy(w[:], v)
+	return w, nil
+}
+
+// Root returns the root of the tree.
+func (t *Tree) Root() rpo.Digest {
+	return t.nodes[1]
+}
+
+// Depth returns the depth of the tree, the base-2 logarithm of the number of
+// leaves.
+func (t *Tree) Depth() int {
+	return t.depth
+}
+
+// Leaves returns the number of leaves of the tree.
+func (t *Tree) Leaves() uint64 {
+	return 1 << t.depth
+}
+
+// Node returns the node at index of the specified depth, where the root has
+// depth 0 and the leaves have depth t.Depth().
+func (t *Tree) Node(depth int, index uint64) (rpo.Digest, error) {
+	if depth < 0 || depth > t.depth || index>>depth != 0 {
+		return rpo.Digest{}, ErrIndex
+	}
+	return t.nodes[1<<depth+index], nil
+}
+
+// Leaf returns the leaf at index.
+func (t *Tree) Leaf(index uint64) (field.Vector, error) {
+	d, err := t.Node(t.depth, index)
+	if err != nil {
+		return nil, err
+	}
+	return d.Vector(), nil
+}
+
+// Path returns the authentication path of the leaf at index.
+func (t *Tree) Path(index uint64) (Path, error) {
+	return t.NodePath(t.depth, index)
+}
+
+// NodePath returns the authentication path of the node at index of the
+// specified depth.
+func (t *Tree) NodePath(depth int, index uint64) (Path, error) {
+	if depth < 0 || depth > t.depth || index>>depth != 0 {
+		return nil, ErrIndex
+	}
+
+	path := make(Path, 0, depth)
+	for i := 1<<depth + index; i > 1; i >>= 1 {
+		path = append(path, t.nodes[i^1])
+	}
+	return path, nil
+}
+
+// UpdateLeaf replaces the leaf at index, updating the nodes up to the root.
+func (t *Tree) UpdateLeaf(index uint64, leaf field.Vector) error {
+	if index>>t.depth != 0 {
+		return ErrIndex
+	}
+	w, err := Word(leaf)
+	if err != nil {
+		return err
+	}
+
+	i := 1<<t.depth + index
+	t.nodes[i] = w
+	for i >>= 1; i > 0; i >>= 1 {
+		t.nodes[i] = rpo.Merge(t.nodes[2*i], t.nodes[2*i+1])
+	}
+	return nil
+}
+
+// Path is an authentication path: the siblings of the nodes from a node up
+// to the root, excluding the root.
+type Path []rpo.Digest
+
+// Root returns the root of the tree in which node is at index of depth
+// len(path), with path as authentication path.
+func (p Path) Root(index uint64, node rpo.Digest) (rpo.Digest, error) {
+	if index>>len(p) != 0 {
+		return rpo.Digest{}, ErrIndex
+	}
+	for _, sibling := range p {
+		if index&1 == 0 {
+			node = rpo.Merge(node, sibling)
+		} else {
+			node = rpo.Merge(sibling, node)
+		}
+		index >>= 1
+	}
+	return node, nil
+}
+
+// Verify returns whether path authenticates node at index of depth
+// len(path) in the tree with the specified root.
+func (p Path) Verify(root rpo.Digest, index uint64, node rpo.Digest) bool {
+	r, err := p.Root(index, node)
+	return err == nil && r == root
+}
+
+var _emptyRoots [MaxDepth + 1]rpo.Digest
+
+func init() {
+	for i := 1; i <= MaxDepth; i++ {
+		_emptyRoots[i] = rpo.Merge(_emptyRoots[i-1], _emptyRoots[i-1])
+	}
+}
+
+// EmptyRoot returns the root of a tree of the specified height, from 0 to
+// MaxDepth, whose leaves are all zero words.  EmptyRoot(64 - d) is the node
+// at depth d of an empty tree of depth 64, as Miden VM's EmptySubtreeRoots.
+func EmptyRoot(height int) rpo.Digest {
+	return _emptyRoots[height]
+}
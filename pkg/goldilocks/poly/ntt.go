@@ -0,0 +1,188 @@
+// Package poly implements polynomial arithmetic over the Goldilocks field,
+// with number-theoretic transforms over power-of-two domains and their
+// cosets.
+package poly
+
+import (
+	"errors"
+	"math/big"
+	"math/bits"
+
+	field "github.com/qredo/verifiable-oracles/pkg/goldilocks"
+)
+
+// MaxLogSize is the base-2 logarithm of the largest domain size.  The
+// multiplicative group of Goldilocks has a subgroup of order 2^32.
+const MaxLogSize = 32
+
+// ErrDomainSize is returned for domain sizes which are not powers of two up
+// to 2^MaxLogSize.
+var ErrDomainSize = errors.New("poly: invalid domain size")
+
+// Generator of the multiplicative group of Goldilocks
+const _generator = 7
+
+// Domain is a multiplicative subgroup of Goldilocks of power-of-two size, or
+// a coset of it, offset·⟨ω⟩.
+type Domain struct {
+	// Number of elements
+	Size int
+	// Generator of the subgroup, ω
+	Generator field.Element
+	// Offset of the coset, 1 for the subgroup itself
+	Offset field.Element
+
+	logSize     int
+	sizeInv     field.Element
+	offsetInv   field.Element
+	twiddles    field.Vector
+	invTwiddles field.Vector
+}
+
+// RootOfUnity returns a primitive root of unity of order 2^logSize.
+func RootOfUnity(logSize int) (field.Element, error) {
+	var w field.Element
+	if logSize < 0 || logSize > MaxLogSize {
+		return w, ErrDomainSize
+	}
+
+	e := field.Modulus()
+	e.Sub(e, big.NewInt(1)).Rsh(e, uint(logSize))
+	w.SetUint64(_generator)
+	w.Exp(w, e)
+	return w, nil
+}
+
+// NewDomain returns the subgroup of order size, which must be a power of two.
+func NewDomain(size int) (*Domain, error) {
+	return NewCosetDomain(size, field.One())
+}
+
+// NewCosetDomain returns the coset offset·⟨ω⟩ of the subgroup of order
+// size, which must be a power of two.  offset must not be zero.
+func NewCosetDomain(size int, offset field.Element) (*Domain, error) {
+	if size <= 0 || size&(size-1) != 0 || offset.IsZero() {
+		return nil, ErrDomainSize
+	}
+
+	logSize := bits.TrailingZeros(uint(size))
+	w, err := RootOfUnity(logSize)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &Domain{
+		Size:      size,
+		Generator: w,
+		Offset:    offset,
+		logSize:   logSize,
+	}
+	d.sizeInv.SetUint64(uint64(size)).Inverse(&d.sizeInv)
+	d.offsetInv.Inverse(&offset)
+
+	var wInv field.Element
+	wInv.Inverse(&w)
+	d.twiddles = powers(w, size/2)
+	d.invTwiddles = powers(wInv, size/2)
+	return d, nil
+}
+
+// Returns 1, x, ..., x^(n-1).
+func powers(x field.Element, n int) field.Vector {
+	v := make(field.Vector, n)
+	if n > 0 {
+		v[0].SetOne()
+	}
+	for i := 1; i < n; i++ {
+		v[i].Mul(&v[i-1], &x)
+	}
+	return v
+}
+
+// Elements returns the elements of the domain, offset·ω^i.
+func (d *Domain) Elements() field.Vector {
+	v := powers(d.Generator, d.Size)
+	for i := range v {
+		v[i].Mul(&v[i], &d.Offset)
+	}
+	return v
+}
+
+// Element returns the element i of the domain, offset·ω^i.
+func (d *Domain) Element(i int) field.Element {
+	var e field.Element
+	e.Exp(d.Generator, big.NewInt(int64(i)))
+	return *e.Mul(&e, &d.Offset)
+}
+
+// NTT transforms the coefficients v of a polynomial of degree lower than
+// d.Size in place into its evaluations over the domain, in natural order.
+func (d *Domain) NTT(v field.Vector) {
+	d.checkSize(v)
+
+	if !d.Offset.IsOne() {
+		scale(v, d.Offset)
+	}
+	transform(v, d.twiddles)
+}
+
+// INTT transforms the evaluations v of a polynomial over the domain in
+// place into its coefficients.  INTT is the inverse of NTT.
+func (d *Domain) INTT(v field.Vector) {
+	d.checkSize(v)
+
+	transform(v, d.invTwiddles)
+	for i := range v {
+		v[i].Mul(&v[i], &d.sizeInv)
+	}
+	if !d.Offset.IsOne() {
+		scale(v, d.offsetInv)
+	}
+}
+
+func (d *Domain) checkSize(v field.Vector) {
+	if len(v) != d.Size {
+		panic("poly: vector size does not match the domain size")
+	}
+}
+
+// Sets v[i] to v[i]·x^i.
+func scale(v field.Vector, x field.Element) {
+	p := field.One()
+	for i := range v {
+		v[i].Mul(&v[i], &p)
+		p.Mul(&p, &x)
+	}
+}
+
+// Radix-2 decimation-in-time transform, twiddles[i] = ω^i.
+func transform(v field.Vector, twiddles field.Vector) {
+	n := len(v)
+	bitReverse(v)
+
+	for half := 1; half < n; half <<= 1 {
+		step := n / (2 * half)
+		for start := 0; start < n; start += 2 * half {
+			for j := 0; j < half; j++ {
+				a, b := &v[start+j], &v[start+j+half]
+				b.Mul(b, &twiddles[j*step])
+				field.Butterfly(a, b)
+			}
+		}
+	}
+}
+
+func bitReverse(v field.Vector) {
+	n := len(v)
+	if n <= 1 {
+		return
+	}
+
+	shift := bits.UintSize - bits.TrailingZeros(uint(n))
+	for i := 0; i < n; i++ {
+		j := int(bits.Reverse(uint(i)) >> shift)
+		if i < j {
+			v[i], v[j] = v[j], v[i]
+		}
+	}
+}
@@ -0,0 +1,265 @@
+package poly
+
+import (
+	"errors"
+	"math/bits"
+
+	field "github.com/qredo/verifiable-oracles/pkg/goldilocks"
+)
+
+var (
+	// ErrNotDivisible is returned when a polynomial is not divisible by the
+	// vanishing polynomial of a domain.
+	ErrNotDivisible = errors.New("poly: polynomial is not divisible")
+	// ErrDegree is returned when a polynomial does not fit in a domain.
+	ErrDegree = errors.New("poly: polynomial degree too large for the domain")
+	// ErrInterpolation is returned when interpolating points with equal or
+	// mismatched x coordinates.
+	ErrInterpolation = errors.New("poly: invalid interpolation points")
+)
+
+// Polynomials are multiplied with NTTs above this number of coefficients
+const _nttThreshold = 64
+
+// Polynomial is a polynomial over Goldilocks, given by its coefficients, in
+// order of increasing degree.  Trailing zero coefficients are allowed.
+type Polynomial field.Vector
+
+// Degree returns the degree of p, -1 for the zero polynomial.
+func (p Polynomial) Degree() int {
+	for i := len(p) - 1; i >= 0; i-- {
+		if !p[i].IsZero() {
+			return i
+		}
+	}
+	return -1
+}
+
+// Trim returns p without its trailing zero coefficients.
+func (p Polynomial) Trim() Polynomial {
+	return p[:p.Degree()+1]
+}
+
+// Equal returns whether p and q are the same polynomial, ignoring trailing
+// zero coefficients.
+func (p Polynomial) Equal(q Polynomial) bool {
+	p, q = p.Trim(), q.Trim()
+	if len(p) != len(q) {
+		return false
+	}
+	for i := range p {
+		if !p[i].Equal(&q[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// Eval evaluates p at x.
+func (p Polynomial) Eval(x field.Element) field.Element {
+	var r field.Element
+	for i := len(p) - 1; i >= 0; i-- {
+		r.Mul(&r, &x).Add(&r, &p[i])
+	}
+	return r
+}
+
+// Add returns p + q.
+func Add(p, q Polynomial) Polynomial {
+	if len(p) < len(q) {
+		p, q = q, p
+	}
+	r := append(Polynomial(nil), p...)
+	for i := range q {
+		r[i].Add(&r[i], &q[i])
+	}
+	return r
+}
+
+// Sub returns p - q.
+func Sub(p, q Polynomial) Polynomial {
+	r := make(Polynomial, max(len(p), len(q)))
+	copy(r, p)
+	for i := range q {
+		r[i].Sub(&r[i], &q[i])
+	}
+	return r
+}
+
+// Scale returns c·p.
+func Scale(p Polynomial, c field.Element) Polynomial {
+	r := make(Polynomial, len(p))
+	for i := range p {
+		r[i].Mul(&p[i], &c)
+	}
+	return r
+}
+
+// Mul returns p·q, using NTTs for large polynomials.
+func Mul(p, q Polynomial) Polynomial {
+	p, q = p.Trim(), q.Trim()
+	if len(p) == 0 || len(q) == 0 {
+		return Polynomial{}
+	}
+	if min(len(p), len(q)) <= _nttThreshold {
+		return MulNaive(p, q)
+	}
+
+	n := len(p) + len(q) - 1
+	size := 1 << bits.Len(uint(n-1))
+	d, err := NewDomain(size)
+	if err != nil {
+		panic(err)
+	}
+
+	a := make(field.Vector, size)
+	b := make(field.Vector, size)
+	copy(a, p)
+	copy(b, q)
+	d.NTT(a)
+	d.NTT(b)
+	for i := range a {
+		a[i].Mul(&a[i], &b[i])
+	}
+	d.INTT(a)
+	return Polynomial(a[:n])
+}
+
+// MulNaive returns p·q, computed in quadratic time.
+func MulNaive(p, q Polynomial) Polynomial {
+	if len(p) == 0 || len(q) == 0 {
+		return Polynomial{}
+	}
+
+	r := make(Polynomial, len(p)+len(q)-1)
+	var t field.Element
+	for i := range p {
+		for j := range q {
+			t.Mul(&p[i], &q[j])
+			r[i+j].Add(&r[i+j], &t)
+		}
+	}
+	return r
+}
+
+// Evaluate returns the evaluations of p over the domain.
+func (d *Domain) Evaluate(p Polynomial) (field.Vector, error) {
+	p = p.Trim()
+	if len(p) > d.Size {
+		return nil, ErrDegree
+	}
+
+	v := make(field.Vector, d.Size)
+	copy(v, p)
+	d.NTT(v)
+	return v, nil
+}
+
+// Interpolate returns the polynomial of degree lower than d.Size taking the
+// values evaluations over the domain.
+func (d *Domain) Interpolate(evaluations field.Vector) Polynomial {
+	v := append(field.Vector(nil), evaluations...)
+	d.INTT(v)
+	return Polynomial(v)
+}
+
+// Vanishing returns the vanishing polynomial of the domain, x^n - offset^n,
+// which is zero exactly over the domain.
+func (d *Domain) Vanishing() Polynomial {
+	z := make(Polynomial, d.Size+1)
+	z[d.Size].SetOne()
+	z[0] = d.offsetPower()
+	z[0].Neg(&z[0])
+	return z
+}
+
+// offset^n
+func (d *Domain) offsetPower() field.Element {
+	c := d.Offset
+	for i := 0; i < d.logSize; i++ {
+		c.Square(&c)
+	}
+	return c
+}
+
+// DivideByVanishing returns p / (x^n - offset^n), the quotient of p by the
+// vanishing polynomial of the domain.  DivideByVanishing returns
+// ErrNotDivisible if p is not zero over the domain.
+func (d *Domain) DivideByVanishing(p Polynomial) (Polynomial, error) {
+	p = p.Trim()
+	n := d.Size
+	if len(p) <= n {
+		if len(p) == 0 {
+			return Polynomial{}, nil
+		}
+		return nil, ErrNotDivisible
+	}
+
+	// p = quotient·(x^n - c) + remainder, eliminating the leading
+	// coefficients one by one
+	c := d.offsetPower()
+	r := append(Polynomial(nil), p...)
+	quotient := make(Polynomial, len(p)-n)
+	var t field.Element
+	for i := len(r) - 1; i >= n; i-- {
+		quotient[i-n] = r[i]
+		t.Mul(&r[i], &c)
+		r[i-n].Add(&r[i-n], &t)
+	}
+
+	if r[:n].Degree() != -1 {
+		return nil, ErrNotDivisible
+	}
+	return quotient, nil
+}
+
+// Interpolate returns the polynomial of lowest degree taking the values ys at
+// the points xs, using Lagrange interpolation in quadratic time.
+func Interpolate(xs, ys field.Vector) (Polynomial, error) {
+	n := len(xs)
+	if len(ys) != n {
+		return nil, ErrInterpolation
+	}
+	if n == 0 {
+		return Polynomial{}, nil
+	}
+
+	// Z(x) = ∏ (x - xs[i])
+	z := Polynomial{field.One()}
+	for i := range xs {
+		var neg field.Element
+		neg.Neg(&xs[i])
+		z = MulNaive(z, Polynomial{neg, field.One()})
+	}
+
+	// Denominators ∏_{j≠i} (xs[i] - xs[j])
+	denominators := make(field.Vector, n)
+	var t field.Element
+	for i := range xs {
+		denominators[i].SetOne()
+		for j := range xs {
+			if i != j {
+				t.Sub(&xs[i], &xs[j])
+				denominators[i].Mul(&denominators[i], &t)
+			}
+		}
+		if denominators[i].IsZero() {
+			return nil, ErrInterpolation
+		}
+	}
+	denominators = field.BatchInvert(denominators)
+
+	r := make(Polynomial, n)
+	for i := range xs {
+		// Z(x) / (x - xs[i]) by synthetic division
+		var carry field.Element
+		var c field.Element
+		c.Mul(&ys[i], &denominators[i])
+		for k := n; k >= 1; k-- {
+			carry.Mul(&carry, &xs[i]).Add(&carry, &z[k])
+			t.Mul(&carry, &c)
+			r[k-1].Add(&r[k-1], &t)
+		}
+	}
+	return r, nil
+}
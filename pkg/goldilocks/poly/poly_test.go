@@ -0,0 +1,233 @@
+package poly_test
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	field "github.com/qredo/verifiable-oracles/pkg/goldilocks"
+	"github.com/qredo/verifiable-oracles/pkg/goldilocks/poly"
+)
+
+func randomVector(r *rand.Rand, n int) field.Vector {
+	v := make(field.Vector, n)
+	for i := range v {
+		v[i].SetUint64(r.Uint64())
+	}
+	return v
+}
+
+// Evaluates p at every element of the domain, in quadratic time.
+func naiveEvaluate(d *poly.Domain, p poly.Polynomial) field.Vector {
+	xs := d.Elements()
+	v := make(field.Vector, len(xs))
+	for i := range xs {
+		v[i] = p.Eval(xs[i])
+	}
+	return v
+}
+
+func TestRootOfUnity(t *testing.T) {
+	assert := assert.New(t)
+
+	w, err := poly.RootOfUnity(poly.MaxLogSize)
+	assert.Nil(err)
+
+	// ω^(2^31) = -1
+	for i := 0; i < poly.MaxLogSize-1; i++ {
+		w.Square(&w)
+	}
+	var minusOne field.Element
+	minusOne.SetInt64(-1)
+	assert.Equal(minusOne, w)
+
+	_, err = poly.RootOfUnity(poly.MaxLogSize + 1)
+	assert.ErrorIs(err, poly.ErrDomainSize)
+}
+
+func TestNewDomain_Error(t *testing.T) {
+	for _, size := range []int{0, -4, 3, 12} {
+		_, err := poly.NewDomain(size)
+		assert.ErrorIs(t, err, poly.ErrDomainSize, "size %d", size)
+	}
+	_, err := poly.NewCosetDomain(8, field.Element{})
+	assert.ErrorIs(t, err, poly.ErrDomainSize)
+}
+
+func TestNTT(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	offset := field.NewElement(7)
+
+	for _, size := range []int{1, 2, 4, 16, 256} {
+		for _, coset := range []bool{false, true} {
+			t.Run(fmt.Sprintf("size %d coset %v", size, coset), func(t *testing.T) {
+				assert := assert.New(t)
+
+				d, err := poly.NewDomain(size)
+				if coset {
+					d, err = poly.NewCosetDomain(size, offset)
+				}
+				if !assert.Nil(err) {
+					return
+				}
+
+				p := poly.Polynomial(randomVector(r, size))
+				v := append(field.Vector(nil), p...)
+				d.NTT(v)
+				assert.Equal(naiveEvaluate(d, p), v)
+
+				d.INTT(v)
+				assert.Equal(field.Vector(p), v)
+			})
+		}
+	}
+}
+
+func TestMul(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+
+	for _, n := range [][2]int{{0, 3}, {1, 1}, {5, 9}, {100, 70}, {300, 513}} {
+		t.Run(fmt.Sprintf("%dx%d", n[0], n[1]), func(t *testing.T) {
+			p := poly.Polynomial(randomVector(r, n[0]))
+			q := poly.Polynomial(randomVector(r, n[1]))
+			assert.True(t, poly.MulNaive(p, q).Equal(poly.Mul(p, q)))
+		})
+	}
+}
+
+func TestArithmetic(t *testing.T) {
+	assert := assert.New(t)
+	r := rand.New(rand.NewSource(3))
+
+	p := poly.Polynomial(randomVector(r, 10))
+	q := poly.Polynomial(randomVector(r, 4))
+	x := field.NewElement(12345)
+
+	var want field.Element
+	sum := poly.Add(p, q).Eval(x)
+	px, qx := p.Eval(x), q.Eval(x)
+	assert.Equal(*want.Add(&px, &qx), sum)
+	diff := poly.Sub(q, p).Eval(x)
+	assert.Equal(*want.Sub(&qx, &px), diff)
+	prod := poly.Mul(p, q).Eval(x)
+	assert.Equal(*want.Mul(&px, &qx), prod)
+	scaled := poly.Scale(p, x).Eval(x)
+	assert.Equal(*want.Mul(&px, &x), scaled)
+
+	assert.Equal(9, p.Degree())
+	assert.Equal(-1, poly.Polynomial{{}, {}}.Degree())
+	assert.True(poly.Sub(p, p).Equal(poly.Polynomial{}))
+}
+
+func TestDomain_Interpolate(t *testing.T) {
+	assert := assert.New(t)
+	r := rand.New(rand.NewSource(4))
+
+	d, err := poly.NewCosetDomain(32, field.NewElement(3))
+	if !assert.Nil(err) {
+		return
+	}
+
+	p := poly.Polynomial(randomVector(r, 20))
+	v, err := d.Evaluate(p)
+	assert.Nil(err)
+	assert.True(p.Equal(d.Interpolate(v)))
+
+	_, err = d.Evaluate(poly.Polynomial(randomVector(r, 33)))
+	assert.ErrorIs(err, poly.ErrDegree)
+}
+
+func TestInterpolate(t *testing.T) {
+	assert := assert.New(t)
+	r := rand.New(rand.NewSource(5))
+
+	p := poly.Polynomial(randomVector(r, 12))
+	xs := randomVector(r, 12)
+	ys := make(field.Vector, len(xs))
+	for i := range xs {
+		ys[i] = p.Eval(xs[i])
+	}
+
+	q, err := poly.Interpolate(xs, ys)
+	assert.Nil(err)
+	assert.True(p.Equal(q))
+
+	xs[1] = xs[0]
+	_, err = poly.Interpolate(xs, ys)
+	assert.ErrorIs(err, poly.ErrInterpolation)
+	_, err = poly.Interpolate(xs, ys[1:])
+	assert.ErrorIs(err, poly.ErrInterpolation)
+}
+
+func TestDivideByVanishing(t *testing.T) {
+	assert := assert.New(t)
+	r := rand.New(rand.NewSource(6))
+
+	for _, offset := range []uint64{1, 7} {
+		d, err := poly.NewCosetDomain(16, field.NewElement(offset))
+		if !assert.Nil(err) {
+			return
+		}
+
+		z := d.Vanishing()
+		for _, x := range d.Elements() {
+			y := z.Eval(x)
+			assert.True(y.IsZero())
+		}
+
+		q := poly.Polynomial(randomVector(r, 40))
+		quotient, err := d.DivideByVanishing(poly.Mul(q, z))
+		assert.Nil(err)
+		assert.True(q.Equal(quotient))
+
+		_, err = d.DivideByVanishing(poly.Add(poly.Mul(q, z), poly.Polynomial{field.One()}))
+		assert.ErrorIs(err, poly.ErrNotDivisible)
+		_, err = d.DivideByVanishing(q[:5])
+		assert.ErrorIs(err, poly.ErrNotDivisible)
+	}
+}
+
+func BenchmarkNTT(b *testing.B) {
+	r := rand.New(rand.NewSource(7))
+	for _, logSize := range []int{10, 16} {
+		d, _ := poly.NewDomain(1 << logSize)
+		v := randomVector(r, d.Size)
+
+		b.Run(fmt.Sprintf("2^%d", logSize), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				d.NTT(v)
+			}
+		})
+	}
+}
+
+func BenchmarkNaiveEvaluate(b *testing.B) {
+	r := rand.New(rand.NewSource(7))
+	d, _ := poly.NewDomain(1 << 10)
+	p := poly.Polynomial(randomVector(r, d.Size))
+
+	b.Run("2^10", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			naiveEvaluate(d, p)
+		}
+	})
+}
+
+func BenchmarkMul(b *testing.B) {
+	r := rand.New(rand.NewSource(8))
+	p := poly.Polynomial(randomVector(r, 1024))
+	q := poly.Polynomial(randomVector(r, 1024))
+
+	b.Run("ntt", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			poly.Mul(p, q)
+		}
+	})
+	b.Run("naive", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			poly.MulNaive(p, q)
+		}
+	})
+}
@@ -0,0 +1,139 @@
+// Package randomcoin implements the public coin of the Fiat-Shamir transform
+// over the Goldilocks field, as the DefaultRandomCoin of Winterfell
+// instantiated with RPO256: absorbing the same elements and digests, Go and
+// Rust draw the same challenges, query positions and proof-of-work checks.
+//
+// This is the coin of the Winterfell prover and of its FRI verifier, not the
+// sponge-based RpoRandomCoin of Miden VM's recursive STARK verifier.
+package randomcoin
+
+import (
+	"errors"
+	"math/bits"
+
+	field "github.com/qredo/verifiable-oracles/pkg/goldilocks"
+	"github.com/qredo/verifiable-oracles/pkg/goldilocks/rpo"
+)
+
+// Maximum number of digests drawn to find integers, as in Winterfell
+const maxTries = 1000
+
+var (
+	// ErrDomainSize is returned when drawing integers from a domain whose size
+	// is not a power of two greater than the number of integers.
+	ErrDomainSize = errors.New("randomcoin: domain size must be a power of two greater than the number of values")
+	// ErrDrawIntegers is returned when not enough distinct integers were
+	// drawn.
+	ErrDrawIntegers = errors.New("randomcoin: failed to draw distinct integers")
+)
+
+// RandomCoin is a pseudo-random generator seeded by a digest, the hash of
+// everything absorbed so far.  Every draw hashes the seed with a counter,
+// which is reset when the coin is reseeded.
+type RandomCoin struct {
+	seed    rpo.Digest
+	counter uint64
+}
+
+// New returns a coin seeded with the hash of seed.
+func New(seed field.Vector) *RandomCoin {
+	return &RandomCoin{seed: rpo.HashElements(seed)}
+}
+
+// Seed returns the current seed of the coin.
+func (c *RandomCoin) Seed() rpo.Digest {
+	return c.seed
+}
+
+// Reseed absorbs data, typically a commitment, by merging it with the seed.
+func (c *RandomCoin) Reseed(data rpo.Digest) {
+	c.seed = rpo.Merge(c.seed, data)
+	c.counter = 0
+}
+
+// ReseedElements absorbs elements, by reseeding the coin with their hash.
+func (c *RandomCoin) ReseedElements(elements field.Vector) {
+	c.Reseed(rpo.HashElements(elements))
+}
+
+// ReseedWithInt absorbs value, typically a proof-of-work nonce.
+func (c *RandomCoin) ReseedWithInt(value uint64) {
+	c.seed = rpo.MergeWithInt(c.seed, value)
+	c.counter = 0
+}
+
+// LeadingZeros returns the number of trailing zero bits of the first element
+// of the seed, the work proven by the last nonce absorbed.  Winterfell calls
+// them leading zeros as it reads the element from little-endian bytes.
+func (c *RandomCoin) LeadingZeros() int {
+	return leadingZeros(c.seed)
+}
+
+// CheckLeadingZeros returns the work proven by nonce: LeadingZeros after
+// ReseedWithInt(nonce), without changing the coin.
+func (c *RandomCoin) CheckLeadingZeros(nonce uint64) int {
+	return leadingZeros(rpo.MergeWithInt(c.seed, nonce))
+}
+
+// Grind returns the smallest positive nonce proving at least grindingFactor
+// bits of work.  Verifiers accept any such nonce, not only the smallest.  The
+// expected number of hashes is 2^grindingFactor.
+func (c *RandomCoin) Grind(grindingFactor int) uint64 {
+	nonce := uint64(1)
+	for c.CheckLeadingZeros(nonce) < grindingFactor {
+		nonce++
+	}
+	return nonce
+}
+
+func leadingZeros(d rpo.Digest) int {
+	return bits.TrailingZeros64(d[0].Uint64())
+}
+
+// Returns the next pseudo-random digest.
+func (c *RandomCoin) next() rpo.Digest {
+	c.counter++
+	return rpo.MergeWithInt(c.seed, c.counter)
+}
+
+// Draw returns a pseudo-random field element.
+func (c *RandomCoin) Draw() field.Element {
+	return c.next()[0]
+}
+
+// DrawE2 returns a pseudo-random element of the quadratic extension.
+func (c *RandomCoin) DrawE2() field.E2 {
+	d := c.next()
+	return field.E2{A0: d[0], A1: d[1]}
+}
+
+// DrawE3 returns a pseudo-random element of the cubic extension.
+func (c *RandomCoin) DrawE3() field.E3 {
+	d := c.next()
+	return field.E3{A0: d[0], A1: d[1], A2: d[2]}
+}
+
+// DrawIntegers returns n distinct pseudo-random integers smaller than
+// domainSize, a power of two greater than n, typically query positions in an
+// evaluation domain.
+func (c *RandomCoin) DrawIntegers(n, domainSize int) ([]int, error) {
+	if domainSize <= 0 || domainSize&(domainSize-1) != 0 || n >= domainSize {
+		return nil, ErrDomainSize
+	}
+
+	mask := uint64(domainSize - 1)
+	values := make([]int, 0, n)
+	seen := make(map[int]bool, n)
+	for i := 0; i < maxTries && len(values) < n; i++ {
+		d := c.next()
+		v := int(d[0].Uint64() & mask)
+		if !seen[v] {
+			seen[v] = true
+			values = append(values, v)
+		}
+	}
+	if len(values) < n {
+		return nil, ErrDrawIntegers
+	}
+	return values, nil
+}
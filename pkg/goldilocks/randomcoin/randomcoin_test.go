@@ -0,0 +1,169 @@
+package randomcoin_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	field "github.com/qredo/verifiable-oracles/pkg/goldilocks"
+	"github.com/qredo/verifiable-oracles/pkg/goldilocks/randomcoin"
+	"github.com/qredo/verifiable-oracles/pkg/goldilocks/rpo"
+)
+
+func vector(n int) field.Vector {
+	v := make(field.Vector, n)
+	for i := range v {
+		v[i].SetUint64(uint64(i + 1))
+	}
+	return v
+}
+
+func TestRandomCoin_Draw(t *testing.T) {
+	assert := assert.New(t)
+
+	seed := rpo.HashElements(vector(3))
+	coin := randomcoin.New(vector(3))
+	assert.Equal(seed, coin.Seed())
+
+	// Draws hash the seed with a counter
+	assert.Equal(rpo.MergeWithInt(seed, 1)[0], coin.Draw())
+	d := rpo.MergeWithInt(seed, 2)
+	assert.Equal(field.E2{A0: d[0], A1: d[1]}, coin.DrawE2())
+	d = rpo.MergeWithInt(seed, 3)
+	assert.Equal(field.E3{A0: d[0], A1: d[1], A2: d[2]}, coin.DrawE3())
+
+	// Reseeding merges with the seed and resets the counter
+	data := rpo.HashElements(vector(5))
+	coin.Reseed(data)
+	seed = rpo.Merge(seed, data)
+	assert.Equal(seed, coin.Seed())
+	assert.Equal(rpo.MergeWithInt(seed, 1)[0], coin.Draw())
+
+	coin.ReseedElements(vector(5))
+	seed = rpo.Merge(seed, data)
+	assert.Equal(seed, coin.Seed())
+
+	coin.ReseedWithInt(42)
+	seed = rpo.MergeWithInt(seed, 42)
+	assert.Equal(seed, coin.Seed())
+	assert.Equal(rpo.MergeWithInt(seed, 1)[0], coin.Draw())
+
+	// Coins absorbing the same data draw the same challenges
+	a, b := randomcoin.New(vector(8)), randomcoin.New(vector(8))
+	for i := 0; i < 4; i++ {
+		a.Reseed(data)
+		b.Reseed(data)
+		assert.Equal(a.Draw(), b.Draw())
+	}
+	b.ReseedWithInt(1)
+	assert.NotEqual(a.Draw(), b.Draw())
+}
+
+func TestRandomCoin_DrawIntegers(t *testing.T) {
+	table := map[string]struct {
+		n, domainSize int
+		err           error
+	}{
+		"one":          {n: 1, domainSize: 2},
+		"queries":      {n: 27, domainSize: 1 << 10},
+		"almost full":  {n: 15, domainSize: 16},
+		"full":         {n: 16, domainSize: 16, err: randomcoin.ErrDomainSize},
+		"not power":    {n: 3, domainSize: 24, err: randomcoin.ErrDomainSize},
+		"empty domain": {n: 0, domainSize: 0, err: randomcoin.ErrDomainSize},
+	}
+	for name, tc := range table {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			coin := randomcoin.New(vector(2))
+			values, err := coin.DrawIntegers(tc.n, tc.domainSize)
+			if tc.err != nil {
+				assert.ErrorIs(err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Len(values, tc.n)
+
+			seen := make(map[int]bool)
+			for _, v := range values {
+				assert.False(seen[v], v)
+				assert.Less(v, tc.domainSize)
+				assert.GreaterOrEqual(v, 0)
+				seen[v] = true
+			}
+
+			// The first value is the low bits of the first draw
+			first := randomcoin.New(vector(2)).Draw()
+			assert.Equal(int(first.Uint64()%uint64(tc.domainSize)), values[0])
+		})
+	}
+}
+
+func TestRandomCoin_Grind(t *testing.T) {
+	assert := assert.New(t)
+
+	coin := randomcoin.New(vector(4))
+	coin.Reseed(rpo.HashElements(vector(1)))
+
+	const grindingFactor = 8
+	nonce := coin.Grind(grindingFactor)
+	assert.GreaterOrEqual(coin.CheckLeadingZeros(nonce), grindingFactor)
+	for n := uint64(1); n < nonce; n++ {
+		assert.Less(coin.CheckLeadingZeros(n), grindingFactor, n)
+	}
+
+	// The verifier absorbs the nonce before checking the work
+	seed := coin.Seed()
+	work := coin.CheckLeadingZeros(nonce)
+	assert.Equal(seed, coin.Seed())
+	coin.ReseedWithInt(nonce)
+	assert.Equal(work, coin.LeadingZeros())
+}
+
+// Vectors drawn from Winterfell's DefaultRandomCoin instantiated with RPO256,
+// checked in as testdata/winterfell.json.  Regenerate them with
+//
+//	cargo run --manifest-path testdata/winterfell/Cargo.toml > testdata/winterfell.json
+type winterfellVectors struct {
+	Draw              uint64    `json:"draw"`
+	DrawE2            [2]uint64 `json:"draw_e2"`
+	Integers          []int     `json:"integers"`
+	LeadingZeros      int       `json:"leading_zeros"`
+	CheckLeadingZeros []int     `json:"check_leading_zeros"`
+}
+
+func TestRandomCoin_Winterfell(t *testing.T) {
+	data, err := os.ReadFile("testdata/winterfell.json")
+	if errors.Is(err, fs.ErrNotExist) {
+		t.Skip("testdata/winterfell.json not generated, see winterfellVectors")
+	}
+	require.Nil(t, err)
+
+	var want winterfellVectors
+	require.Nil(t, json.Unmarshal(data, &want))
+
+	assert := assert.New(t)
+
+	coin := randomcoin.New(vector(3))
+	draw := coin.Draw()
+	assert.Equal(want.Draw, draw.Uint64())
+	e2 := coin.DrawE2()
+	assert.Equal(want.DrawE2, [2]uint64{e2.A0.Uint64(), e2.A1.Uint64()})
+
+	coin.Reseed(rpo.HashElements(vector(5)))
+	integers, err := coin.DrawIntegers(27, 1024)
+	assert.Nil(err)
+	assert.Equal(want.Integers, integers)
+
+	coin.ReseedWithInt(42)
+	assert.Equal(want.LeadingZeros, coin.LeadingZeros())
+	for i, work := range want.CheckLeadingZeros {
+		nonce := uint64(i + 1)
+		assert.Equal(work, coin.CheckLeadingZeros(nonce), nonce)
+	}
+}
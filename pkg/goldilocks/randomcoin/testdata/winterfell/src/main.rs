@@ -0,0 +1,38 @@
+//! Prints test vectors of Winterfell's DefaultRandomCoin instantiated with
+//! RPO256 as JSON, checked in as testdata/winterfell.json for
+//! TestRandomCoin_Winterfell:
+//!
+//!     cargo run --manifest-path testdata/winterfell/Cargo.toml > testdata/winterfell.json
+
+use miden_crypto::{hash::rpo::Rpo256, Felt};
+use winter_crypto::{DefaultRandomCoin, ElementHasher, RandomCoin};
+use winter_math::{fields::QuadExtension, FieldElement, StarkField};
+
+fn elements(n: u64) -> Vec<Felt> {
+    (1..=n).map(Felt::new).collect()
+}
+
+fn main() {
+    let mut coin = DefaultRandomCoin::<Rpo256>::new(&elements(3));
+
+    let draw: Felt = coin.draw().unwrap();
+    let draw_e2: QuadExtension<Felt> = coin.draw().unwrap();
+    let e2 = QuadExtension::<Felt>::as_base_elements(&[draw_e2]).to_vec();
+
+    coin.reseed(Rpo256::hash_elements(&elements(5)));
+    let integers = coin.draw_integers(27, 1024).unwrap();
+
+    coin.reseed_with_int(42);
+    let leading_zeros = coin.leading_zeros();
+    let check_leading_zeros: Vec<u32> = (1..=16).map(|nonce| coin.check_leading_zeros(nonce)).collect();
+
+    println!(
+        "{{\"draw\":{},\"draw_e2\":[{},{}],\"integers\":{:?},\"leading_zeros\":{},\"check_leading_zeros\":{:?}}}",
+        draw.as_int(),
+        e2[0].as_int(),
+        e2[1].as_int(),
+        integers,
+        leading_zeros,
+        check_leading_zeros,
+    );
+}
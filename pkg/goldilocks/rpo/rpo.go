@@ -0,0 +1,261 @@
+// Package rpo implements the Rescue Prime Optimized hash function RPO256 over
+// the Goldilocks field, as used natively by Miden VM.  Digests computed by
+// this package match those of the hperm instruction and the Miden standard
+// library, so Go code can compute the commitments checked by MASM programs.
+package rpo
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+
+	field "github.com/qredo/verifiable-oracles/pkg/goldilocks"
+)
+
+const (
+	// Number of field elements of the state
+	StateWidth = 12
+	// Number of field elements absorbed per permutation
+	Rate = 8
+	// Number of field elements of the capacity
+	Capacity = StateWidth - Rate
+	// Number of field elements of a digest
+	DigestSize = 4
+	// Number of rounds of the permutation
+	NumRounds = 7
+	// Exponent of the S-box
+	Alpha = 7
+
+	// Bytes absorbed per field element by Hash.  Any 7 bytes map to a field
+	// element.
+	binaryChunkSize = 7
+)
+
+// State is the state of the RPO permutation.  Elements 0 to 3 are the
+// capacity, elements 4 to 11 the rate, and elements 4 to 7 the digest.
+type State [StateWidth]field.Element
+
+// Digest is the output of RPO256.
+type Digest [DigestSize]field.Element
+
+var (
+	_mds         [StateWidth][StateWidth]field.Element
+	_ark1, _ark2 [NumRounds]State
+	_modulus     = field.Modulus().Uint64()
+)
+
+func init() {
+	for i := 0; i < StateWidth; i++ {
+		for j := 0; j < StateWidth; j++ {
+			_mds[i][j].SetUint64(_mdsRow[(j-i+StateWidth)%StateWidth])
+		}
+	}
+	for r := 0; r < NumRounds; r++ {
+		for i := 0; i < StateWidth; i++ {
+			_ark1[r][i].SetUint64(_ark1Values[r][i])
+			_ark2[r][i].SetUint64(_ark2Values[r][i])
+		}
+	}
+}
+
+// Permute applies the RPO permutation to state.
+func Permute(state *State) {
+	for r := 0; r < NumRounds; r++ {
+		state.applyMDS()
+		state.addConstants(&_ark1[r])
+		state.applySbox()
+
+		state.applyMDS()
+		state.addConstants(&_ark2[r])
+		state.applyInvSbox()
+	}
+}
+
+func (s *State) applyMDS() {
+	var (
+		result State
+		t      field.Element
+	)
+	for i := range result {
+		for j := range s {
+			t.Mul(&_mds[i][j], &s[j])
+			result[i].Add(&result[i], &t)
+		}
+	}
+	*s = result
+}
+
+func (s *State) addConstants(ark *State) {
+	for i := range s {
+		s[i].Add(&s[i], &ark[i])
+	}
+}
+
+// x^7
+func (s *State) applySbox() {
+	var x2, x4 field.Element
+	for i := range s {
+		x2.Square(&s[i])
+		x4.Square(&x2)
+		s[i].Mul(&s[i], &x2).Mul(&s[i], &x4)
+	}
+}
+
+// x^(1/7), computed with the addition chain of Miden VM for
+// 10540996611094048183 =
+// 0b1001001001001001001001001001000110110110110110110110110110110111
+func (s *State) applyInvSbox() {
+	var a, b field.Element
+	for i := range s {
+		x := &s[i]
+
+		var t1, t2 field.Element
+		t1.Square(x)
+		t2.Square(&t1)
+		t3 := expAcc(t2, 3, t2)
+		t4 := expAcc(t3, 6, t3)
+		t5 := expAcc(t4, 12, t4)
+		t6 := expAcc(t5, 6, t3)
+		t7 := expAcc(t6, 31, t6)
+
+		a.Square(&t7).Mul(&a, &t6).Square(&a).Square(&a)
+		b.Mul(&t1, &t2).Mul(&b, x)
+		x.Mul(&a, &b)
+	}
+}
+
+// Returns base^(2^m) * tail.
+func expAcc(base field.Element, m int, tail field.Element) field.Element {
+	for i := 0; i < m; i++ {
+		base.Square(&base)
+	}
+	return *base.Mul(&base, &tail)
+}
+
+// Digest returns the digest part of the state.
+func (s *State) Digest() (d Digest) {
+	copy(d[:], s[Capacity:Capacity+DigestSize])
+	return
+}
+
+// HashElements hashes a vector of field elements.  The input is padded with
+// a one and as many zeros as needed to fill the rate, and the first
+// capacity element is set to one, if its length is not a multiple of Rate.
+func HashElements(elements field.Vector) Digest {
+	var state State
+	if len(elements)%Rate != 0 {
+		state[0].SetOne()
+	}
+
+	i := 0
+	for _, e := range elements {
+		state[Capacity+i] = e
+		if i++; i == Rate {
+			Permute(&state)
+			i = 0
+		}
+	}
+
+	if i > 0 {
+		state[Capacity+i].SetOne()
+		for i++; i < Rate; i++ {
+			state[Capacity+i].SetZero()
+		}
+		Permute(&state)
+	}
+	return state.Digest()
+}
+
+// Hash hashes a byte string.  The bytes are absorbed in 7-byte little-endian
+// chunks, and the last chunk is terminated by a byte with value 1.
+func Hash(b []byte) Digest {
+	n := (len(b) + binaryChunkSize - 1) / binaryChunkSize
+
+	var state State
+	if n%Rate != 0 {
+		state[0].SetOne()
+	}
+
+	var (
+		i   int
+		buf [8]byte
+		e   field.Element
+	)
+	for k := 0; k < n; k++ {
+		chunk := b[k*binaryChunkSize : min((k+1)*binaryChunkSize, len(b))]
+		buf = [8]byte{}
+		copy(buf[:], chunk)
+		if k == n-1 {
+			buf[len(chunk)] = 1
+		}
+
+		e.SetUint64(binary.LittleEndian.Uint64(buf[:]))
+		state[Capacity+i].Add(&state[Capacity+i], &e)
+		if i++; i == Rate {
+			Permute(&state)
+			i = 0
+		}
+	}
+
+	// The padding is implied by the capacity
+	if i > 0 {
+		Permute(&state)
+	}
+	return state.Digest()
+}
+
+// Merge hashes two digests into one, as the nodes of a Merkle tree.
+func Merge(a, b Digest) Digest {
+	return MergeInDomain(a, b, field.Element{})
+}
+
+// MergeInDomain is like Merge, but sets the second capacity element to
+// domain, to separate hashes of different kinds of nodes.
+func MergeInDomain(a, b Digest, domain field.Element) Digest {
+	var state State
+	state[1] = domain
+	copy(state[Capacity:], a[:])
+	copy(state[Capacity+DigestSize:], b[:])
+
+	Permute(&state)
+	return state.Digest()
+}
+
+// MergeWithInt hashes a digest and an integer, as the seeds of Winterfell's
+// random coin.  The integer is absorbed as one field element, or as two, its
+// value modulo q and its quotient by q, if it is not smaller than q.  The
+// input is padded with a one, and the first capacity element is set to one.
+func MergeWithInt(seed Digest, value uint64) Digest {
+	var state State
+	state[0].SetOne()
+	copy(state[Capacity:], seed[:])
+	i := Capacity + DigestSize
+	state[i].SetUint64(value)
+	if value >= _modulus {
+		i++
+		state[i].SetUint64(value / _modulus)
+	}
+	state[i+1].SetOne()
+
+	Permute(&state)
+	return state.Digest()
+}
+
+// Vector returns the digest elements.
+func (d Digest) Vector() field.Vector {
+	return append(field.Vector(nil), d[:]...)
+}
+
+// Bytes returns the canonical values of the digest elements, in
+// little-endian order, as serialized by Miden VM.
+func (d Digest) Bytes() []byte {
+	b := make([]byte, 0, DigestSize*field.Bytes)
+	for i := range d {
+		b = binary.LittleEndian.AppendUint64(b, d[i].Uint64())
+	}
+	return b
+}
+
+// String returns the hexadecimal representation of the digest bytes.
+func (d Digest) String() string {
+	return hex.EncodeToString(d.Bytes())
+}
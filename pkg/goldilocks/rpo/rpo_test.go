@@ -0,0 +1,156 @@
+package rpo_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	field "github.com/qredo/verifiable-oracles/pkg/goldilocks"
+	"github.com/qredo/verifiable-oracles/pkg/goldilocks/rpo"
+)
+
+func digest(v ...uint64) (d rpo.Digest) {
+	for i := range d {
+		d[i].SetUint64(v[i])
+	}
+	return
+}
+
+func vector(n int) field.Vector {
+	v := make(field.Vector, n)
+	for i := range v {
+		v[i].SetUint64(uint64(i + 1))
+	}
+	return v
+}
+
+// Roots of empty subtrees from the Miden standard library collections::smt
+var _emptySubtreeTable = map[string]struct {
+	height int
+	want   rpo.Digest
+}{
+	"EMPTY_48": {height: 16, want: digest(10650694022550988030, 5634734408638476525, 9233115969432897632, 1437907447409278328)},
+	"EMPTY_32": {height: 32, want: digest(11677748883385181208, 15891398395707500576, 3790704659934033620, 2126099371106695189)},
+	"EMPTY_16": {height: 48, want: digest(17483286922353768131, 353378057542380712, 1935183237414585408, 4820339620987989650)},
+}
+
+func TestMerge_EmptySubtree(t *testing.T) {
+	for name, tc := range _emptySubtreeTable {
+		t.Run(name, func(t *testing.T) {
+			var d rpo.Digest
+			for i := 0; i < tc.height; i++ {
+				d = rpo.Merge(d, d)
+			}
+			assert.Equal(t, tc.want, d)
+		})
+	}
+}
+
+func TestMerge(t *testing.T) {
+	assert := assert.New(t)
+
+	a, b := digest(1, 2, 3, 4), digest(5, 6, 7, 8)
+
+	// Merging is hashing a full rate
+	assert.Equal(rpo.HashElements(vector(8)), rpo.Merge(a, b))
+	assert.NotEqual(rpo.Merge(a, b), rpo.Merge(b, a))
+
+	var domain field.Element
+	assert.Equal(rpo.Merge(a, b), rpo.MergeInDomain(a, b, domain))
+	domain.SetUint64(1)
+	assert.NotEqual(rpo.Merge(a, b), rpo.MergeInDomain(a, b, domain))
+}
+
+func TestMergeWithInt(t *testing.T) {
+	seed := digest(1, 2, 3, 4)
+	q := field.Modulus().Uint64()
+
+	// Merging with an integer is hashing the seed and its elements
+	table := map[string]struct {
+		value    uint64
+		elements []uint64
+	}{
+		"zero":    {value: 0, elements: []uint64{0}},
+		"small":   {value: 5, elements: []uint64{5}},
+		"q-1":     {value: q - 1, elements: []uint64{q - 1}},
+		"q":       {value: q, elements: []uint64{0, 1}},
+		"max":     {value: 1<<64 - 1, elements: []uint64{1<<64 - 1 - q, 1}},
+		"above q": {value: q + 7, elements: []uint64{7, 1}},
+	}
+	for name, tc := range table {
+		t.Run(name, func(t *testing.T) {
+			v := seed.Vector()
+			for _, e := range tc.elements {
+				v = append(v, field.NewElement(e))
+			}
+			assert.Equal(t, rpo.HashElements(v), rpo.MergeWithInt(seed, tc.value))
+		})
+	}
+}
+
+func TestHashElements(t *testing.T) {
+	assert := assert.New(t)
+
+	seen := make(map[rpo.Digest]int)
+	for n := 0; n <= 3*rpo.Rate; n++ {
+		d := rpo.HashElements(vector(n))
+		if prev, ok := seen[d]; ok {
+			t.Errorf("lengths %d and %d collide", prev, n)
+		}
+		seen[d] = n
+	}
+
+	// Padding is distinguished from trailing elements
+	padded := append(vector(3), field.One())
+	assert.NotEqual(rpo.HashElements(vector(3)), rpo.HashElements(padded))
+}
+
+func TestHash(t *testing.T) {
+	assert := assert.New(t)
+
+	seen := make(map[rpo.Digest]int)
+	for n := 0; n <= 64; n++ {
+		d := rpo.Hash(make([]byte, n))
+		if prev, ok := seen[d]; ok {
+			t.Errorf("lengths %d and %d collide", prev, n)
+		}
+		seen[d] = n
+	}
+
+	// A 7-byte chunk is a field element
+	var e field.Element
+	e.SetUint64(0x01_07_06_05_04_03_02_01)
+	d := rpo.Hash([]byte{1, 2, 3, 4, 5, 6, 7})
+	assert.NotEqual(rpo.HashElements(field.Vector{e}), d)
+	assert.Equal(rpo.Hash([]byte{1, 2, 3, 4, 5, 6, 7}), d)
+}
+
+func TestDigest_Bytes(t *testing.T) {
+	assert := assert.New(t)
+
+	d := digest(1, 2, 0xffffffff00000000, 4)
+	assert.Equal("0100000000000000"+"0200000000000000"+"00000000ffffffff"+"0400000000000000", d.String())
+	assert.Len(d.Bytes(), 32)
+	assert.Equal(field.Vector(d[:]), d.Vector())
+}
+
+func BenchmarkPermute(b *testing.B) {
+	var state rpo.State
+	for i := range state {
+		state[i].SetUint64(uint64(i))
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		rpo.Permute(&state)
+	}
+}
+
+func BenchmarkHashElements(b *testing.B) {
+	v := vector(1024)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		rpo.HashElements(v)
+	}
+}
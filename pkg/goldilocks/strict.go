@@ -0,0 +1,111 @@
+package goldilocks
+
+import (
+	"encoding"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Strict parsing accepts exactly one encoding per element, its canonical
+// value in [0, q), where SetString and SetBytes reduce any integer modulo q.
+// Values read from outside, such as proofs and program inputs, must be
+// parsed strictly so that distinct encodings cannot denote the same element.
+// Vector.ReadFrom and Vector.UnmarshalBinary are strict.
+
+var (
+	// ErrNonCanonical is returned when parsing a value which is not smaller
+	// than the modulus.
+	ErrNonCanonical = errors.New("goldilocks: non-canonical element encoding")
+	// ErrSyntax is returned when parsing a string which is not a number in
+	// the expected format.
+	ErrSyntax = errors.New("goldilocks: invalid element syntax")
+)
+
+// ParseDecimal parses the canonical decimal representation of an element:
+// digits only, without sign, leading zeros or underscores, of a value
+// smaller than q.
+func ParseDecimal(s string) (Element, error) {
+	if len(s) > 1 && s[0] == '0' {
+		return Element{}, fmt.Errorf("%w: %q", ErrSyntax, s)
+	}
+	return parseUint(s, 10)
+}
+
+// ParseHex parses the hexadecimal representation of an element, with a 0x
+// prefix, of a value smaller than q.  Leading zeros are allowed, so that
+// elements may be written on 16 digits.
+func ParseHex(s string) (Element, error) {
+	digits, ok := strings.CutPrefix(s, "0x")
+	if !ok {
+		return Element{}, fmt.Errorf("%w: %q", ErrSyntax, s)
+	}
+	return parseUint(digits, 16)
+}
+
+func parseUint(s string, base int) (Element, error) {
+	v, err := strconv.ParseUint(s, base, 64)
+	if errors.Is(err, strconv.ErrRange) {
+		return Element{}, fmt.Errorf("%w: %s", ErrNonCanonical, s)
+	}
+	if err != nil {
+		return Element{}, fmt.Errorf("%w: %q", ErrSyntax, s)
+	}
+	if v >= q {
+		return Element{}, fmt.Errorf("%w: %s", ErrNonCanonical, s)
+	}
+	return NewElement(v), nil
+}
+
+// ParseBigEndian parses the 8-byte big-endian encoding of an element, as
+// written by Element.Bytes, of a value smaller than q.
+func ParseBigEndian(b []byte) (Element, error) {
+	return parseBytes(b, binary.BigEndian)
+}
+
+// ParseLittleEndian parses the 8-byte little-endian encoding of an element,
+// as serialized by Miden VM and Winterfell, of a value smaller than q.
+func ParseLittleEndian(b []byte) (Element, error) {
+	return parseBytes(b, binary.LittleEndian)
+}
+
+func parseBytes(b []byte, order binary.ByteOrder) (Element, error) {
+	if len(b) != Bytes {
+		return Element{}, fmt.Errorf("%w: %d bytes", ErrSyntax, len(b))
+	}
+	v := order.Uint64(b)
+	if v >= q {
+		return Element{}, fmt.Errorf("%w: %d", ErrNonCanonical, v)
+	}
+	return NewElement(v), nil
+}
+
+// MarshalText implements encoding.TextMarshaler, encoding z as its canonical
+// decimal value.  Unlike String, it never prints negative numbers.
+func (z *Element) MarshalText() ([]byte, error) {
+	return strconv.AppendUint(nil, z.Uint64(), 10), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, parsing z strictly from
+// its decimal representation, or from its hexadecimal representation with a
+// 0x prefix.
+func (z *Element) UnmarshalText(text []byte) error {
+	s := string(text)
+	parse := ParseDecimal
+	if strings.HasPrefix(s, "0x") {
+		parse = ParseHex
+	}
+
+	v, err := parse(s)
+	if err != nil {
+		return err
+	}
+	*z = v
+	return nil
+}
+
+// Type Assertions
+var _ encoding.TextMarshaler = (*Element)(nil)
+var _ encoding.TextUnmarshaler = (*Element)(nil)
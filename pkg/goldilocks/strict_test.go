@@ -0,0 +1,208 @@
+package goldilocks
+
+import (
+	"bytes"
+	"encoding/binary"
+	"runtime"
+	"strconv"
+	"testing"
+
+	"github.com/leanovate/gopter"
+	ggen "github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseStrict(t *testing.T) {
+	valid := map[string]struct {
+		s    string
+		want uint64
+	}{
+		"zero":        {s: "0", want: 0},
+		"one":         {s: "1", want: 1},
+		"q-1":         {s: "18446744069414584320", want: q - 1},
+		"hex zero":    {s: "0x0", want: 0},
+		"hex":         {s: "0xff", want: 255},
+		"hex padded":  {s: "0x00000000000000ff", want: 255},
+		"hex q-1":     {s: "0xffffffff00000000", want: q - 1},
+		"hex upper":   {s: "0xFFFFFFFF00000000", want: q - 1},
+		"hex 2^32-1":  {s: "0xffffffff", want: 1<<32 - 1},
+		"decimal 2^k": {s: "4294967296", want: 1 << 32},
+	}
+	for name, tc := range valid {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			var z Element
+			assert.NoError(z.UnmarshalText([]byte(tc.s)))
+			assert.Equal(NewElement(tc.want), z)
+		})
+	}
+
+	invalid := map[string]struct {
+		s   string
+		err error
+	}{
+		"empty":        {s: "", err: ErrSyntax},
+		"q":            {s: "18446744069414584321", err: ErrNonCanonical},
+		"q+1":          {s: "18446744069414584322", err: ErrNonCanonical},
+		"2^64":         {s: "18446744073709551616", err: ErrNonCanonical},
+		"2^128":        {s: "340282366920938463463374607431768211456", err: ErrNonCanonical},
+		"hex q":        {s: "0xffffffff00000001", err: ErrNonCanonical},
+		"hex 2^64":     {s: "0x10000000000000000", err: ErrNonCanonical},
+		"negative":     {s: "-1", err: ErrSyntax},
+		"plus":         {s: "+1", err: ErrSyntax},
+		"leading zero": {s: "01", err: ErrSyntax},
+		"underscore":   {s: "1_000", err: ErrSyntax},
+		"spaces":       {s: " 1", err: ErrSyntax},
+		"hex prefix":   {s: "0x", err: ErrSyntax},
+		"hex sign":     {s: "0x-1", err: ErrSyntax},
+		"upper prefix": {s: "0X1", err: ErrSyntax},
+		"binary":       {s: "0b1", err: ErrSyntax},
+		"not a number": {s: "one", err: ErrSyntax},
+	}
+	for name, tc := range invalid {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			z := NewElement(7)
+			assert.ErrorIs(z.UnmarshalText([]byte(tc.s)), tc.err)
+			assert.Equal(NewElement(7), z, "z must be unchanged")
+		})
+	}
+
+	_, err := ParseHex("ff")
+	assert.ErrorIs(t, err, ErrSyntax)
+	_, err = ParseDecimal("0xff")
+	assert.ErrorIs(t, err, ErrSyntax)
+}
+
+func TestParseBytes(t *testing.T) {
+	assert := assert.New(t)
+
+	var be, le [8]byte
+	for _, v := range []uint64{0, 1, 1 << 32, q - 1} {
+		binary.BigEndian.PutUint64(be[:], v)
+		binary.LittleEndian.PutUint64(le[:], v)
+
+		z, err := ParseBigEndian(be[:])
+		assert.NoError(err)
+		assert.Equal(NewElement(v), z)
+		z, err = ParseLittleEndian(le[:])
+		assert.NoError(err)
+		assert.Equal(NewElement(v), z)
+	}
+
+	for _, v := range []uint64{q, q + 1, 1<<64 - 1} {
+		binary.BigEndian.PutUint64(be[:], v)
+		binary.LittleEndian.PutUint64(le[:], v)
+
+		_, err := ParseBigEndian(be[:])
+		assert.ErrorIs(err, ErrNonCanonical)
+		_, err = ParseLittleEndian(le[:])
+		assert.ErrorIs(err, ErrNonCanonical)
+
+		// SetBytes silently reduces the same encoding
+		var z Element
+		z.SetBytes(be[:])
+		assert.Equal(NewElement(v-q), z)
+	}
+
+	for _, b := range [][]byte{nil, {1}, make([]byte, 9)} {
+		_, err := ParseBigEndian(b)
+		assert.ErrorIs(err, ErrSyntax)
+		_, err = ParseLittleEndian(b)
+		assert.ErrorIs(err, ErrSyntax)
+	}
+}
+
+func TestTextRoundTrip(t *testing.T) {
+	properties := gopter.NewProperties(extensionParameters())
+
+	properties.Property("UnmarshalText(MarshalText(x)) == x", prop.ForAll(
+		func(a Element) bool {
+			text, err := a.MarshalText()
+			if err != nil {
+				return false
+			}
+			var b Element
+			return b.UnmarshalText(text) == nil && b.Equal(&a)
+		},
+		genFull(),
+	))
+
+	properties.Property("MarshalText is the canonical decimal value", prop.ForAll(
+		func(a Element) bool {
+			text, _ := a.MarshalText()
+			return string(text) == strconv.FormatUint(a.Uint64(), 10)
+		},
+		genFull(),
+	))
+
+	properties.Property("the bytes of Element are parsed strictly", prop.ForAll(
+		func(a Element) bool {
+			b := a.Bytes()
+			be, err1 := ParseBigEndian(b[:])
+			var le [Bytes]byte
+			binary.LittleEndian.PutUint64(le[:], a.Uint64())
+			e, err2 := ParseLittleEndian(le[:])
+			return err1 == nil && err2 == nil && be.Equal(&a) && e.Equal(&a)
+		},
+		genFull(),
+	))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+}
+
+func TestVectorReadFrom_Strict(t *testing.T) {
+	properties := gopter.NewProperties(extensionParameters())
+
+	properties.Property("ReadFrom reads what WriteTo writes", prop.ForAll(
+		func(v Vector) bool {
+			var buf bytes.Buffer
+			written, _ := v.WriteTo(&buf)
+			data := buf.Bytes()
+
+			var r Vector
+			read, err := r.ReadFrom(bytes.NewReader(data))
+			if err != nil || read != written || !vectorEqual(r, v) {
+				return false
+			}
+			return r.UnmarshalBinary(data) == nil && vectorEqual(r, v)
+		},
+		ggen.SliceOf(genFull()),
+	))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+
+	assert := assert.New(t)
+
+	v := Vector{NewElement(1), NewElement(2)}
+	data, err := v.MarshalBinary()
+	assert.NoError(err)
+
+	// Non-canonical element
+	bad := append([]byte(nil), data...)
+	binary.BigEndian.PutUint64(bad[4+8:], q)
+	var r Vector
+	_, err = r.ReadFrom(bytes.NewReader(bad))
+	assert.ErrorIs(err, ErrNonCanonical)
+	assert.ErrorContains(err, "element 1")
+
+	// Trailing bytes
+	assert.ErrorIs(r.UnmarshalBinary(append(data, 0)), ErrSyntax)
+
+	// A forged length fails on the missing elements without allocating them
+	forged := append([]byte(nil), data...)
+	binary.BigEndian.PutUint32(forged, 1<<32-1)
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	_, err = r.ReadFrom(bytes.NewReader(forged))
+	runtime.ReadMemStats(&after)
+	assert.Error(err)
+	assert.Less(after.TotalAlloc-before.TotalAlloc, uint64(1<<20))
+
+	// Truncated input
+	assert.Error(r.UnmarshalBinary(data[:len(data)-1]))
+	assert.Error(r.UnmarshalBinary(data[:2]))
+}
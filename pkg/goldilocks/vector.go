@@ -19,6 +19,7 @@ package goldilocks
 import (
 	"bytes"
 	"encoding/binary"
+	"fmt"
 	"io"
 	"strings"
 )
@@ -44,11 +45,17 @@ func (vector *Vector) MarshalBinary() (data []byte, err error) {
 	return buf.Bytes(), nil
 }
 
-// UnmarshalBinary implements encoding.BinaryUnmarshaler
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.  It reads the vector
+// with ReadFrom and rejects trailing bytes.
 func (vector *Vector) UnmarshalBinary(data []byte) error {
 	r := bytes.NewReader(data)
-	_, err := vector.ReadFrom(r)
-	return err
+	if _, err := vector.ReadFrom(r); err != nil {
+		return err
+	}
+	if r.Len() != 0 {
+		return fmt.Errorf("%w: %d trailing bytes", ErrSyntax, r.Len())
+	}
+	return nil
 }
 
 // WriteTo implements io.WriterTo and writes a vector of big endian encoded Element.
@@ -75,6 +82,9 @@ func (vector *Vector) WriteTo(w io.Writer) (int64, error) {
 
 // ReadFrom implements io.ReaderFrom and reads a vector of big endian encoded Element.
 // Length of the vector must be encoded as a uint32 on the first 4 bytes.
+// Non-canonical elements are rejected with ErrNonCanonical.  The encoded length
+// is not trusted: memory is allocated as elements are read, so a truncated or
+// forged input cannot cause a large allocation.
 func (vector *Vector) ReadFrom(r io.Reader) (int64, error) {
 
 	var buf [Bytes]byte
@@ -84,7 +94,7 @@ func (vector *Vector) ReadFrom(r io.Reader) (int64, error) {
 	sliceLen := binary.BigEndian.Uint32(buf[:4])
 
 	n := int64(4)
-	(*vector) = make(Vector, sliceLen)
+	v := make(Vector, 0, min(sliceLen, 1024))
 
 	for i := 0; i < int(sliceLen); i++ {
 		read, err := io.ReadFull(r, buf[:])
@@ -92,12 +102,14 @@ func (vector *Vector) ReadFrom(r io.Reader) (int64, error) {
 		if err != nil {
 			return n, err
 		}
-		(*vector)[i], err = BigEndian.Element(&buf)
+		e, err := ParseBigEndian(buf[:])
 		if err != nil {
-			return n, err
+			return n, fmt.Errorf("element %d: %w", i, err)
 		}
+		v = append(v, e)
 	}
 
+	(*vector) = v
 	return n, nil
 }
 
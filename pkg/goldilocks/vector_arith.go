@@ -0,0 +1,163 @@
+package goldilocks
+
+import (
+	"math/big"
+	"math/bits"
+)
+
+// Element-wise arithmetic on vectors.  The receiver holds the result and may
+// be one of the operands, so v.Add(v, w) adds w to v in place.  All vectors
+// must have the same length.
+//
+// The loops work on the Montgomery representation directly: sums of
+// Montgomery forms are Montgomery forms of sums, so Sum and InnerProduct
+// accumulate 128-bit values and reduce once, using 2⁶⁴ ≡ 2³² - 1 (mod q).
+
+// Add sets vector to a + b element-wise.
+func (vector *Vector) Add(a, b Vector) {
+	v := vector.checkLen("Add", a, b)
+	for i := range v {
+		r, carry := bits.Add64(a[i][0], b[i][0], 0)
+		if carry != 0 || r >= q {
+			r -= q
+		}
+		v[i][0] = r
+	}
+}
+
+// Sub sets vector to a - b element-wise.
+func (vector *Vector) Sub(a, b Vector) {
+	v := vector.checkLen("Sub", a, b)
+	for i := range v {
+		r, borrow := bits.Sub64(a[i][0], b[i][0], 0)
+		if borrow != 0 {
+			r += q
+		}
+		v[i][0] = r
+	}
+}
+
+// Mul sets vector to a · b element-wise.
+func (vector *Vector) Mul(a, b Vector) {
+	v := vector.checkLen("Mul", a, b)
+	for i := range v {
+		v[i][0] = montMul(a[i][0], b[i][0])
+	}
+}
+
+// ScalarMul sets vector to a · b, for a scalar b.
+func (vector *Vector) ScalarMul(a Vector, b *Element) {
+	v := vector.checkLen("ScalarMul", a, a)
+	s := b[0]
+	for i := range v {
+		v[i][0] = montMul(a[i][0], s)
+	}
+}
+
+// Exp sets vector to aᵏ element-wise.
+func (vector *Vector) Exp(a Vector, k *big.Int) {
+	v := vector.checkLen("Exp", a, a)
+
+	if k.Sign() == -1 {
+		a = BatchInvert(a)
+		k = new(big.Int).Neg(k)
+	}
+
+	// Square and multiply all elements for each bit of k
+	x := append(Vector(nil), a...)
+	for i := range v {
+		v[i].SetOne()
+	}
+	for b := 0; b < k.BitLen(); b++ {
+		if k.Bit(b) == 1 {
+			vector.Mul(v, x)
+		}
+		if b+1 < k.BitLen() {
+			x.Mul(x, x)
+		}
+	}
+}
+
+// Sum returns the sum of the elements of vector.
+func (vector Vector) Sum() (res Element) {
+	var hi, lo, carry uint64
+	for i := range vector {
+		lo, carry = bits.Add64(lo, vector[i][0], 0)
+		hi += carry
+	}
+	res[0] = reduce128(hi, lo)
+	return
+}
+
+// InnerProduct returns the sum of the element-wise products of vector and
+// other.
+func (vector Vector) InnerProduct(other Vector) (res Element) {
+	if len(vector) != len(other) {
+		panic("vector.InnerProduct: vectors don't have the same length")
+	}
+
+	// The products of Montgomery forms carry an extra factor R, removed by a
+	// single Montgomery reduction at the end.  top counts the 2¹²⁸ overflows.
+	var hi, lo, top, carry uint64
+	for i := range vector {
+		pHi, pLo := bits.Mul64(vector[i][0], other[i][0])
+		lo, carry = bits.Add64(lo, pLo, 0)
+		hi, carry = bits.Add64(hi, pHi, carry)
+		top += carry
+	}
+
+	// 2¹²⁸ ≡ -2³² (mod q)
+	r := reduce128(hi, lo)
+	t := reduce128(top>>32, top<<32)
+	r, borrow := bits.Sub64(r, t, 0)
+	if borrow != 0 {
+		r += q
+	}
+
+	res[0] = montMul(r, 1)
+	return
+}
+
+// Returns the length of vector after checking the lengths of the operands.
+func (vector *Vector) checkLen(op string, a, b Vector) Vector {
+	if len(a) != len(b) || len(*vector) != len(a) {
+		panic("vector." + op + ": vectors don't have the same length")
+	}
+	return *vector
+}
+
+// Returns x·y·2⁻⁶⁴ mod q, see Element.Mul.
+func montMul(x, y uint64) uint64 {
+	hi, lo := bits.Mul64(x, y)
+	if lo != 0 {
+		hi++
+	}
+	m := lo * qInvNeg
+	hi2, _ := bits.Mul64(m, q)
+	r, carry := bits.Add64(hi2, hi, 0)
+	if carry != 0 || r >= q {
+		r -= q
+	}
+	return r
+}
+
+// Returns hi·2⁶⁴ + lo mod q.
+func reduce128(hi, lo uint64) uint64 {
+	const epsilon = 1<<32 - 1 // 2⁶⁴ - q
+
+	// hi·2⁶⁴ = hiHi·2⁹⁶ + hiLo·2⁶⁴ ≡ -hiHi + hiLo·(2³² - 1)
+	hiHi, hiLo := hi>>32, hi&epsilon
+
+	t0, borrow := bits.Sub64(lo, hiHi, 0)
+	if borrow != 0 {
+		t0 -= epsilon
+	}
+	r, carry := bits.Add64(t0, hiLo*epsilon, 0)
+	if carry != 0 {
+		r += epsilon
+	}
+	if r >= q {
+		r -= q
+	}
+	return r
+}
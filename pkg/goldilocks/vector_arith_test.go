@@ -0,0 +1,244 @@
+package goldilocks
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/leanovate/gopter"
+	ggen "github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+	"github.com/stretchr/testify/assert"
+)
+
+// Two vectors of the same length
+func genVectorPair() gopter.Gen {
+	return ggen.SliceOf(gopter.CombineGens(genFull(), genFull())).Map(func(pairs [][]interface{}) [2]Vector {
+		a, b := make(Vector, len(pairs)), make(Vector, len(pairs))
+		for i, p := range pairs {
+			a[i], b[i] = p[0].(Element), p[1].(Element)
+		}
+		return [2]Vector{a, b}
+	})
+}
+
+// Returns the element-wise result of op on a and b
+func naiveVectorOp(a, b Vector, op func(z, x, y *Element) *Element) Vector {
+	r := make(Vector, len(a))
+	for i := range a {
+		op(&r[i], &a[i], &b[i])
+	}
+	return r
+}
+
+func TestVectorArithmetic(t *testing.T) {
+	properties := gopter.NewProperties(extensionParameters())
+
+	ops := map[string]struct {
+		vector  func(z *Vector, a, b Vector)
+		element func(z, x, y *Element) *Element
+	}{
+		"Add": {(*Vector).Add, (*Element).Add},
+		"Sub": {(*Vector).Sub, (*Element).Sub},
+		"Mul": {(*Vector).Mul, (*Element).Mul},
+	}
+	for name, op := range ops {
+		op := op
+		properties.Property(name+" matches Element", prop.ForAll(
+			func(v [2]Vector) bool {
+				want := naiveVectorOp(v[0], v[1], op.element)
+				got := make(Vector, len(v[0]))
+				op.vector(&got, v[0], v[1])
+				return vectorEqual(got, want)
+			},
+			genVectorPair(),
+		))
+		properties.Property(name+" in place", prop.ForAll(
+			func(v [2]Vector) bool {
+				want := naiveVectorOp(v[0], v[1], op.element)
+				got := append(Vector(nil), v[0]...)
+				op.vector(&got, got, v[1])
+				return vectorEqual(got, want)
+			},
+			genVectorPair(),
+		))
+	}
+
+	properties.Property("ScalarMul matches Element", prop.ForAll(
+		func(a Vector, s Element) bool {
+			got := append(Vector(nil), a...)
+			got.ScalarMul(got, &s)
+			for i := range a {
+				var want Element
+				if !got[i].Equal(want.Mul(&a[i], &s)) {
+					return false
+				}
+			}
+			return true
+		},
+		ggen.SliceOf(genFull()),
+		genFull(),
+	))
+
+	properties.Property("Sum matches Element", prop.ForAll(
+		func(a Vector) bool {
+			var want Element
+			for i := range a {
+				want.Add(&want, &a[i])
+			}
+			got := a.Sum()
+			return got.Equal(&want)
+		},
+		ggen.SliceOf(genFull()),
+	))
+
+	properties.Property("InnerProduct matches Element", prop.ForAll(
+		func(v [2]Vector) bool {
+			var want, t Element
+			for i := range v[0] {
+				want.Add(&want, t.Mul(&v[0][i], &v[1][i]))
+			}
+			got := v[0].InnerProduct(v[1])
+			return got.Equal(&want)
+		},
+		genVectorPair(),
+	))
+
+	properties.Property("Exp matches Element", prop.ForAll(
+		func(a Vector, k int64) bool {
+			e := big.NewInt(k)
+			got := append(Vector(nil), a...)
+			got.Exp(got, e)
+			for i := range a {
+				var want Element
+				if !got[i].Equal(want.Exp(a[i], e)) {
+					return false
+				}
+			}
+			return true
+		},
+		ggen.SliceOf(genFull()),
+		ggen.Int64(),
+	))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+}
+
+func TestVectorArithmeticLimits(t *testing.T) {
+	assert := assert.New(t)
+
+	// Large values overflow the 128-bit accumulators many times
+	const n = 1 << 12
+	a, b := make(Vector, n), make(Vector, n)
+	for i := range a {
+		a[i].SetUint64(q - 1)
+		b[i][0] = q - 1
+	}
+
+	var wantSum, wantInner, t2 Element
+	for i := range a {
+		wantSum.Add(&wantSum, &a[i])
+		wantInner.Add(&wantInner, t2.Mul(&a[i], &b[i]))
+	}
+	got := a.Sum()
+	assert.Equal(wantSum, got)
+	got = a.InnerProduct(b)
+	assert.Equal(wantInner, got)
+
+	var empty Vector
+	got = empty.Sum()
+	assert.True(got.IsZero())
+	got = empty.InnerProduct(nil)
+	assert.True(got.IsZero())
+
+	assert.Panics(func() { a.Add(a, b[1:]) })
+	assert.Panics(func() { a.InnerProduct(b[1:]) })
+	short := b[1:]
+	assert.Panics(func() { short.ScalarMul(a, &t2) })
+}
+
+func vectorEqual(a, b Vector) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !a[i].Equal(&b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func benchmarkVectors(n int) (Vector, Vector) {
+	a, b := make(Vector, n), make(Vector, n)
+	for i := range a {
+		a[i].SetRandom()
+		b[i].SetRandom()
+	}
+	return a, b
+}
+
+func BenchmarkVectorMul(b *testing.B) {
+	x, y := benchmarkVectors(1 << 16)
+	b.Run("vector", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			x.Mul(x, y)
+		}
+	})
+	b.Run("element", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for j := range x {
+				x[j].Mul(&x[j], &y[j])
+			}
+		}
+	})
+}
+
+func BenchmarkVectorAdd(b *testing.B) {
+	x, y := benchmarkVectors(1 << 16)
+	b.Run("vector", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			x.Add(x, y)
+		}
+	})
+	b.Run("element", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for j := range x {
+				x[j].Add(&x[j], &y[j])
+			}
+		}
+	})
+}
+
+func BenchmarkVectorInnerProduct(b *testing.B) {
+	x, y := benchmarkVectors(1 << 16)
+	b.Run("vector", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			x.InnerProduct(y)
+		}
+	})
+	b.Run("element", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			var r, t Element
+			for j := range x {
+				r.Add(&r, t.Mul(&x[j], &y[j]))
+			}
+		}
+	})
+}
+
+func BenchmarkVectorSum(b *testing.B) {
+	x, _ := benchmarkVectors(1 << 16)
+	b.Run("vector", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			x.Sum()
+		}
+	})
+	b.Run("element", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			var r Element
+			for j := range x {
+				r.Add(&r, &x[j])
+			}
+		}
+	})
+}
@@ -0,0 +1,35 @@
+package miden
+
+import "context"
+
+// Backend compiles, runs, proves, and verifies Miden VM programs.  Local uses
+// the Miden VM CLI installed on this machine, and other implementations, such
+// as a remote proving service client, can be swapped in by configuration.
+type Backend interface {
+	Compile(ctx context.Context, assembly Assembly) (ProgramHash, error)
+	Run(ctx context.Context, assembly Assembly, input Input) (ProgramHash, Output, error)
+	Prove(ctx context.Context, assembly Assembly, input Input) (ProgramHash, Output, Proof, error)
+	Verify(ctx context.Context, programHash ProgramHash, proof Proof, input Input, output Output) (bool, error)
+}
+
+// Local is the Backend calling the package-level functions.
+type Local struct{}
+
+func (Local) Compile(ctx context.Context, assembly Assembly) (ProgramHash, error) {
+	return Compile(ctx, assembly)
+}
+
+func (Local) Run(ctx context.Context, assembly Assembly, input Input) (ProgramHash, Output, error) {
+	return Run(ctx, assembly, input)
+}
+
+func (Local) Prove(ctx context.Context, assembly Assembly, input Input) (ProgramHash, Output, Proof, error) {
+	return Prove(ctx, assembly, input)
+}
+
+func (Local) Verify(ctx context.Context, programHash ProgramHash, proof Proof, input Input, output Output) (bool, error) {
+	return Verify(ctx, programHash, proof, input, output)
+}
+
+// Type Assertions
+var _ Backend = Local{}
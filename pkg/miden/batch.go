@@ -0,0 +1,149 @@
+package miden
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"strconv"
+	"sync"
+)
+
+// ErrVerifySkipped is returned for the items of a batch whose verification
+// was not started, or was stopped, because another item failed.
+var ErrVerifySkipped = errors.New("miden: verification skipped")
+
+// VerifyItem is a proof to be verified in a batch.
+type VerifyItem struct {
+	Proof  Proof
+	Input  Input
+	Output Output
+}
+
+// VerifyResult is the result of verifying an item of a batch.  Err is a
+// *VerifyError if OK is false.
+type VerifyResult struct {
+	OK  bool
+	Err error
+}
+
+// VerifyError describes the failure to verify an item of a batch.
+type VerifyError struct {
+	// Index of the item in the batch
+	Index int
+	Err   error
+}
+
+func (e *VerifyError) Error() string {
+	return fmt.Sprintf("miden: item %d: %v", e.Index, e.Err)
+}
+
+func (e *VerifyError) Unwrap() error {
+	return e.Err
+}
+
+// VerifyBatchOptions configures VerifyBatchWithOptions.
+type VerifyBatchOptions struct {
+	// Maximum number of proofs verified concurrently, the number of CPUs if
+	// zero
+	Workers int
+	// Stop verifying at the first failure, reporting the remaining items as
+	// ErrVerifySkipped
+	FailFast bool
+}
+
+// VerifyBatch verifies proofs of the program with programHash concurrently,
+// one Miden VM process per CPU.  VerifyBatch returns the result of every
+// item, in order, and the error of the first item which failed, if any.
+func VerifyBatch(ctx context.Context, programHash ProgramHash, items []VerifyItem) ([]VerifyResult, error) {
+	return VerifyBatchWithOptions(ctx, VerifyBatchOptions{}, programHash, items)
+}
+
+// VerifyBatchWithOptions is like VerifyBatch, but uses the specified options.
+func VerifyBatchWithOptions(ctx context.Context, opts VerifyBatchOptions, programHash ProgramHash, items []VerifyItem) ([]VerifyResult, error) {
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	results := make([]VerifyResult, len(items))
+	if len(items) == 0 {
+		return results, nil
+	}
+
+	// Every item is verified in its own subdirectory of the batch working
+	// directory
+	d, ctx := newTmpDirDriver(ctx)
+	if d.err != nil {
+		return nil, d.err
+	}
+	defer d.cleanup()
+
+	batchCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg      sync.WaitGroup
+		indices = make(chan int)
+
+		// Item whose failure cancelled the batch, -1 if none
+		mu      sync.Mutex
+		trigger = -1
+	)
+	for i := 0; i < min(workers, len(items)); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for i := range indices {
+				r := verifyBatchItem(batchCtx, d, programHash, i, items[i])
+				if !r.OK && opts.FailFast {
+					mu.Lock()
+					switch {
+					case trigger == -1:
+						trigger = i
+						cancel()
+					case ctx.Err() == nil:
+						// Killed, or not started, because item trigger failed
+						r.Err = &VerifyError{Index: i, Err: ErrVerifySkipped}
+					}
+					mu.Unlock()
+				}
+				results[i] = r
+			}
+		}()
+	}
+
+	for i := range items {
+		indices <- i
+	}
+	close(indices)
+	wg.Wait()
+
+	var first error
+	for _, r := range results {
+		if !r.OK && !errors.Is(r.Err, ErrVerifySkipped) {
+			first = r.Err
+			break
+		}
+	}
+	return results, first
+}
+
+func verifyBatchItem(ctx context.Context, batch *driver, programHash ProgramHash, i int, item VerifyItem) VerifyResult {
+	if err := ctx.Err(); err != nil {
+		return VerifyResult{Err: &VerifyError{Index: i, Err: err}}
+	}
+
+	d := batch.subdriver(strconv.Itoa(i))
+	defer d.cleanup()
+
+	ok, err := d.verify(ctx, programHash, item.Proof, item.Input, item.Output)
+	if err == nil && !ok {
+		err = errors.New("verification failed")
+	}
+	if err != nil {
+		return VerifyResult{Err: &VerifyError{Index: i, Err: err}}
+	}
+	return VerifyResult{OK: true}
+}
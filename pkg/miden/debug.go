@@ -0,0 +1,405 @@
+package miden
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	field "github.com/qredo/verifiable-oracles/pkg/goldilocks"
+)
+
+// DefaultMaxCycles is the number of cycles traced when no limit is specified.
+const DefaultMaxCycles = 1 << 16
+
+// MemoryEntry is a word stored in Miden VM memory.
+type MemoryEntry struct {
+	Addr uint64
+	Word [4]field.Element
+}
+
+// Step is the state of Miden VM at a single clock cycle, as reported by the
+// Miden VM debugger.
+type Step struct {
+	Clk uint32
+	// VM operation executed in this cycle, empty for the initial state
+	Op string
+	// Assembly instruction which the operation is part of, empty if unknown
+	AsmOp string
+	// Procedure context of the assembly instruction
+	Context string
+	// Number of cycles the assembly instruction takes
+	Cost int
+	// Index of this cycle within the assembly instruction, starting from 1
+	Cycle  int
+	Fmp    field.Element
+	Stack  field.Vector
+	Memory []MemoryEntry
+}
+
+// ExecutionTrace is the trace of a Miden VM program execution.
+type ExecutionTrace struct {
+	Hash  ProgramHash
+	Steps []Step
+	// Execution error reported by the debugger, if any
+	Err error
+	// Whether the trace was cut at the maximum number of cycles
+	Truncated bool
+}
+
+// Cycles returns the number of cycles executed by the program, which is a
+// lower bound if the trace was truncated.
+func (t *ExecutionTrace) Cycles() int {
+	if len(t.Steps) == 0 {
+		return 0
+	}
+	return int(t.Steps[len(t.Steps)-1].Clk)
+}
+
+// ExecutionSummary summarises a Miden VM program execution, as reported by
+// `miden run`.
+//
+// There are no total cycles or padding fields: `miden run` only prints the
+// length of the execution trace once padded, which covers the chiplet and
+// range checker segments as well as the executed cycles, so neither can be
+// derived from it.  Use Trace, whose ExecutionTrace.Cycles counts the executed
+// cycles by stepping through the program in the debugger.
+type ExecutionSummary struct {
+	// Length of the execution trace, padded to a power of two
+	TraceLength int
+	Duration    time.Duration
+}
+
+// Trace compiles a Miden VM program assembly in debug mode and records its
+// state at every cycle, up to maxCycles cycles (DefaultMaxCycles if zero).
+func Trace(ctx context.Context, assembly Assembly, input Input, maxCycles int) (*ExecutionTrace, error) {
+	d, ctx := newTmpDirDriver(ctx)
+	defer d.cleanup()
+
+	if err := d.setAssembly(assembly); err != nil {
+		return nil, err
+	}
+	if err := d.setInput(input); err != nil {
+		return nil, err
+	}
+
+	return TraceFile(ctx, d.assemblyPath(), d.inputPath(), maxCycles)
+}
+
+// TraceFile is like Trace, but reads the program assembly and its input from
+// files.
+func TraceFile(ctx context.Context, assemblyPath string, inputPath string, maxCycles int, libraryPaths ...string) (*ExecutionTrace, error) {
+	if maxCycles <= 0 {
+		maxCycles = DefaultMaxCycles
+	}
+
+	s, err := startDebugger(ctx, assemblyPath, inputPath, libraryPaths)
+	if err != nil {
+		return nil, err
+	}
+	defer s.close()
+
+	trace := &ExecutionTrace{}
+	if trace.Hash, err = s.hash(); err != nil {
+		return nil, err
+	}
+
+	// Print the initial state, then step through the program
+	command := "print"
+	for {
+		step, done, err := s.execute(command)
+		if err != nil {
+			return nil, err
+		}
+
+		// Stepping past the end of the program reports the last state again
+		if n := len(trace.Steps); n == 0 || trace.Steps[n-1].Clk != step.Clk {
+			if n >= maxCycles {
+				trace.Truncated = true
+				break
+			}
+			trace.Steps = append(trace.Steps, step)
+		}
+
+		if done != nil {
+			if done != errExecutionComplete {
+				trace.Err = done
+			}
+			break
+		}
+		command = "next"
+	}
+
+	return trace, s.quit()
+}
+
+// RunWithSummary is like Run, but also returns the execution summary
+// reported by Miden VM.
+func RunWithSummary(ctx context.Context, assembly Assembly, input Input) (hash ProgramHash, output Output, summary ExecutionSummary, err error) {
+	d, ctx := newTmpDirDriver(ctx)
+	defer d.cleanup()
+
+	if err = d.setAssembly(assembly); err != nil {
+		return
+	}
+
+	return d.runWithSummary(ctx, d.assemblyPath(), input)
+}
+
+// RunFileWithSummary is like RunFile, but also returns the execution summary
+// reported by Miden VM.  The summary is zero if Miden VM did not print it in
+// the expected format, which does not fail the run.
+func RunFileWithSummary(ctx context.Context, assemblyPath string, inputPath string, outputPath string, libraryPaths ...string) (hash ProgramHash, summary ExecutionSummary, err error) {
+	args := libraryArgs([]string{"run", "--assembly", assemblyPath, "--input", inputPath, "--output", outputPath}, libraryPaths)
+	out, err := runCommand(ctx, exec.CommandContext(ctx, "miden", args...))
+	if err != nil {
+		return
+	}
+
+	outLines := strings.Split(string(out), "\n")
+	if hash, err = extractHashRun(outLines); err != nil {
+		return
+	}
+	if steps, duration, err := extractRunSteps(outLines); err == nil {
+		summary = ExecutionSummary{TraceLength: steps, Duration: duration}
+	}
+	return
+}
+
+// Parses "Executing program with hash ...... done (N steps in M ms)"
+func extractRunSteps(outLines []string) (int, time.Duration, error) {
+	line, ok := extractLine(outLines, "Executing program with hash ", ")")
+	if !ok {
+		return 0, 0, errors.New("miden: run summary line not found")
+	}
+
+	var steps, ms int
+	i := strings.LastIndex(line, "done (")
+	if i == -1 {
+		return 0, 0, errors.New("miden: run summary line not found")
+	}
+	if _, err := fmt.Sscanf(line[i:], "done (%d steps in %d ms", &steps, &ms); err != nil {
+		return 0, 0, fmt.Errorf("miden: invalid run summary line: %w", err)
+	}
+
+	return steps, time.Duration(ms) * time.Millisecond, nil
+}
+
+var errExecutionComplete = errors.New("miden: execution complete")
+
+// A scripted session of the interactive Miden VM debugger, recorded in the
+// command log of ctx when the debugger exits
+type debugSession struct {
+	ctx    context.Context
+	cmd    *exec.Cmd
+	start  time.Time
+	stdin  io.WriteCloser
+	stdout *bufio.Scanner
+	stderr bytes.Buffer
+}
+
+func startDebugger(ctx context.Context, assemblyPath string, inputPath string, libraryPaths []string) (*debugSession, error) {
+	args := libraryArgs([]string{"debug", "--assembly", assemblyPath, "--input", inputPath}, libraryPaths)
+	s := &debugSession{ctx: ctx, cmd: exec.CommandContext(ctx, "miden", args...)}
+	s.cmd.Stderr = &s.stderr
+
+	stdin, err := s.cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := s.cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	s.start = time.Now()
+	if err = s.cmd.Start(); err != nil {
+		recordCommand(ctx, s.cmd, s.start, err)
+		return nil, err
+	}
+
+	s.stdin = stdin
+	s.stdout = bufio.NewScanner(stdout)
+	s.stdout.Buffer(nil, 1<<24)
+	return s, nil
+}
+
+// Reads the program hash printed when the debugger starts.
+func (s *debugSession) hash() (ProgramHash, error) {
+	const prefix = "Debugging program with hash "
+
+	for s.stdout.Scan() {
+		line := s.stdout.Text()
+		if strings.HasPrefix(line, prefix) {
+			return extractHashDebug([]string{line})
+		}
+	}
+	return nil, s.fail()
+}
+
+// Executes a debugger command printing the VM state, and returns the state.
+// done is errExecutionComplete if the program finished, or the execution
+// error reported by the debugger.
+func (s *debugSession) execute(command string) (step Step, done error, err error) {
+	if _, err = io.WriteString(s.stdin, command+"\n"); err != nil {
+		return
+	}
+
+	for s.stdout.Scan() {
+		line := s.stdout.Text()
+
+		switch {
+		case strings.Contains(line, "Program execution complete."):
+			done = errExecutionComplete
+		case strings.Contains(line, "Execution error: "):
+			_, msg, _ := strings.Cut(line, "Execution error: ")
+			done = fmt.Errorf("miden: execution error: %s", msg)
+		case strings.Contains(line, "clk="):
+			step, err = parseStep(line[strings.Index(line, "clk="):])
+			return
+		}
+	}
+
+	err = s.fail()
+	return
+}
+
+func (s *debugSession) quit() error {
+	if _, err := io.WriteString(s.stdin, "quit\n"); err != nil {
+		return err
+	}
+	s.stdin.Close()
+	return s.wait()
+}
+
+// Returns the reason of the debugger output ending unexpectedly.
+func (s *debugSession) fail() error {
+	if err := s.stdout.Err(); err != nil {
+		return err
+	}
+	s.stdin.Close()
+	if err := s.wait(); err != nil {
+		return err
+	}
+	return errors.New("miden: unexpected end of debugger output")
+}
+
+func (s *debugSession) close() {
+	s.stdin.Close()
+	if s.cmd.ProcessState == nil {
+		s.cmd.Process.Kill()
+		s.wait()
+	}
+}
+
+// Waits for the debugger to exit, and records the session.
+func (s *debugSession) wait() error {
+	err := s.cmd.Wait()
+	var exitError *exec.ExitError
+	if errors.As(err, &exitError) {
+		exitError.Stderr = s.stderr.Bytes()
+	}
+	recordCommand(s.ctx, s.cmd, s.start, err)
+	return err
+}
+
+func extractHashDebug(outLines []string) ([]byte, error) {
+	output, ok := extractLine(outLines, "Debugging program with hash ", "...")
+	if !ok {
+		return nil, errors.New("miden: hash line not found")
+	}
+	return hex.DecodeString(output)
+}
+
+// Parses the VM state printed by the debugger:
+//
+//	clk=1, op=span, context=#main, operation=push.1, cost=1, cycles=1, fmp=1073741824, stack=[...], memory=[(0, [1, 2, 3, 4])]
+//
+// Both the op and the assembly instruction (context, operation, cost, and
+// cycles) are optional.
+func parseStep(line string) (step Step, err error) {
+	invalid := func(what string) error {
+		return fmt.Errorf("miden: invalid %s in debugger state %q", what, line)
+	}
+
+	head, rest, ok := strings.Cut(line, ", fmp=")
+	if !ok {
+		return step, invalid("fmp")
+	}
+	fmp, rest, ok := strings.Cut(rest, ", stack=[")
+	if !ok {
+		return step, invalid("stack")
+	}
+	stack, memory, ok := strings.Cut(rest, "], memory=")
+	if !ok {
+		return step, invalid("memory")
+	}
+
+	fields := strings.Split(head, ", ")
+	clk, err := strconv.ParseUint(strings.TrimPrefix(fields[0], "clk="), 10, 32)
+	if err != nil {
+		return step, invalid("clk")
+	}
+	step.Clk = uint32(clk)
+
+	for _, f := range fields[1:] {
+		key, value, _ := strings.Cut(f, "=")
+		switch key {
+		case "op":
+			step.Op = value
+		case "context":
+			step.Context = value
+		case "operation":
+			step.AsmOp = value
+		case "cost":
+			step.Cost, err = strconv.Atoi(value)
+		case "cycles":
+			step.Cycle, err = strconv.Atoi(value)
+		}
+		if err != nil {
+			return step, invalid(key)
+		}
+	}
+
+	if _, err = step.Fmp.SetString(fmp); err != nil {
+		return step, invalid("fmp")
+	}
+	if step.Stack, err = parseNumbers(stack); err != nil {
+		return step, invalid("stack")
+	}
+
+	words, err := parseNumbers(memory)
+	if err != nil || len(words)%5 != 0 {
+		return step, invalid("memory")
+	}
+	for i := 0; i < len(words); i += 5 {
+		entry := MemoryEntry{Addr: words[i].Uint64()}
+		copy(entry.Word[:], words[i+1:i+5])
+		step.Memory = append(step.Memory, entry)
+	}
+
+	return step, nil
+}
+
+// Parses all decimal numbers in s, ignoring brackets and separators.
+func parseNumbers(s string) (field.Vector, error) {
+	tokens := strings.FieldsFunc(s, func(r rune) bool {
+		return strings.ContainsRune("[](), ", r)
+	})
+
+	v := make(field.Vector, len(tokens))
+	for i, t := range tokens {
+		n, err := strconv.ParseUint(t, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		v[i].SetUint64(n)
+	}
+	return v, nil
+}
@@ -0,0 +1,124 @@
+package miden
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	field "github.com/qredo/verifiable-oracles/pkg/goldilocks"
+)
+
+func vector(v ...uint64) field.Vector {
+	r := make(field.Vector, len(v))
+	for i := range v {
+		r[i].SetUint64(v[i])
+	}
+	return r
+}
+
+var _parseStepTable = map[string]struct {
+	line string
+	want Step
+}{
+	"initial state": {
+		line: "clk=0, fmp=1073741824, stack=[1, 2, 0, 0], memory=[]",
+		want: Step{
+			Fmp:   field.NewElement(1073741824),
+			Stack: vector(1, 2, 0, 0),
+		},
+	},
+	"operation": {
+		line: "clk=1, op=span, fmp=1073741824, stack=[0], memory=[]",
+		want: Step{
+			Clk:   1,
+			Op:    "span",
+			Fmp:   field.NewElement(1073741824),
+			Stack: vector(0),
+		},
+	},
+	"assembly instruction": {
+		line: "clk=2, op=push(1), context=#main, operation=push.1, cost=1, cycles=1, fmp=1073741824, stack=[1, 0], memory=[]",
+		want: Step{
+			Clk:     2,
+			Op:      "push(1)",
+			AsmOp:   "push.1",
+			Context: "#main",
+			Cost:    1,
+			Cycle:   1,
+			Fmp:     field.NewElement(1073741824),
+			Stack:   vector(1, 0),
+		},
+	},
+	"memory": {
+		line: "clk=7, op=mstorew, context=#main, operation=mem_storew.0, cost=2, cycles=2, fmp=1073741824, stack=[0], memory=[(0, [1, 2, 3, 4]), (5, [0, 0, 0, 18446744069414584320])]",
+		want: Step{
+			Clk:     7,
+			Op:      "mstorew",
+			AsmOp:   "mem_storew.0",
+			Context: "#main",
+			Cost:    2,
+			Cycle:   2,
+			Fmp:     field.NewElement(1073741824),
+			Stack:   vector(0),
+			Memory: []MemoryEntry{
+				{Addr: 0, Word: [4]field.Element(vector(1, 2, 3, 4))},
+				{Addr: 5, Word: [4]field.Element(vector(0, 0, 0, 18446744069414584320))},
+			},
+		},
+	},
+}
+
+var _parseStepErrorTable = map[string]string{
+	"empty":          "",
+	"missing stack":  "clk=0, fmp=1073741824, memory=[]",
+	"invalid clk":    "clk=x, fmp=1073741824, stack=[], memory=[]",
+	"invalid cost":   "clk=1, op=noop, context=#main, operation=nop, cost=x, cycles=1, fmp=0, stack=[], memory=[]",
+	"invalid stack":  "clk=0, fmp=1073741824, stack=[a], memory=[]",
+	"partial memory": "clk=0, fmp=1073741824, stack=[], memory=[(0, [1, 2, 3])]",
+}
+
+func TestParseStep(t *testing.T) {
+	for name, tc := range _parseStepTable {
+		t.Run(name, func(t *testing.T) {
+			step, err := parseStep(tc.line)
+			assert.Nil(t, err)
+			assert.Equal(t, tc.want, step)
+		})
+	}
+}
+
+func TestParseStep_Error(t *testing.T) {
+	for name, line := range _parseStepErrorTable {
+		t.Run(name, func(t *testing.T) {
+			_, err := parseStep(line)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestExtractRunSteps(t *testing.T) {
+	assert := assert.New(t)
+
+	lines := []string{
+		"Reading program file `assembly.masm`",
+		"Compiling program... done (0 ms)",
+		"Executing program with hash f0db3924f3e2d677a51924b09ecef8a12416a6ceb09fadd39785bb4f685cab66... done (1024 steps in 3 ms)",
+	}
+
+	steps, duration, err := extractRunSteps(lines)
+	assert.Nil(err)
+	assert.Equal(1024, steps)
+	assert.Equal(3*time.Millisecond, duration)
+
+	_, _, err = extractRunSteps(lines[:2])
+	assert.Error(err)
+}
+
+func TestExtractHashDebug(t *testing.T) {
+	lines := []string{"Debugging program with hash 0a0b... "}
+
+	hash, err := extractHashDebug(lines)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{0x0a, 0x0b}, hash)
+}
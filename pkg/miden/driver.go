@@ -1,21 +1,52 @@
 package miden
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"os"
 	"path"
+
+	"github.com/qredo/verifiable-oracles/pkg/miden/masl"
 )
 
 // Miden driver
 type driver struct {
-	wd  string
-	err error
+	wd   string
+	err  error
+	keep KeepPolicy
+	log  *commandLog
+	// Set on drivers working in a subdirectory of another driver, which
+	// decides whether to keep it
+	nested bool
 }
 
-func newTmpDirDriver() *driver {
-	wd, err := os.MkdirTemp("", "miden*")
-	return &driver{wd: wd, err: err}
+// newTmpDirDriver creates a working directory as configured by
+// SetWorkDirConfig.  The returned context records the Miden VM commands run
+// with it for the manifest of kept directories.
+func newTmpDirDriver(ctx context.Context) (*driver, context.Context) {
+	config := GetWorkDirConfig()
+
+	d := &driver{keep: config.Keep}
+	if config.Root != "" {
+		d.err = os.MkdirAll(config.Root, 0755)
+	}
+	if d.err == nil {
+		d.wd, d.err = os.MkdirTemp(config.Root, "miden*")
+	}
+
+	d.log, ctx = withCommandLog(ctx, d.wd)
+	return d, ctx
+}
+
+// subdriver returns a driver working in the subdirectory name of the working
+// directory, recording its commands in the same manifest.
+func (d *driver) subdriver(name string) *driver {
+	sub := &driver{wd: path.Join(d.wd, name), err: d.err, keep: d.keep, log: d.log, nested: true}
+	if sub.err == nil {
+		sub.err = os.Mkdir(sub.wd, 0755)
+	}
+	return sub
 }
 
 // paths
@@ -43,6 +74,60 @@ func (d *driver) setAssembly(assembly Assembly) error {
 	return d.err
 }
 
+func (d *driver) libraryPath() string {
+	return path.Join(d.wd, "lib")
+}
+
+// setModules materialises the module tree under the library directory, e.g.
+// module oracle::rpo is written to lib/oracle/rpo.masm.
+func (d *driver) setModules(modules []Module) error {
+	if d.err != nil {
+		return d.err
+	}
+
+	for _, m := range modules {
+		name := path.Join(d.libraryPath(), path.Join(m.components()...)+".masm")
+		if d.err = os.MkdirAll(path.Dir(name), 0755); d.err != nil {
+			return d.err
+		}
+		if d.err = os.WriteFile(name, m.Assembly, 0644); d.err != nil {
+			return d.err
+		}
+	}
+	return nil
+}
+
+// setProject writes the project into the working directory and encodes the
+// module tree of each namespace into a .masl library, e.g. lib/oracle.masl.
+// setProject returns the paths of all libraries of the project.
+func (d *driver) setProject(project Project) ([]string, error) {
+	if d.err = project.Validate(); d.err != nil {
+		return nil, d.err
+	}
+	if err := d.setAssembly(project.Main); err != nil {
+		return nil, err
+	}
+	if err := d.setModules(project.Modules); err != nil {
+		return nil, err
+	}
+
+	var libraryPaths []string
+	for _, namespace := range project.Namespaces() {
+		var lib *masl.Library
+		if lib, d.err = masl.ReadDir(path.Join(d.libraryPath(), namespace), namespace); d.err != nil {
+			return nil, d.err
+		}
+
+		var libraryPath string
+		if libraryPath, d.err = lib.WriteToDir(d.libraryPath()); d.err != nil {
+			return nil, d.err
+		}
+		libraryPaths = append(libraryPaths, libraryPath)
+	}
+
+	return append(libraryPaths, project.Libraries...), nil
+}
+
 func (d *driver) setInput(input Input) error {
 	if d.err != nil {
 		return d.err
@@ -111,9 +196,76 @@ func (d *driver) setProof(proof Proof) error {
 	return d.err
 }
 
+// run executes the program at assemblyPath on input inside the working
+// directory.
+func (d *driver) run(ctx context.Context, assemblyPath string, input Input, libraryPaths ...string) (hash ProgramHash, output Output, err error) {
+	hash, output, _, err = d.runWithSummary(ctx, assemblyPath, input, libraryPaths...)
+	return
+}
+
+func (d *driver) runWithSummary(ctx context.Context, assemblyPath string, input Input, libraryPaths ...string) (hash ProgramHash, output Output, summary ExecutionSummary, err error) {
+	if err = d.setInput(input); err != nil {
+		return
+	}
+	if hash, summary, err = RunFileWithSummary(ctx, assemblyPath, d.inputPath(), d.outputPath(), libraryPaths...); err != nil {
+		return
+	}
+
+	output, err = d.output()
+	return
+}
+
+// prove executes and proves the program at assemblyPath on input inside the
+// working directory.
+func (d *driver) prove(ctx context.Context, opts ProofOptions, assemblyPath string, input Input, libraryPaths ...string) (hash ProgramHash, output Output, proof Proof, err error) {
+	hash, output, proof, _, err = d.proveWithProgress(ctx, opts, nil, assemblyPath, input, libraryPaths...)
+	return
+}
+
+func (d *driver) proveWithProgress(ctx context.Context, opts ProofOptions, progress ProgressFunc, assemblyPath string, input Input, libraryPaths ...string) (hash ProgramHash, output Output, proof Proof, stats ProveStats, err error) {
+	if err = d.setInput(input); err != nil {
+		return
+	}
+	if hash, stats, err = ProveFileWithProgress(ctx, opts, progress, assemblyPath, d.inputPath(), d.outputPath(), d.proofPath(), libraryPaths...); err != nil {
+		return
+	}
+	if output, err = d.output(); err != nil {
+		return
+	}
+
+	proof, err = d.proof()
+	return
+}
+
+// verify checks the proof of the program with programHash inside the working
+// directory.
+func (d *driver) verify(ctx context.Context, programHash ProgramHash, proof Proof, input Input, output Output) (bool, error) {
+	if err := d.setInput(input); err != nil {
+		return false, err
+	}
+	if err := d.setOutput(output); err != nil {
+		return false, err
+	}
+	if err := d.setProof(proof); err != nil {
+		return false, err
+	}
+
+	return VerifyFile(ctx, programHash, d.inputPath(), d.outputPath(), d.proofPath())
+}
+
+// cleanup removes the working directory, unless it is kept according to the
+// KeepPolicy, in which case the manifest is written to it.
 func (d *driver) cleanup() error {
 	if d.wd == "" {
 		return errors.New("trying to remove an empty wd")
 	}
+
+	switch {
+	case d.keep == KeepNever:
+	case d.nested:
+		return nil
+	case d.keep == KeepAlways || d.err != nil || d.log.hasFailed():
+		return d.writeManifest()
+	}
 	return os.RemoveAll(d.wd)
 }
@@ -0,0 +1,635 @@
+package miden_test
+
+import (
+	"context"
+	"encoding/hex"
+	"os"
+	"os/exec"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	field "github.com/qredo/verifiable-oracles/pkg/goldilocks"
+	"github.com/qredo/verifiable-oracles/pkg/miden"
+	"github.com/qredo/verifiable-oracles/pkg/miden/midentest"
+)
+
+func TestMain(m *testing.M) {
+	midentest.Main(m)
+}
+
+var _fakeAddAssembly = []byte("begin\n    add\nend\n")
+
+func fakeHash() miden.ProgramHash {
+	hash, _ := hex.DecodeString("c1e5d8f3d6b2a0c1e5d8f3d6b2a0c1e5d8f3d6b2a0c1e5d8f3d6b2a0c1e5d8f3")
+	return hash
+}
+
+func fakeInput(a, b uint64) miden.Input {
+	var x, y field.Element
+	x.SetUint64(a)
+	y.SetUint64(b)
+	return miden.Input{OperandStack: field.Vector{x, y}}
+}
+
+func fakeOutput(sum uint64) miden.Output {
+	var s field.Element
+	s.SetUint64(sum)
+	return miden.Output{Stack: out(s), OverflowAddrs: field.Vector{}}
+}
+
+func installFakeMiden(t *testing.T, fail map[string]string) {
+	t.Helper()
+
+	midentest.Install(t, midentest.Config{
+		Programs: []midentest.Program{{
+			Assembly: _fakeAddAssembly,
+			Hash:     fakeHash(),
+			Runs: []midentest.Run{
+				{Input: fakeInput(1, 2), Output: fakeOutput(3), Steps: 16},
+				{Input: fakeInput(0, 0), Err: "assertion failed at clock cycle 1"},
+			},
+		}},
+		Fail: fail,
+	})
+}
+
+func TestFakeMiden(t *testing.T) {
+	assert := assert.New(t)
+	installFakeMiden(t, nil)
+	ctx := context.Background()
+
+	version, err := miden.Version(ctx)
+	assert.Nil(err)
+	assert.Equal(midentest.Version, version)
+
+	hash, err := miden.Compile(ctx, _fakeAddAssembly)
+	assert.Nil(err)
+	assert.Equal(fakeHash(), hash)
+
+	hash, output, err := miden.Run(ctx, _fakeAddAssembly, fakeInput(1, 2))
+	assert.Nil(err)
+	assert.Equal(fakeHash(), hash)
+	assert.Equal(fakeOutput(3), output)
+
+	hash, output, summary, err := miden.RunWithSummary(ctx, _fakeAddAssembly, fakeInput(1, 2))
+	assert.Nil(err)
+	assert.Equal(fakeHash(), hash)
+	assert.Equal(fakeOutput(3), output)
+	assert.Equal(16, summary.TraceLength)
+
+	hash, output, proof, err := miden.Prove(ctx, _fakeAddAssembly, fakeInput(1, 2))
+	if !assert.Nil(err) {
+		return
+	}
+	assert.Equal(fakeHash(), hash)
+	assert.Equal(fakeOutput(3), output)
+
+	ok, err := miden.Verify(ctx, hash, proof, fakeInput(1, 2), output)
+	assert.True(ok)
+	assert.Nil(err)
+
+	ok, err = miden.Verify(ctx, hash, proof, fakeInput(1, 2), fakeOutput(4))
+	assert.False(ok)
+	assert.Nil(err)
+}
+
+func TestFakeMiden_Error(t *testing.T) {
+	assert := assert.New(t)
+	installFakeMiden(t, map[string]string{"verify": "verifier unavailable"})
+	ctx := context.Background()
+
+	_, err := miden.Compile(ctx, []byte("begin\n    mul\nend\n"))
+	assert.Error(err)
+
+	_, _, err = miden.Run(ctx, _fakeAddAssembly, fakeInput(2, 2))
+	assert.Error(err)
+
+	_, _, err = miden.Run(ctx, _fakeAddAssembly, fakeInput(0, 0))
+	var exitError *exec.ExitError
+	if assert.ErrorAs(err, &exitError) {
+		assert.Contains(string(exitError.Stderr), "assertion failed")
+	}
+
+	hash, output, proof, err := miden.Prove(ctx, _fakeAddAssembly, fakeInput(1, 2))
+	assert.Nil(err)
+	ok, err := miden.Verify(ctx, hash, proof, fakeInput(1, 2), output)
+	assert.False(ok)
+	assert.Error(err)
+}
+
+func TestFakeMidenProgram(t *testing.T) {
+	assert := assert.New(t)
+	installFakeMiden(t, nil)
+	ctx := context.Background()
+
+	program, err := newTestProgramCache(t).Compile(ctx, _fakeAddAssembly)
+	if !assert.Nil(err) {
+		return
+	}
+
+	opts := miden.ProofOptions{Security: miden.Security128, Recursive: true}
+	output, proof, err := program.ProveWithOptions(ctx, fakeInput(1, 2), opts)
+	assert.Nil(err)
+	assert.Equal(fakeOutput(3), output)
+
+	expected, err := midentest.FakeProof(fakeHash(), fakeInput(1, 2), output, opts)
+	assert.Nil(err)
+	assert.Equal(expected, proof)
+
+	ok, err := program.Verify(ctx, proof, fakeInput(1, 2), output)
+	assert.True(ok)
+	assert.Nil(err)
+}
+
+func TestFakeMidenProgramCacheClear(t *testing.T) {
+	assert := assert.New(t)
+	installFakeMiden(t, nil)
+	ctx := context.Background()
+
+	c := newTestProgramCache(t)
+	program, err := c.Compile(ctx, _fakeAddAssembly)
+	if !assert.Nil(err) {
+		return
+	}
+	assert.Nil(c.Clear())
+	assert.NoFileExists(program.AssemblyPath())
+
+	_, err = program.Run(ctx, fakeInput(1, 2))
+	assert.ErrorIs(err, miden.ErrProgramCleared)
+	_, _, err = program.Prove(ctx, fakeInput(1, 2))
+	assert.ErrorIs(err, miden.ErrProgramCleared)
+
+	recompiled, err := c.Compile(ctx, _fakeAddAssembly)
+	if !assert.Nil(err) {
+		return
+	}
+	assert.NotSame(program, recompiled)
+	output, err := recompiled.Run(ctx, fakeInput(1, 2))
+	assert.Nil(err)
+	assert.Equal(fakeOutput(3), output)
+}
+
+func TestFakeMidenProgramCacheCompile_Failed(t *testing.T) {
+	assert := assert.New(t)
+	installFakeMiden(t, nil)
+
+	c := newTestProgramCache(t)
+	_, err := c.Compile(context.Background(), []byte("begin\n    mul\nend\n"))
+	assert.Error(err)
+
+	entries, err := os.ReadDir(c.Dir())
+	assert.Nil(err)
+	assert.Empty(entries)
+}
+
+func TestFakeMidenProgramCacheCompile_RacingClear(t *testing.T) {
+	assert := assert.New(t)
+	midentest.Install(t, midentest.Config{
+		Programs: []midentest.Program{{
+			Assembly: _fakeAddAssembly,
+			Hash:     fakeHash(),
+			Runs:     []midentest.Run{{Input: fakeInput(1, 2), Output: fakeOutput(3)}},
+			Delay:    200 * time.Millisecond,
+		}},
+	})
+	ctx := context.Background()
+
+	c := newTestProgramCache(t)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		time.Sleep(50 * time.Millisecond)
+		assert.Nil(c.Clear())
+	}()
+
+	// The compilation cleared under it is retried
+	program, err := c.Compile(ctx, _fakeAddAssembly)
+	<-done
+	if !assert.Nil(err) {
+		return
+	}
+	output, err := program.Run(ctx, fakeInput(1, 2))
+	assert.Nil(err)
+	assert.Equal(fakeOutput(3), output)
+}
+
+func TestFakeMidenProject(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	main, err := os.ReadFile("testdata/project/main.masm")
+	if !assert.Nil(err) {
+		return
+	}
+	input := miden.Input{OperandStack: field.Vector{field.One()}}
+	output := miden.Output{Stack: out(), OverflowAddrs: field.Vector{}}
+	midentest.Install(t, midentest.Config{
+		Programs: []midentest.Program{{
+			Assembly: main,
+			Hash:     fakeHash(),
+			Runs:     []midentest.Run{{Input: input, Output: output}},
+		}},
+	})
+
+	project := readProject(t)
+	hash, err := miden.CompileProject(ctx, project)
+	assert.Nil(err)
+	assert.Equal(fakeHash(), hash)
+
+	_, runOutput, err := miden.RunProject(ctx, project, input)
+	assert.Nil(err)
+	assert.Equal(output, runOutput)
+
+	hash, proveOutput, proof, err := miden.ProveProject(ctx, project, input)
+	if assert.Nil(err) {
+		ok, err := miden.Verify(ctx, hash, proof, input, proveOutput)
+		assert.True(ok)
+		assert.Nil(err)
+	}
+}
+
+func TestFakeMidenDefaultProofCache(t *testing.T) {
+	assert := assert.New(t)
+	installFakeMiden(t, nil)
+	ctx := context.Background()
+
+	c, err := miden.NewProofCache(miden.ProofCacheConfig{Dir: t.TempDir()})
+	if !assert.Nil(err) {
+		return
+	}
+	miden.SetDefaultProofCache(c)
+	t.Cleanup(func() { miden.SetDefaultProofCache(nil) })
+
+	// The program hash is taken from the prover output, without compiling
+	installFakeMiden(t, map[string]string{"compile": "compiler unavailable"})
+	_, output, proof, stats, err := miden.ProveWithProgress(ctx, _fakeAddAssembly, fakeInput(1, 2), miden.ProofOptions{}, nil)
+	if !assert.Nil(err) {
+		return
+	}
+	assert.False(stats.Cached)
+	entries, _, err := c.Size()
+	assert.Nil(err)
+	assert.Equal(1, entries)
+
+	// The cached proof is returned even though proving now fails
+	installFakeMiden(t, map[string]string{"compile": "compiler unavailable", "prove": "prover unavailable"})
+	hash, cachedOutput, cachedProof, stats, err := miden.ProveWithProgress(ctx, _fakeAddAssembly, fakeInput(1, 2), miden.ProofOptions{}, nil)
+	assert.Nil(err)
+	assert.True(stats.Cached)
+	assert.Equal(fakeHash(), hash)
+	assert.Equal(output, cachedOutput)
+	assert.Equal(proof, cachedProof)
+
+	_, _, _, err = miden.Prove(ctx, _fakeAddAssembly, fakeInput(1, 2))
+	assert.Nil(err)
+
+	// Other options are not cached
+	_, _, _, _, err = miden.ProveWithProgress(ctx, _fakeAddAssembly, fakeInput(1, 2), miden.ProofOptions{Recursive: true}, nil)
+	assert.Error(err)
+
+	miden.SetDefaultProofCache(nil)
+	_, _, _, err = miden.Prove(ctx, _fakeAddAssembly, fakeInput(1, 2))
+	assert.Error(err)
+}
+
+func TestFakeMidenProofCacheGet_Invalid(t *testing.T) {
+	assert := assert.New(t)
+	installFakeMiden(t, nil)
+	ctx := context.Background()
+
+	c, err := miden.NewProofCache(miden.ProofCacheConfig{Dir: t.TempDir()})
+	if !assert.Nil(err) {
+		return
+	}
+	err = c.Put(fakeHash(), fakeInput(1, 2), miden.ProofOptions{}, fakeOutput(3), miden.Proof{1, 2, 3})
+	assert.Nil(err)
+
+	// Entries are kept if Miden VM fails to verify them
+	installFakeMiden(t, map[string]string{"verify": "verifier unavailable"})
+	_, _, ok := c.Get(ctx, fakeHash(), fakeInput(1, 2), miden.ProofOptions{})
+	assert.False(ok)
+	entries, _, err := c.Size()
+	assert.Nil(err)
+	assert.Equal(1, entries)
+
+	// but removed if Miden VM rejects them
+	installFakeMiden(t, nil)
+	_, _, ok = c.Get(ctx, fakeHash(), fakeInput(1, 2), miden.ProofOptions{})
+	assert.False(ok)
+	entries, _, err = c.Size()
+	assert.Nil(err)
+	assert.Equal(0, entries)
+}
+
+func TestFakeMidenProveWithProgress(t *testing.T) {
+	assert := assert.New(t)
+	installFakeMiden(t, nil)
+	ctx := context.Background()
+
+	var lines []string
+	progress := func(line string) { lines = append(lines, line) }
+
+	hash, output, proof, stats, err := miden.ProveWithProgress(ctx, _fakeAddAssembly, fakeInput(1, 2), miden.ProofOptions{}, progress)
+	if !assert.Nil(err) {
+		return
+	}
+	assert.Equal(fakeHash(), hash)
+	assert.Equal(fakeOutput(3), output)
+	assert.NotEmpty(proof)
+
+	assert.Contains(lines, "Generated execution trace of 72 columns and 16 steps in 0 ms")
+	assert.Contains(lines, "Program with hash "+hex.EncodeToString(fakeHash())+" proved in 0 ms")
+	assert.Equal(16, stats.TraceLength)
+	assert.Positive(stats.WallTime)
+
+	_, _, _, _, err = miden.ProveWithProgress(ctx, _fakeAddAssembly, fakeInput(0, 0), miden.ProofOptions{}, progress)
+	var exitError *exec.ExitError
+	if assert.ErrorAs(err, &exitError) {
+		assert.Contains(string(exitError.Stderr), "assertion failed")
+	}
+}
+
+func TestFakeMidenVerifyBatch(t *testing.T) {
+	assert := assert.New(t)
+	installFakeMiden(t, nil)
+	ctx := context.Background()
+
+	_, output, proof, err := miden.Prove(ctx, _fakeAddAssembly, fakeInput(1, 2))
+	if !assert.Nil(err) {
+		return
+	}
+	valid := miden.VerifyItem{Proof: proof, Input: fakeInput(1, 2), Output: output}
+	invalid := miden.VerifyItem{Proof: proof, Input: fakeInput(1, 2), Output: fakeOutput(4)}
+
+	results, err := miden.VerifyBatch(ctx, fakeHash(), []miden.VerifyItem{valid, valid, valid})
+	assert.Nil(err)
+	assert.Equal([]miden.VerifyResult{{OK: true}, {OK: true}, {OK: true}}, results)
+
+	results, err = miden.VerifyBatch(ctx, fakeHash(), []miden.VerifyItem{valid, invalid, valid, invalid})
+	var verifyError *miden.VerifyError
+	if assert.ErrorAs(err, &verifyError) {
+		assert.Equal(1, verifyError.Index)
+	}
+	if assert.Len(results, 4) {
+		assert.True(results[0].OK)
+		assert.False(results[1].OK)
+		assert.True(results[2].OK)
+		assert.False(results[3].OK)
+		assert.ErrorAs(results[3].Err, &verifyError)
+		assert.Equal(3, verifyError.Index)
+	}
+
+	opts := miden.VerifyBatchOptions{Workers: 1, FailFast: true}
+	results, err = miden.VerifyBatchWithOptions(ctx, opts, fakeHash(), []miden.VerifyItem{valid, invalid, valid})
+	assert.Error(err)
+	assert.NotErrorIs(err, miden.ErrVerifySkipped)
+	if assert.Len(results, 3) {
+		assert.True(results[0].OK)
+		assert.False(results[1].OK)
+		assert.ErrorIs(results[2].Err, miden.ErrVerifySkipped)
+	}
+
+	results, err = miden.VerifyBatch(ctx, fakeHash(), nil)
+	assert.Nil(err)
+	assert.Empty(results)
+}
+
+func keptDirs(t *testing.T, root string) []string {
+	t.Helper()
+
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var dirs []string
+	for _, entry := range entries {
+		dirs = append(dirs, path.Join(root, entry.Name()))
+	}
+	return dirs
+}
+
+func TestFakeMidenVerifyBatch_FailFast(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	// Valid items take long enough to be killed when the invalid one fails
+	midentest.Install(t, midentest.Config{
+		Programs: []midentest.Program{{
+			Assembly: _fakeAddAssembly,
+			Hash:     fakeHash(),
+			Runs: []midentest.Run{
+				{Input: fakeInput(1, 2), Output: fakeOutput(3), Delay: time.Minute},
+				{Input: fakeInput(0, 0), Err: "assertion failed at clock cycle 1"},
+			},
+		}},
+	})
+	proof, err := midentest.FakeProof(fakeHash(), fakeInput(1, 2), fakeOutput(3), miden.ProofOptions{})
+	if !assert.Nil(err) {
+		return
+	}
+	valid := miden.VerifyItem{Proof: proof, Input: fakeInput(1, 2), Output: fakeOutput(3)}
+	invalid := miden.VerifyItem{Proof: proof, Input: fakeInput(0, 0), Output: fakeOutput(3)}
+
+	opts := miden.VerifyBatchOptions{Workers: 3, FailFast: true}
+	results, err := miden.VerifyBatchWithOptions(ctx, opts, fakeHash(), []miden.VerifyItem{valid, valid, invalid, valid})
+	var verifyError *miden.VerifyError
+	if assert.ErrorAs(err, &verifyError) {
+		assert.Equal(2, verifyError.Index)
+	}
+	assert.NotErrorIs(err, miden.ErrVerifySkipped)
+	if assert.Len(results, 4) {
+		for _, i := range []int{0, 1, 3} {
+			assert.ErrorIs(results[i].Err, miden.ErrVerifySkipped, "item %d", i)
+		}
+		assert.False(results[2].OK)
+	}
+}
+
+func TestFakeMidenKeepWorkDir(t *testing.T) {
+	assert := assert.New(t)
+	installFakeMiden(t, nil)
+	ctx := context.Background()
+
+	root := path.Join(t.TempDir(), "runs")
+	miden.SetWorkDirConfig(miden.WorkDirConfig{Root: root, Keep: miden.KeepOnFailure})
+	t.Cleanup(func() { miden.SetWorkDirConfig(miden.WorkDirConfig{}) })
+
+	_, _, err := miden.Run(ctx, _fakeAddAssembly, fakeInput(1, 2))
+	assert.Nil(err)
+	assert.Empty(keptDirs(t, root))
+
+	_, _, err = miden.Run(ctx, _fakeAddAssembly, fakeInput(0, 0))
+	assert.Error(err)
+
+	dirs := keptDirs(t, root)
+	if !assert.Len(dirs, 1) {
+		return
+	}
+	assert.FileExists(path.Join(dirs[0], "assembly.masm"))
+	assert.FileExists(path.Join(dirs[0], "input.json"))
+
+	manifest, err := miden.ReadManifest(dirs[0])
+	if !assert.Nil(err) {
+		return
+	}
+	assert.True(manifest.Failed)
+	assert.Equal(midentest.Version, manifest.MidenVersion)
+	if assert.Len(manifest.Commands, 1) {
+		c := manifest.Commands[0]
+		assert.Equal([]string{"run", "--assembly", "assembly.masm", "--input", "input.json", "--output", "output.json"}, c.Args)
+		assert.Equal(1, c.ExitCode)
+		assert.Contains(c.Stderr, "assertion failed")
+	}
+
+	rerun, err := miden.Rerun(ctx, dirs[0])
+	assert.Error(err)
+	if assert.NotNil(rerun) {
+		assert.True(rerun.Failed)
+		assert.Len(rerun.Commands, 1)
+	}
+}
+
+func TestFakeMidenKeepWorkDir_Debug(t *testing.T) {
+	assert := assert.New(t)
+	installFakeMiden(t, nil)
+
+	root := t.TempDir()
+	miden.SetWorkDirConfig(miden.WorkDirConfig{Root: root, Keep: miden.KeepOnFailure})
+	t.Cleanup(func() { miden.SetWorkDirConfig(miden.WorkDirConfig{}) })
+
+	// The fake CLI has no debugger, so the session fails
+	_, err := miden.Trace(context.Background(), _fakeAddAssembly, fakeInput(1, 2), 0)
+	assert.Error(err)
+
+	dirs := keptDirs(t, root)
+	if !assert.Len(dirs, 1) {
+		return
+	}
+	manifest, err := miden.ReadManifest(dirs[0])
+	if !assert.Nil(err) {
+		return
+	}
+	assert.True(manifest.Failed)
+	if assert.Len(manifest.Commands, 1) {
+		c := manifest.Commands[0]
+		assert.Equal([]string{"debug", "--assembly", "assembly.masm", "--input", "input.json"}, c.Args)
+		assert.Equal(1, c.ExitCode)
+		assert.Contains(c.Stderr, "unsupported command debug")
+	}
+}
+
+func TestFakeMidenKeepWorkDirAlways(t *testing.T) {
+	assert := assert.New(t)
+	installFakeMiden(t, nil)
+	ctx := context.Background()
+
+	program, err := newTestProgramCache(t).Compile(ctx, _fakeAddAssembly)
+	if !assert.Nil(err) {
+		return
+	}
+
+	root := t.TempDir()
+	miden.SetWorkDirConfig(miden.WorkDirConfig{Root: root, Keep: miden.KeepAlways})
+	t.Cleanup(func() { miden.SetWorkDirConfig(miden.WorkDirConfig{}) })
+
+	_, _, err = program.Prove(ctx, fakeInput(1, 2))
+	assert.Nil(err)
+
+	dirs := keptDirs(t, root)
+	if !assert.Len(dirs, 1) {
+		return
+	}
+
+	// The assembly of the program cache is copied into the kept directory
+	assert.FileExists(path.Join(dirs[0], "assembly.masm"))
+	assert.FileExists(path.Join(dirs[0], "proof.bin"))
+
+	manifest, err := miden.ReadManifest(dirs[0])
+	if !assert.Nil(err) {
+		return
+	}
+	assert.False(manifest.Failed)
+	if assert.Len(manifest.Commands, 1) {
+		assert.Equal("prove", manifest.Commands[0].Args[0])
+		assert.Equal("assembly.masm", manifest.Commands[0].Args[2])
+		assert.Equal(0, manifest.Commands[0].ExitCode)
+	}
+
+	assert.Nil(os.Remove(path.Join(dirs[0], "proof.bin")))
+	rerun, err := miden.Rerun(ctx, dirs[0])
+	assert.Nil(err)
+	assert.False(rerun.Failed)
+	assert.FileExists(path.Join(dirs[0], "proof.bin"))
+}
+
+func TestFakeMidenKeepWorkDirAlways_Nested(t *testing.T) {
+	assert := assert.New(t)
+	installFakeMiden(t, nil)
+	ctx := context.Background()
+
+	c, err := miden.NewProofCache(miden.ProofCacheConfig{Dir: t.TempDir()})
+	if !assert.Nil(err) {
+		return
+	}
+	miden.SetDefaultProofCache(c)
+	t.Cleanup(func() { miden.SetDefaultProofCache(nil) })
+
+	_, _, _, err = miden.Prove(ctx, _fakeAddAssembly, fakeInput(1, 2))
+	if !assert.Nil(err) {
+		return
+	}
+
+	root := t.TempDir()
+	miden.SetWorkDirConfig(miden.WorkDirConfig{Root: root, Keep: miden.KeepAlways})
+	t.Cleanup(func() { miden.SetWorkDirConfig(miden.WorkDirConfig{}) })
+
+	// The cached proof is verified in a directory of its own
+	_, _, _, err = miden.Prove(ctx, _fakeAddAssembly, fakeInput(1, 2))
+	assert.Nil(err)
+
+	dirs := keptDirs(t, root)
+	if !assert.Len(dirs, 2) {
+		return
+	}
+
+	var commands []miden.ManifestCommand
+	for _, dir := range dirs {
+		manifest, err := miden.ReadManifest(dir)
+		if !assert.Nil(err) {
+			return
+		}
+		if len(manifest.Commands) == 0 {
+			continue
+		}
+
+		for _, c := range manifest.Commands {
+			for _, arg := range c.Args {
+				assert.False(path.IsAbs(arg), "%s in %v", arg, c.Args)
+			}
+		}
+		commands = append(commands, manifest.Commands...)
+
+		rerun, err := miden.Rerun(ctx, dir)
+		assert.Nil(err)
+		assert.False(rerun.Failed)
+	}
+	if assert.Len(commands, 1) {
+		assert.Equal("verify", commands[0].Args[0])
+	}
+}
+
+func TestFakeMiden_UnknownCommand(t *testing.T) {
+	assert := assert.New(t)
+	installFakeMiden(t, nil)
+
+	out, err := exec.Command("miden", "bundle", "--namespace", "oracle", t.TempDir()).CombinedOutput()
+	var exitError *exec.ExitError
+	if assert.ErrorAs(err, &exitError) {
+		assert.Equal(1, exitError.ExitCode())
+	}
+	assert.Contains(string(out), "error: Found argument 'bundle' which wasn't expected")
+}
@@ -2,6 +2,8 @@ package miden
 
 import (
 	"encoding/json"
+	"fmt"
+	"strconv"
 
 	field "github.com/qredo/verifiable-oracles/pkg/goldilocks"
 )
@@ -12,14 +14,33 @@ type Input struct {
 	AdviceStack  field.Vector `json:"advice_stack,omitempty"`
 }
 
+// Miden expects canonical decimal values, whereas Element.String prints
+// elements close to the modulus as negative numbers.
 func marshalVector(v field.Vector) []string {
 	r := make([]string, len(v))
 	for i := range v {
-		r[i] = v[i].String()
+		r[i] = strconv.FormatUint(v[i].Uint64(), 10)
 	}
 	return r
 }
 
+// Values are parsed strictly, as the canonical decimal values printed by
+// Miden, so that an element has a single encoding.
+func unmarshalVector(s []string) (field.Vector, error) {
+	if s == nil {
+		return nil, nil
+	}
+
+	r := make(field.Vector, len(s))
+	for i := range s {
+		var err error
+		if r[i], err = field.ParseDecimal(s[i]); err != nil {
+			return nil, fmt.Errorf("miden: invalid field element %q: %w", s[i], err)
+		}
+	}
+	return r, nil
+}
+
 // Need to explicitly implement json.Marshaler, as Miden expect expty stacks to
 // be encoded as [] and strings
 func (f Input) MarshalJSON() ([]byte, error) {
@@ -33,6 +54,33 @@ func (f Input) MarshalJSON() ([]byte, error) {
 	return json.Marshal(data)
 }
 
+// Need to explicitly implement json.Unmarshaler, so that stacks are parsed
+// strictly.
+func (f *Input) UnmarshalJSON(data []byte) error {
+	var (
+		raw struct {
+			OperandStack []string `json:"operand_stack"`
+			AdviceStack  []string `json:"advice_stack"`
+		}
+		input Input
+		err   error
+	)
+
+	if err = json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if input.OperandStack, err = unmarshalVector(raw.OperandStack); err != nil {
+		return err
+	}
+	if input.AdviceStack, err = unmarshalVector(raw.AdviceStack); err != nil {
+		return err
+	}
+
+	*f = input
+	return nil
+}
+
 // Type Assertions
 var _ json.Marshaler = (*Input)(nil)
 var _ json.Marshaler = Input{}
+var _ json.Unmarshaler = (*Input)(nil)
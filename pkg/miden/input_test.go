@@ -53,6 +53,45 @@ func TestInputFileJsonMarshal(t *testing.T) {
 	}
 }
 
+func TestInputFileJsonUnmarshal(t *testing.T) {
+	for name, tc := range _inputFileTable {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			var in miden.Input
+			err := json.Unmarshal([]byte(tc.want), &in)
+			assert.Nil(err)
+
+			j, err := json.Marshal(in)
+			assert.Nil(err)
+			assert.Equal(tc.want, string(j))
+			assert.Equal(tc.input.AdviceStack, in.AdviceStack)
+		})
+	}
+}
+
+func TestInputFileJsonUnmarshal_Error(t *testing.T) {
+	table := map[string]string{
+		"not a string":  `{"operand_stack":[1]}`,
+		"modulus":       `{"operand_stack":["18446744069414584321"]}`,
+		"negative":      `{"advice_stack":["-1"]}`,
+		"leading zero":  `{"advice_stack":["007"]}`,
+		"not a number":  `{"operand_stack":["one"]}`,
+		"not a vector":  `{"operand_stack":"1"}`,
+		"too large":     `{"operand_stack":["18446744073709551616"]}`,
+		"underscore":    `{"operand_stack":["1_000"]}`,
+		"advice modulo": `{"advice_stack":["18446744069414584322"]}`,
+	}
+	for name, data := range table {
+		t.Run(name, func(t *testing.T) {
+			var in miden.Input
+
+			err := json.Unmarshal([]byte(data), &in)
+			assert.Error(t, err)
+		})
+	}
+}
+
 func TestInputTestData(t *testing.T) {
 	assert := assert.New(t)
 
@@ -0,0 +1,303 @@
+// Package masl encodes MASM library modules into .masl libraries, the
+// format of the libraries passed to the Miden VM 0.6 CLI with --libraries.
+// The CLI reads libraries but cannot create them, so they are encoded here
+// as the Miden assembler does.
+package masl
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Extension of library files
+const Extension = ".masl"
+
+// Extension of module files
+const _moduleExtension = ".masm"
+
+// Version of the libraries, 0.1.0 as the Miden assembler defaults to
+var _version = [3]uint16{0, 1, 0}
+
+// Module is the source of a library module.  The first component of the
+// path is the namespace of the library, e.g. oracle::math.
+type Module struct {
+	Path   string
+	Source []byte
+}
+
+// Library is a set of modules sharing a namespace.
+type Library struct {
+	namespace string
+	// Modules sorted by path
+	paths   []string
+	modules []*moduleAST
+	// Namespaces of the imported modules, except this library
+	dependencies []string
+}
+
+// New parses the modules of the library with the given namespace.
+func New(namespace string, modules []Module) (*Library, error) {
+	if !isLabel(namespace) {
+		return nil, fmt.Errorf("masl: invalid namespace %q", namespace)
+	}
+	if len(modules) == 0 {
+		return nil, fmt.Errorf("masl: library %s has no modules", namespace)
+	}
+	if len(modules) > _maxU16 {
+		return nil, fmt.Errorf("masl: library %s has %d modules, more than %d", namespace, len(modules), _maxU16)
+	}
+
+	sorted := slices.Clone(modules)
+	slices.SortFunc(sorted, func(a, b Module) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+
+	l := &Library{namespace: namespace}
+	dependencies := make(map[string]bool)
+	for i, m := range sorted {
+		n, err := pathComponents(m.Path)
+		switch {
+		case err != nil:
+			return nil, fmt.Errorf("masl: invalid module path %q: %w", m.Path, err)
+		case n < 2 || !strings.HasPrefix(m.Path, namespace+_pathSeparator):
+			return nil, fmt.Errorf("masl: module %s not in namespace %s", m.Path, namespace)
+		case i > 0 && m.Path == sorted[i-1].Path:
+			return nil, fmt.Errorf("masl: duplicate module %s", m.Path)
+		}
+
+		ast, err := parseModule(string(m.Source))
+		if err != nil {
+			return nil, fmt.Errorf("masl: module %s: %w", m.Path, err)
+		}
+		for _, path := range ast.imports {
+			dependencies[strings.SplitN(path, _pathSeparator, 2)[0]] = true
+		}
+
+		l.paths = append(l.paths, m.Path)
+		l.modules = append(l.modules, ast)
+	}
+
+	delete(dependencies, namespace)
+	l.dependencies = sortedKeys(dependencies)
+	return l, nil
+}
+
+// ReadDir parses the modules found in dir into a library with the given
+// namespace, as the Miden assembler does: subdirectories are path components
+// and .masm files are modules, e.g. dir/crypto/rpo.masm is the module
+// <namespace>::crypto::rpo.
+func ReadDir(dir string, namespace string) (*Library, error) {
+	var modules []Module
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || !entry.Type().IsRegular() || filepath.Ext(path) != _moduleExtension {
+			return err
+		}
+
+		rel, err := filepath.Rel(dir, strings.TrimSuffix(path, _moduleExtension))
+		if err != nil {
+			return err
+		}
+		source, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		components := append([]string{namespace}, strings.Split(filepath.ToSlash(rel), "/")...)
+		modules = append(modules, Module{Path: strings.Join(components, _pathSeparator), Source: source})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("masl: %w", err)
+	}
+
+	return New(namespace, modules)
+}
+
+// Namespace returns the namespace of the library.
+func (l *Library) Namespace() string {
+	return l.namespace
+}
+
+// MarshalBinary encodes the library, without source locations.
+func (l *Library) MarshalBinary() ([]byte, error) {
+	var w writer
+	w.string8(l.namespace)
+	for _, v := range _version {
+		w.u16(v)
+	}
+
+	w.u16(uint16(len(l.dependencies)))
+	for _, d := range l.dependencies {
+		w.string8(d)
+	}
+
+	w.u16(uint16(len(l.modules)))
+	for i, m := range l.modules {
+		// Module paths are relative to the namespace
+		w.string16(strings.TrimPrefix(l.paths[i], l.namespace+_pathSeparator))
+		m.encode(&w)
+	}
+
+	w.bool(false)
+	return w.buf, nil
+}
+
+// WriteToDir writes the library to the file named after its namespace in
+// dir, and returns the path of the file.
+func (l *Library) WriteToDir(dir string) (string, error) {
+	b, err := l.MarshalBinary()
+	if err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(dir, l.namespace+Extension)
+	if err = os.WriteFile(path, b, 0644); err != nil {
+		return "", fmt.Errorf("masl: %w", err)
+	}
+	return path, nil
+}
+
+func (m *moduleAST) encode(w *writer) {
+	w.string16(m.docs)
+
+	w.u16(uint16(len(m.imports)))
+	for _, name := range sortedKeys(m.imports) {
+		w.string16(m.imports[name])
+	}
+
+	w.u16(uint16(len(m.reexports)))
+	for _, name := range sortedKeys(m.reexports) {
+		r := m.reexports[name]
+		w.bytes(r.id[:])
+		w.string8(r.name)
+	}
+
+	w.u16(uint16(len(m.procs)))
+	for _, p := range m.procs {
+		w.string8(p.name)
+		w.string16(p.docs)
+		w.bool(p.export)
+		w.u16(p.locals)
+		w.nodes(p.body)
+	}
+}
+
+// A node of a procedure body.
+type node interface {
+	encode(w *writer)
+}
+
+// An instruction encoded as its opcode followed by its immediate values.
+type instruction []byte
+
+func newInstruction(code byte) instruction {
+	return instruction{code}
+}
+
+func (i instruction) u8(v uint8) instruction {
+	return append(i, v)
+}
+
+func (i instruction) u16(v uint16) instruction {
+	return binary.LittleEndian.AppendUint16(i, v)
+}
+
+func (i instruction) u32(v uint32) instruction {
+	return binary.LittleEndian.AppendUint32(i, v)
+}
+
+func (i instruction) u64(v uint64) instruction {
+	return binary.LittleEndian.AppendUint64(i, v)
+}
+
+func (i instruction) bytes(b []byte) instruction {
+	return append(i, b...)
+}
+
+func (i instruction) encode(w *writer) {
+	w.bytes(i)
+}
+
+type ifElse struct {
+	onTrue, onFalse []node
+}
+
+func (n ifElse) encode(w *writer) {
+	w.u8(_opIfElse)
+	w.nodes(n.onTrue)
+	w.nodes(n.onFalse)
+}
+
+type repeat struct {
+	times uint32
+	body  []node
+}
+
+func (n repeat) encode(w *writer) {
+	w.u8(_opRepeat)
+	w.u32(n.times)
+	w.nodes(n.body)
+}
+
+type while struct {
+	body []node
+}
+
+func (n while) encode(w *writer) {
+	w.u8(_opWhile)
+	w.nodes(n.body)
+}
+
+// Encodes values in little endian, as the Miden assembler does.  Lengths
+// are checked by the parser.
+type writer struct {
+	buf []byte
+}
+
+func (w *writer) u8(v uint8) {
+	w.buf = append(w.buf, v)
+}
+
+func (w *writer) u16(v uint16) {
+	w.buf = binary.LittleEndian.AppendUint16(w.buf, v)
+}
+
+func (w *writer) u32(v uint32) {
+	w.buf = binary.LittleEndian.AppendUint32(w.buf, v)
+}
+
+func (w *writer) bool(v bool) {
+	if v {
+		w.u8(1)
+	} else {
+		w.u8(0)
+	}
+}
+
+func (w *writer) bytes(b []byte) {
+	w.buf = append(w.buf, b...)
+}
+
+// Writes a string with a one byte length.
+func (w *writer) string8(s string) {
+	w.u8(uint8(len(s)))
+	w.buf = append(w.buf, s...)
+}
+
+// Writes a string with a two byte length.
+func (w *writer) string16(s string) {
+	w.u16(uint16(len(s)))
+	w.buf = append(w.buf, s...)
+}
+
+func (w *writer) nodes(nodes []node) {
+	w.u16(uint16(len(nodes)))
+	for _, n := range nodes {
+		n.encode(w)
+	}
+}
@@ -0,0 +1,298 @@
+package masl_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zeebo/blake3"
+
+	"github.com/qredo/verifiable-oracles/pkg/miden/masl"
+)
+
+// Builds the expected encoding from bytes, strings, and nested slices.
+func encode(parts ...any) []byte {
+	var b []byte
+	for _, p := range parts {
+		switch p := p.(type) {
+		case int:
+			b = append(b, byte(p))
+		case string:
+			b = append(b, p...)
+		case []byte:
+			b = append(b, p...)
+		default:
+			panic("invalid part")
+		}
+	}
+	return b
+}
+
+// Returns the encoding of a string with a one byte length.
+func str8(s string) []byte {
+	return encode(len(s), s)
+}
+
+// Returns the encoding of a string with a two byte length.
+func str16(s string) []byte {
+	return encode(len(s), len(s)>>8, s)
+}
+
+func procedureID(path string) []byte {
+	sum := blake3.Sum256([]byte(path))
+	return sum[:20]
+}
+
+func readModule(t *testing.T, path, file string) masl.Module {
+	t.Helper()
+
+	source, err := os.ReadFile(filepath.Join("../testdata/project", file))
+	require.NoError(t, err)
+	return masl.Module{Path: path, Source: source}
+}
+
+func TestLibrary(t *testing.T) {
+	assert := assert.New(t)
+
+	l, err := masl.New("oracle", []masl.Module{
+		readModule(t, "oracle::math", "oracle/math.masm"),
+		readModule(t, "oracle::checks", "oracle/checks.masm"),
+	})
+	require.NoError(t, err)
+	assert.Equal("oracle", l.Namespace())
+
+	addOne := procedureID("oracle::math::add_one")
+	expected := encode(
+		str8("oracle"),
+		0, 0, 1, 0, 0, 0, // version
+		0, 0, // dependencies
+		2, 0, // modules
+
+		str16("checks"),
+		0, 0, // docs
+		1, 0, str16("oracle::math"),
+		1, 0, addOne, str8("increment"),
+		2, 0,
+		str8("assert_two"), 0, 0, 1, 0, 0,
+		2, 0, 196, 2, 1, // push.TWO assert_eq
+		str8("assert_one"), str16("Asserts that the top of the stack is one"), 1, 0, 0,
+		2, 0, 235, addOne, 234, 0, 0, // exec.math::add_one exec.assert_two
+
+		str16("math"),
+		0, 0, // docs
+		0, 0, // imports
+		0, 0, // re-exports
+		2, 0,
+		str8("increment"), 0, 0, 0, 0, 0,
+		2, 0, 196, 1, 4, // push.ONE add
+		str8("add_one"), 0, 0, 1, 0, 0,
+		1, 0, 234, 0, 0, // exec.increment
+
+		0, // source locations
+	)
+
+	b, err := l.MarshalBinary()
+	assert.NoError(err)
+	assert.Equal(expected, b)
+
+	dir := t.TempDir()
+	path, err := l.WriteToDir(dir)
+	assert.NoError(err)
+	assert.Equal(filepath.Join(dir, "oracle.masl"), path)
+
+	written, err := os.ReadFile(path)
+	assert.NoError(err)
+	assert.Equal(expected, written)
+
+	l, err = masl.ReadDir("../testdata/project/oracle", "oracle")
+	require.NoError(t, err)
+	b, err = l.MarshalBinary()
+	assert.NoError(err)
+	assert.Equal(expected, b)
+}
+
+func TestLibrary_Dependencies(t *testing.T) {
+	assert := assert.New(t)
+
+	l, err := masl.New("lib", []masl.Module{{
+		Path: "lib::m",
+		Source: []byte(`#! Module docs
+
+use.std::math::u64
+use.other::a::b
+use.lib::c
+
+export.f
+  exec.u64::checked_add
+  call.b::g
+end
+`),
+	}})
+	require.NoError(t, err)
+
+	expected := encode(
+		str8("lib"),
+		0, 0, 1, 0, 0, 0,
+		2, 0, str8("other"), str8("std"),
+		1, 0,
+		str16("m"),
+		str16("Module docs"),
+		3, 0, str16("other::a::b"), str16("lib::c"), str16("std::math::u64"),
+		0, 0,
+		1, 0,
+		str8("f"), 0, 0, 1, 0, 0,
+		2, 0,
+		235, procedureID("std::math::u64::checked_add"),
+		238, procedureID("other::a::b::g"),
+		0,
+	)
+
+	b, err := l.MarshalBinary()
+	assert.NoError(err)
+	assert.Equal(expected, b)
+}
+
+// Returns the encoding of a library with a single procedure lib::m::f.
+func procedureLibrary(body []byte) []byte {
+	return encode(
+		str8("lib"),
+		0, 0, 1, 0, 0, 0,
+		0, 0,
+		1, 0,
+		str16("m"),
+		0, 0, 0, 0, 0, 0,
+		1, 0,
+		str8("f"), 0, 0, 1, 0, 0,
+		body,
+		0,
+	)
+}
+
+var _instructionTable = map[string]struct {
+	source   string
+	expected []byte
+}{
+	"simple": {
+		source:   "assert assertz u32checked_max fri_ext2fold4",
+		expected: encode(4, 0, 0, 3, 116, 233),
+	},
+	"field immediates": {
+		source:   "add.1 add.2 sub.18446744069414584320 div.3 eq.0 neq",
+		expected: encode(6, 0, 14, 5, 2, 0, 0, 0, 0, 0, 0, 0, 7, 0, 0, 0, 0, 255, 255, 255, 255, 11, 3, 0, 0, 0, 0, 0, 0, 0, 24, 0, 0, 0, 0, 0, 0, 0, 0, 25),
+	},
+	"exp": {
+		source:   "exp exp.5 exp.u32",
+		expected: encode(3, 0, 16, 17, 5, 0, 0, 0, 0, 0, 0, 0, 18, 32),
+	},
+	"u32": {
+		source:   "u32assert.2 u32checked_add.65536 u32unchecked_divmod.+3 u32checked_shl.31 u32unchecked_rotr",
+		expected: encode(5, 0, 42, 47, 0, 0, 1, 0, 79, 3, 0, 0, 0, 89, 31, 94),
+	},
+	"stack": {
+		source:   "dup dup.15 dupw.3 swap swapw.2 movup.2 movdnw.3 swapdw",
+		expected: encode(8, 0, 121, 136, 140, 141, 157, 160, 191, 159),
+	},
+	"push": {
+		source:   "push.255 push.256 push.65536 push.0xffffffff00000000",
+		expected: encode(4, 0, 196, 255, 197, 0, 1, 198, 0, 0, 1, 0, 199, 0, 0, 0, 0, 255, 255, 255, 255),
+	},
+	"push list": {
+		source:   "push.1.2 push.1.0x0100 push.1.2.3.4294967296 push.0x00000000000000010000000000000002",
+		expected: encode(4, 0, 201, 2, 1, 2, 202, 2, 1, 0, 0, 1, 200, 1, 0, 0, 0, 0, 0, 0, 0, 2, 0, 0, 0, 0, 0, 0, 0, 3, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1, 0, 0, 0, 201, 2, 1, 2),
+	},
+	"memory": {
+		source:   "mem_load mem_loadw.7 mem_storew.ADDR adv_push.16 adv.push_mapval.0 adv.push_mapvaln.12 adv.insert_hdword.7",
+		expected: encode(7, 0, 209, 212, 7, 0, 0, 0, 219, 3, 0, 0, 0, 223, 16, 225, 3, 225, 6, 12, 225, 10, 7),
+	},
+	"breakpoint": {
+		source:   "breakpoint drop",
+		expected: encode(1, 0, 118),
+	},
+	"control flow": {
+		source:   "if.true drop else while.true dropw end end repeat.3 padw end if.true drop end",
+		expected: encode(3, 0, 253, 1, 0, 118, 1, 0, 255, 1, 0, 119, 254, 3, 0, 0, 0, 1, 0, 120, 253, 1, 0, 118, 0, 0),
+	},
+	"invocation": {
+		source: "syscall.foo call.0x0100000000000000020000000000000003000000000000000400000000000000",
+		expected: encode(2, 0, 239, procedureID("#sys::foo"),
+			237, 1, 0, 0, 0, 0, 0, 0, 0, 2, 0, 0, 0, 0, 0, 0, 0, 3, 0, 0, 0, 0, 0, 0, 0, 4, 0, 0, 0, 0, 0, 0, 0),
+	},
+}
+
+func TestLibrary_Instructions(t *testing.T) {
+	for name, test := range _instructionTable {
+		t.Run(name, func(t *testing.T) {
+			source := "const.ADDR=3\nexport.f\n" + test.source + "\nend\n"
+			l, err := masl.New("lib", []masl.Module{{Path: "lib::m", Source: []byte(source)}})
+			require.NoError(t, err)
+
+			b, err := l.MarshalBinary()
+			assert.NoError(t, err)
+			assert.Equal(t, procedureLibrary(test.expected), b)
+		})
+	}
+}
+
+var _errorTable = map[string]struct {
+	source   string
+	expected string
+}{
+	"empty":             {source: "# nothing\n", expected: "empty source"},
+	"program":           {source: "begin\n  drop\nend\n", expected: "1:1: begin in a library module"},
+	"unknown":           {source: "proc.f\n  frobnicate\nend\n", expected: "2:3: unknown instruction frobnicate"},
+	"missing end":       {source: "proc.f\n  drop\n", expected: "1:1: missing end of procedure f"},
+	"dangling else":     {source: "proc.f\n  else\nend\n", expected: "2:3: else without if"},
+	"dangling docs":     {source: "proc.f\n  drop\nend\n#! docs\n", expected: "4: dangling doc comment"},
+	"duplicate":         {source: "proc.f\nend\nproc.f\nend\n", expected: "3:1: duplicate procedure f"},
+	"undefined":         {source: "proc.f\n  exec.g\nend\n", expected: `2:3: undefined procedure g`},
+	"not imported":      {source: "proc.f\n  exec.u64::add\nend\n", expected: "2:3: module u64 not imported"},
+	"import namespace":  {source: "use.u64\n", expected: `1:1: module path "u64" without a namespace`},
+	"constant":          {source: "const.A=18446744069414584321\n", expected: `1:1: invalid value "18446744069414584321" of constant A`},
+	"undefined const":   {source: "proc.f\n  push.B\nend\n", expected: "2:3: undefined constant B"},
+	"const range":       {source: "const.A=65536\nproc.f\n  loc_load.A\nend\n", expected: "3:3: constant A of loc_load.A out of range"},
+	"division by zero":  {source: "proc.f\n  u32checked_div.0\nend\n", expected: "2:3: division by zero in u32checked_div.0"},
+	"invalid position":  {source: "proc.f\n  movup.1\nend\n", expected: `2:3: invalid parameter "1" of movup.1`},
+	"exec MAST root":    {source: "proc.f\n  exec.0x00\nend\n", expected: "2:3: exec of a MAST root"},
+	"too many values":   {source: "proc.f\n  push.1.2.3.4.5.6.7.8.9.10.11.12.13.14.15.16.17\nend\n", expected: "2:3: too many parameters in push.1.2.3.4.5.6.7.8.9.10.11.12.13.14.15.16.17"},
+	"invalid hex":       {source: "proc.f\n  push.0x123\nend\n", expected: `2:3: invalid parameter "0x123" of push.0x123`},
+	"mid-line doc":      {source: "proc.f\n  drop #! docs\nend\n", expected: "2:8: dangling doc comment"},
+	"dangling ops":      {source: "proc.f\nend\nend\n", expected: "3:1: unexpected end after procedures"},
+	"constant in body":  {source: "proc.f\n  const.A=1\nend\n", expected: "2:3: constant declared inside a procedure"},
+	"reexport":          {source: "export.u64::add\n", expected: "1:1: module u64 not imported"},
+	"invalid proc name": {source: "proc.1f\nend\n", expected: `1:1: invalid procedure name "1f"`},
+}
+
+func TestLibrary_Error(t *testing.T) {
+	for name, test := range _errorTable {
+		t.Run(name, func(t *testing.T) {
+			_, err := masl.New("lib", []masl.Module{{Path: "lib::m", Source: []byte(test.source)}})
+			assert.EqualError(t, err, "masl: module lib::m: "+test.expected)
+		})
+	}
+}
+
+func TestLibrary_InvalidModules(t *testing.T) {
+	assert := assert.New(t)
+	source := []byte("proc.f\nend\n")
+
+	_, err := masl.New("lib", nil)
+	assert.EqualError(err, "masl: library lib has no modules")
+
+	_, err = masl.New("lib::a", []masl.Module{{Path: "lib::a::m", Source: source}})
+	assert.EqualError(err, `masl: invalid namespace "lib::a"`)
+
+	_, err = masl.New("lib", []masl.Module{{Path: "other::m", Source: source}})
+	assert.EqualError(err, "masl: module other::m not in namespace lib")
+
+	_, err = masl.New("lib", []masl.Module{{Path: "lib", Source: source}})
+	assert.EqualError(err, "masl: module lib not in namespace lib")
+
+	_, err = masl.New("lib", []masl.Module{{Path: "lib::m", Source: source}, {Path: "lib::m", Source: source}})
+	assert.EqualError(err, "masl: duplicate module lib::m")
+
+	_, err = masl.New("lib", []masl.Module{{Path: "lib::" + strings.Repeat("a", 256), Source: source}})
+	assert.ErrorContains(err, "masl: invalid module path")
+}
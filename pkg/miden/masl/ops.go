@@ -0,0 +1,707 @@
+package masl
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Modulus of the field of the Miden VM
+const _modulus = 0xffffffff00000001
+
+// Opcodes of instructions parsed with dedicated code, the other ones are in
+// the tables below.
+const (
+	_opIncr         = 14
+	_opExp          = 16
+	_opExpImm       = 17
+	_opExpBitLength = 18
+	_opU32Assert    = 41
+	_opU32Assert2   = 42
+	_opPushU8       = 196
+	_opPushU16      = 197
+	_opPushU32      = 198
+	_opPushFelt     = 199
+	_opPushWord     = 200
+	_opPushU8List   = 201
+	_opPushU16List  = 202
+	_opPushU32List  = 203
+	_opPushFeltList = 204
+	_opAdvPush      = 223
+	_opAdvInject    = 225
+	_opExecLocal    = 234
+	_opExecImported = 235
+	_opCallLocal    = 236
+	_opCallMastRoot = 237
+	_opCallImported = 238
+	_opSysCall      = 239
+	_opIfElse       = 253
+	_opRepeat       = 254
+	_opWhile        = 255
+)
+
+// Limits of instruction parameters
+const (
+	_maxPushValues   = 16
+	_maxShift        = 31
+	_maxExpBits      = 64
+	_maxAdviceRead   = 16
+	_hexChunkSize    = 16
+	_mastRootHexSize = 2 + 64
+	_wordSize        = 4
+)
+
+// Opcodes of instructions without parameters
+var _simpleOps = map[string]byte{
+	"assert":              0,
+	"assert_eq":           1,
+	"assert_eqw":          2,
+	"assertz":             3,
+	"neg":                 12,
+	"inv":                 13,
+	"pow2":                15,
+	"not":                 19,
+	"and":                 20,
+	"or":                  21,
+	"xor":                 22,
+	"eqw":                 27,
+	"lt":                  28,
+	"lte":                 29,
+	"gt":                  30,
+	"gte":                 31,
+	"is_odd":              32,
+	"ext2add":             33,
+	"ext2sub":             34,
+	"ext2mul":             35,
+	"ext2div":             36,
+	"ext2neg":             37,
+	"ext2inv":             38,
+	"u32test":             39,
+	"u32testw":            40,
+	"u32assertw":          43,
+	"u32split":            44,
+	"u32cast":             45,
+	"u32overflowing_add3": 52,
+	"u32wrapping_add3":    53,
+	"u32overflowing_madd": 66,
+	"u32wrapping_madd":    67,
+	"u32checked_and":      80,
+	"u32checked_or":       81,
+	"u32checked_xor":      82,
+	"u32checked_not":      83,
+	"u32checked_popcnt":   100,
+	"u32unchecked_popcnt": 101,
+	"u32checked_lt":       106,
+	"u32unchecked_lt":     107,
+	"u32checked_lte":      108,
+	"u32unchecked_lte":    109,
+	"u32checked_gt":       110,
+	"u32unchecked_gt":     111,
+	"u32checked_gte":      112,
+	"u32unchecked_gte":    113,
+	"u32checked_min":      114,
+	"u32unchecked_min":    115,
+	"u32checked_max":      116,
+	"u32unchecked_max":    117,
+	"drop":                118,
+	"dropw":               119,
+	"padw":                120,
+	"swapdw":              159,
+	"cswap":               192,
+	"cswapw":              193,
+	"cdrop":               194,
+	"cdropw":              195,
+	"sdepth":              206,
+	"caller":              207,
+	"clk":                 208,
+	"mem_stream":          221,
+	"adv_pipe":            222,
+	"adv_loadw":           224,
+	"hash":                226,
+	"hmerge":              227,
+	"hperm":               228,
+	"mtree_get":           229,
+	"mtree_set":           230,
+	"mtree_merge":         231,
+	"mtree_verify":        232,
+	"fri_ext2fold4":       233,
+}
+
+// Opcodes of instructions with an optional immediate field element. The
+// opcode of the immediate form follows the one of the stack form.
+var _feltOps = map[string]byte{
+	"add": 4,
+	"sub": 6,
+	"mul": 8,
+	"div": 10,
+	"eq":  23,
+	"neq": 25,
+}
+
+// Opcodes of instructions with an optional immediate u32, as for _feltOps
+var _u32Ops = map[string]byte{
+	"u32checked_add":      46,
+	"u32wrapping_add":     48,
+	"u32overflowing_add":  50,
+	"u32checked_sub":      54,
+	"u32wrapping_sub":     56,
+	"u32overflowing_sub":  58,
+	"u32checked_mul":      60,
+	"u32wrapping_mul":     62,
+	"u32overflowing_mul":  64,
+	"u32checked_div":      68,
+	"u32unchecked_div":    70,
+	"u32checked_mod":      72,
+	"u32unchecked_mod":    74,
+	"u32checked_divmod":   76,
+	"u32unchecked_divmod": 78,
+	"u32checked_eq":       102,
+	"u32checked_neq":      104,
+}
+
+// Opcodes of instructions with an optional immediate shift of 0 to 31 bits,
+// as for _feltOps
+var _shiftOps = map[string]byte{
+	"u32checked_shr":    84,
+	"u32unchecked_shr":  86,
+	"u32checked_shl":    88,
+	"u32unchecked_shl":  90,
+	"u32checked_rotr":   92,
+	"u32unchecked_rotr": 94,
+	"u32checked_rotl":   96,
+	"u32unchecked_rotl": 98,
+}
+
+// Instructions whose immediate value is a divisor
+var _divisionOps = map[string]bool{
+	"div":                 true,
+	"u32checked_div":      true,
+	"u32unchecked_div":    true,
+	"u32checked_mod":      true,
+	"u32unchecked_mod":    true,
+	"u32checked_divmod":   true,
+	"u32unchecked_divmod": true,
+}
+
+// A stack instruction taking a position, encoded with one opcode per
+// position.
+type stackOp struct {
+	// Opcode of the first position
+	code     byte
+	min, max int
+	// Position without a parameter, or -1 if the parameter is required
+	defaultPosition int
+}
+
+var _stackOps = map[string]stackOp{
+	"dup":    {code: 121, min: 0, max: 15, defaultPosition: 0},
+	"dupw":   {code: 137, min: 0, max: 3, defaultPosition: 0},
+	"swap":   {code: 141, min: 1, max: 15, defaultPosition: 1},
+	"swapw":  {code: 156, min: 1, max: 3, defaultPosition: 1},
+	"movup":  {code: 160, min: 2, max: 15, defaultPosition: -1},
+	"movupw": {code: 174, min: 2, max: 3, defaultPosition: -1},
+	"movdn":  {code: 176, min: 2, max: 15, defaultPosition: -1},
+	"movdnw": {code: 190, min: 2, max: 3, defaultPosition: -1},
+}
+
+// Opcodes of memory instructions with an optional immediate address, as for
+// _feltOps
+var _memoryOps = map[string]byte{
+	"mem_load":   209,
+	"mem_loadw":  211,
+	"mem_store":  215,
+	"mem_storew": 218,
+}
+
+// Opcodes of instructions with a required local memory index
+var _localOps = map[string]byte{
+	"locaddr":    205,
+	"loc_load":   213,
+	"loc_loadw":  214,
+	"loc_store":  217,
+	"loc_storew": 220,
+}
+
+// An advice injector of the adv instruction.
+type injector struct {
+	code byte
+	// Largest immediate value, zero if the injector takes none. The code of
+	// the immediate form follows the code of the injector.
+	max uint64
+}
+
+var _injectors = map[string]injector{
+	"push_u64div":   {code: 0},
+	"push_ext2intt": {code: 1},
+	"push_smtget":   {code: 2},
+	"push_mapval":   {code: 3, max: 12},
+	"push_mapvaln":  {code: 5, max: 12},
+	"push_mtnode":   {code: 7},
+	"insert_mem":    {code: 8},
+	"insert_hdword": {code: 9, max: 255},
+}
+
+// Parses an instruction token. It returns a nil node for debug decorators,
+// which are not serialized.
+func (p *parser) parseInstruction(t token) (node, error) {
+	name := t.parts[0]
+	if code, ok := _simpleOps[name]; ok {
+		if len(t.parts) > 1 {
+			return nil, p.extraParam(t)
+		}
+		return instruction{code}, nil
+	}
+	if code, ok := _feltOps[name]; ok {
+		return p.parseFeltOp(t, code)
+	}
+	if code, ok := _u32Ops[name]; ok {
+		return p.parseU32Op(t, code)
+	}
+	if code, ok := _shiftOps[name]; ok {
+		return p.parseShiftOp(t, code)
+	}
+	if op, ok := _stackOps[name]; ok {
+		return p.parseStackOp(t, op)
+	}
+	if code, ok := _memoryOps[name]; ok {
+		return p.parseMemoryOp(t, code)
+	}
+	if code, ok := _localOps[name]; ok {
+		return p.parseLocalOp(t, code)
+	}
+
+	switch name {
+	case "exp":
+		return p.parseExp(t)
+	case "u32assert":
+		return p.parseU32Assert(t)
+	case "push":
+		return p.parsePush(t)
+	case "adv_push":
+		return p.parseAdvPush(t)
+	case "adv":
+		return p.parseAdvInject(t)
+	case _tokenExec, _tokenCall, _tokenSyscall:
+		return p.parseInvocation(t)
+	case _tokenConst:
+		return nil, p.errorf(t, "constant declared inside a procedure")
+	case "breakpoint":
+		if len(t.parts) > 1 {
+			return nil, p.extraParam(t)
+		}
+		return nil, nil
+	}
+	return nil, p.errorf(t, "unknown instruction %s", t)
+}
+
+func (p *parser) parseFeltOp(t token, code byte) (node, error) {
+	switch len(t.parts) {
+	case 1:
+		return instruction{code}, nil
+	case 2:
+	default:
+		return nil, p.extraParam(t)
+	}
+
+	v, err := p.feltParam(t, 1)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case v == 0 && _divisionOps[t.parts[0]]:
+		return nil, p.errorf(t, "division by zero in %s", t)
+	case v == 1 && t.parts[0] == "add":
+		return instruction{_opIncr}, nil
+	}
+	return newInstruction(code + 1).u64(v), nil
+}
+
+func (p *parser) parseExp(t token) (node, error) {
+	switch len(t.parts) {
+	case 1:
+		return instruction{_opExp}, nil
+	case 2:
+	default:
+		return nil, p.extraParam(t)
+	}
+
+	bits, ok := strings.CutPrefix(t.parts[1], "u")
+	if !ok {
+		v, err := p.feltParam(t, 1)
+		if err != nil {
+			return nil, err
+		}
+		return newInstruction(_opExpImm).u64(v), nil
+	}
+
+	n, ok := parseUint(bits, 8)
+	if !ok || n > _maxExpBits {
+		return nil, p.invalidParam(t, 1)
+	}
+	return newInstruction(_opExpBitLength).u8(uint8(n)), nil
+}
+
+func (p *parser) parseU32Assert(t token) (node, error) {
+	switch {
+	case len(t.parts) > 2:
+		return nil, p.extraParam(t)
+	case len(t.parts) == 1 || t.parts[1] == "1":
+		return instruction{_opU32Assert}, nil
+	case t.parts[1] == "2":
+		return instruction{_opU32Assert2}, nil
+	}
+	return nil, p.invalidParam(t, 1)
+}
+
+func (p *parser) parseU32Op(t token, code byte) (node, error) {
+	switch len(t.parts) {
+	case 1:
+		return instruction{code}, nil
+	case 2:
+	default:
+		return nil, p.extraParam(t)
+	}
+
+	v, ok := parseUint(t.parts[1], 32)
+	switch {
+	case !ok:
+		return nil, p.invalidParam(t, 1)
+	case v == 0 && _divisionOps[t.parts[0]]:
+		return nil, p.errorf(t, "division by zero in %s", t)
+	}
+	return newInstruction(code + 1).u32(uint32(v)), nil
+}
+
+func (p *parser) parseShiftOp(t token, code byte) (node, error) {
+	switch len(t.parts) {
+	case 1:
+		return instruction{code}, nil
+	case 2:
+	default:
+		return nil, p.extraParam(t)
+	}
+
+	n, ok := parseUint(t.parts[1], 8)
+	if !ok || n > _maxShift {
+		return nil, p.invalidParam(t, 1)
+	}
+	return newInstruction(code + 1).u8(uint8(n)), nil
+}
+
+func (p *parser) parseStackOp(t token, op stackOp) (node, error) {
+	position := op.defaultPosition
+	switch len(t.parts) {
+	case 1:
+		if position < 0 {
+			return nil, p.missingParam(t)
+		}
+	case 2:
+		// Positions are matched literally, without signs or leading zeros
+		n, err := strconv.Atoi(t.parts[1])
+		if err != nil || strconv.Itoa(n) != t.parts[1] || n < op.min || n > op.max {
+			return nil, p.invalidParam(t, 1)
+		}
+		position = n
+	default:
+		return nil, p.extraParam(t)
+	}
+	return instruction{op.code + byte(position-op.min)}, nil
+}
+
+func (p *parser) parseMemoryOp(t token, code byte) (node, error) {
+	switch len(t.parts) {
+	case 1:
+		return instruction{code}, nil
+	case 2:
+	default:
+		return nil, p.extraParam(t)
+	}
+
+	address, err := p.constantOrUint(t, 1, 32)
+	if err != nil {
+		return nil, err
+	}
+	return newInstruction(code + 1).u32(uint32(address)), nil
+}
+
+func (p *parser) parseLocalOp(t token, code byte) (node, error) {
+	switch len(t.parts) {
+	case 1:
+		return nil, p.missingParam(t)
+	case 2:
+	default:
+		return nil, p.extraParam(t)
+	}
+
+	index, err := p.constantOrUint(t, 1, 16)
+	if err != nil {
+		return nil, err
+	}
+	return newInstruction(code).u16(uint16(index)), nil
+}
+
+func (p *parser) parsePush(t token) (node, error) {
+	switch {
+	case len(t.parts) == 1:
+		return nil, p.missingParam(t)
+	case len(t.parts) > _maxPushValues+1:
+		return nil, p.extraParam(t)
+	case len(t.parts) > 2:
+		values := make([]uint64, 0, len(t.parts)-1)
+		for i := 1; i < len(t.parts); i++ {
+			v, err := p.pushValue(t, i)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, v)
+		}
+		return pushValues(values), nil
+	}
+
+	digits, ok := strings.CutPrefix(t.parts[1], "0x")
+	if !ok || len(digits) <= _hexChunkSize {
+		v, err := p.pushValue(t, 1)
+		if err != nil {
+			return nil, err
+		}
+		return pushValue(v), nil
+	}
+
+	// Long hexadecimal values are lists of values of 8 bytes each
+	if len(digits)%_hexChunkSize != 0 {
+		return nil, p.errorf(t, "hexadecimal value %q not a multiple of %d digits", digits, _hexChunkSize)
+	}
+	var values []uint64
+	for i := 0; i < len(digits); i += _hexChunkSize {
+		v, err := p.hexValue(t, digits[i:i+_hexChunkSize], 1)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	return pushValues(values), nil
+}
+
+// Returns the value of parameter i of a push instruction, in hexadecimal,
+// decimal, or the name of a constant.
+func (p *parser) pushValue(t token, i int) (uint64, error) {
+	if digits, ok := strings.CutPrefix(t.parts[i], "0x"); ok {
+		return p.hexValue(t, digits, i)
+	}
+
+	name := t.parts[i]
+	if isConstantLabel(name) {
+		v, ok := p.constants[name]
+		if !ok {
+			return 0, p.errorf(t, "undefined constant %s", name)
+		}
+		return v, nil
+	}
+	return p.feltParam(t, i)
+}
+
+func (p *parser) hexValue(t token, digits string, i int) (uint64, error) {
+	if len(digits)%2 != 0 || len(digits) > _hexChunkSize {
+		return 0, p.invalidParam(t, i)
+	}
+	v, ok := parseUintBase(digits, 16, 64)
+	if !ok || v >= _modulus {
+		return 0, p.invalidParam(t, i)
+	}
+	return v, nil
+}
+
+// Returns the push instruction of the smallest type holding v.
+func pushValue(v uint64) instruction {
+	switch {
+	case v <= 0xff:
+		return newInstruction(_opPushU8).u8(uint8(v))
+	case v <= 0xffff:
+		return newInstruction(_opPushU16).u16(uint16(v))
+	case v <= 0xffffffff:
+		return newInstruction(_opPushU32).u32(uint32(v))
+	}
+	return newInstruction(_opPushFelt).u64(v)
+}
+
+// Returns the push instruction of the smallest type holding all of values.
+func pushValues(values []uint64) instruction {
+	var i instruction
+	switch max := slices.Max(values); {
+	case max <= 0xff:
+		i = newInstruction(_opPushU8List).u8(uint8(len(values)))
+		for _, v := range values {
+			i = i.u8(uint8(v))
+		}
+	case max <= 0xffff:
+		i = newInstruction(_opPushU16List).u8(uint8(len(values)))
+		for _, v := range values {
+			i = i.u16(uint16(v))
+		}
+	case max <= 0xffffffff:
+		i = newInstruction(_opPushU32List).u8(uint8(len(values)))
+		for _, v := range values {
+			i = i.u32(uint32(v))
+		}
+	case len(values) == _wordSize:
+		i = newInstruction(_opPushWord)
+		for _, v := range values {
+			i = i.u64(v)
+		}
+	default:
+		i = newInstruction(_opPushFeltList).u8(uint8(len(values)))
+		for _, v := range values {
+			i = i.u64(v)
+		}
+	}
+	return i
+}
+
+func (p *parser) parseAdvPush(t token) (node, error) {
+	switch len(t.parts) {
+	case 1:
+		return nil, p.missingParam(t)
+	case 2:
+	default:
+		return nil, p.extraParam(t)
+	}
+
+	n, ok := parseUint(t.parts[1], 8)
+	if !ok || n < 1 || n > _maxAdviceRead {
+		return nil, p.invalidParam(t, 1)
+	}
+	return newInstruction(_opAdvPush).u8(uint8(n)), nil
+}
+
+func (p *parser) parseAdvInject(t token) (node, error) {
+	if len(t.parts) < 2 {
+		return nil, p.missingParam(t)
+	}
+	inj, ok := _injectors[t.parts[1]]
+	switch {
+	case !ok:
+		return nil, p.errorf(t, "unknown instruction %s", t)
+	case len(t.parts) == 2:
+		return instruction{_opAdvInject, inj.code}, nil
+	case len(t.parts) > 3 || inj.max == 0:
+		return nil, p.extraParam(t)
+	}
+
+	v, ok := parseUint(t.parts[2], 8)
+	switch {
+	case !ok || v > inj.max:
+		return nil, p.invalidParam(t, 2)
+	case v == 0:
+		return instruction{_opAdvInject, inj.code}, nil
+	}
+	return instruction{_opAdvInject, inj.code + 1, byte(v)}, nil
+}
+
+func (p *parser) parseInvocation(t token) (node, error) {
+	switch len(t.parts) {
+	case 1:
+		return nil, p.missingParam(t)
+	case 2:
+	default:
+		return nil, p.extraParam(t)
+	}
+
+	kind, target := t.parts[0], t.parts[1]
+	if strings.HasPrefix(target, "0x") {
+		if kind != _tokenCall {
+			return nil, p.errorf(t, "%s of a MAST root", kind)
+		}
+		root, err := decodeMastRoot(target)
+		if err != nil {
+			return nil, p.errorf(t, "invalid MAST root %q: %v", target, err)
+		}
+		return newInstruction(_opCallMastRoot).bytes(root), nil
+	}
+
+	n, err := pathComponents(target)
+	if err != nil || n > 2 {
+		return nil, p.errorf(t, "invalid procedure %q", target)
+	}
+
+	if n == 1 {
+		if kind == _tokenSyscall {
+			id := newProcedureID(_kernelPath + _pathSeparator + target)
+			return newInstruction(_opSysCall).bytes(id[:]), nil
+		}
+
+		index, ok := p.procIndices[target]
+		if !ok {
+			return nil, p.errorf(t, "undefined procedure %s", target)
+		}
+		code := byte(_opExecLocal)
+		if kind == _tokenCall {
+			code = _opCallLocal
+		}
+		return newInstruction(code).u16(uint16(index)), nil
+	}
+
+	if kind == _tokenSyscall {
+		return nil, p.errorf(t, "syscall of a procedure of module %q", target)
+	}
+	module, name, _ := strings.Cut(target, _pathSeparator)
+	path, ok := p.imports[module]
+	if !ok {
+		return nil, p.errorf(t, "module %s not imported", module)
+	}
+	id := newProcedureID(path + _pathSeparator + name)
+	code := byte(_opExecImported)
+	if kind == _tokenCall {
+		code = _opCallImported
+	}
+	return newInstruction(code).bytes(id[:]), nil
+}
+
+// Decodes a hexadecimal MAST root, four field elements in little endian.
+func decodeMastRoot(s string) ([]byte, error) {
+	if len(s) != _mastRootHexSize {
+		return nil, strconv.ErrSyntax
+	}
+	root, err := hex.DecodeString(s[2:])
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < len(root); i += 8 {
+		if binary.LittleEndian.Uint64(root[i:]) >= _modulus {
+			return nil, strconv.ErrRange
+		}
+	}
+	return root, nil
+}
+
+// Returns the field element of parameter i.
+func (p *parser) feltParam(t token, i int) (uint64, error) {
+	v, ok := parseUint(t.parts[i], 64)
+	if !ok || v >= _modulus {
+		return 0, p.invalidParam(t, i)
+	}
+	return v, nil
+}
+
+// Returns parameter i, an unsigned integer of bitSize bits or the name of a
+// constant.
+func (p *parser) constantOrUint(t token, i int, bitSize int) (uint64, error) {
+	name := t.parts[i]
+	if !isConstantLabel(name) {
+		v, ok := parseUint(name, bitSize)
+		if !ok {
+			return 0, p.invalidParam(t, i)
+		}
+		return v, nil
+	}
+
+	v, ok := p.constants[name]
+	switch {
+	case !ok:
+		return 0, p.errorf(t, "undefined constant %s", name)
+	case v >= 1<<bitSize:
+		return 0, p.errorf(t, "constant %s of %s out of range", name, t)
+	}
+	return v, nil
+}
@@ -0,0 +1,580 @@
+package masl
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/zeebo/blake3"
+)
+
+// Limits imposed by Miden assembly
+const (
+	_maxLabelLength = 255
+	_maxPathLength  = 1023
+	_maxU16         = 1<<16 - 1
+)
+
+// Size of procedure IDs, a prefix of the BLAKE3 hash of the procedure path
+const _procedureIDSize = 20
+
+// Path of the kernel module, where syscall targets are defined
+const _kernelPath = "#sys"
+
+type procedureID [_procedureIDSize]byte
+
+// Returns the ID of a procedure from its fully qualified path, e.g.
+// std::math::u64::checked_add.
+func newProcedureID(path string) procedureID {
+	var id procedureID
+	sum := blake3.Sum256([]byte(path))
+	copy(id[:], sum[:])
+	return id
+}
+
+// A parsed library module.
+type moduleAST struct {
+	docs string
+	// Imported module paths by name, the last component of the path
+	imports   map[string]string
+	reexports map[string]reexport
+	procs     []*procedure
+}
+
+// A procedure of an imported module exported under an alias.
+type reexport struct {
+	id   procedureID
+	name string
+}
+
+type procedure struct {
+	name   string
+	docs   string
+	export bool
+	locals uint16
+	body   []node
+}
+
+// Parses library modules as the Miden assembler does.
+type parser struct {
+	tokens    *tokenStream
+	imports   map[string]string
+	constants map[string]uint64
+	// Indices of the procedures parsed so far
+	procIndices map[string]int
+	procs       []*procedure
+	reexports   map[string]reexport
+}
+
+func parseModule(source string) (*moduleAST, error) {
+	tokens, err := newTokenStream(source)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{
+		tokens:      tokens,
+		imports:     make(map[string]string),
+		constants:   make(map[string]uint64),
+		procIndices: make(map[string]int),
+		reexports:   make(map[string]reexport),
+	}
+	if err = p.parseImports(); err != nil {
+		return nil, err
+	}
+	if err = p.parseConstants(); err != nil {
+		return nil, err
+	}
+	if err = p.parseProcedures(); err != nil {
+		return nil, err
+	}
+
+	if t, ok := tokens.read(); ok {
+		if t.parts[0] == _tokenBegin {
+			return nil, p.errorf(t, "begin in a library module")
+		}
+		return nil, p.errorf(t, "unexpected %s after procedures", t)
+	}
+
+	switch {
+	case len(p.procs) > _maxU16:
+		return nil, fmt.Errorf("%d procedures, more than %d", len(p.procs), _maxU16)
+	case len(p.reexports) > _maxU16:
+		return nil, fmt.Errorf("%d re-exported procedures, more than %d", len(p.reexports), _maxU16)
+	case len(tokens.moduleDocs) > _maxU16:
+		return nil, fmt.Errorf("module docs of %d bytes, longer than %d", len(tokens.moduleDocs), _maxU16)
+	}
+
+	m := &moduleAST{
+		docs:      tokens.moduleDocs,
+		imports:   p.imports,
+		reexports: p.reexports,
+		procs:     p.procs,
+	}
+	return m, nil
+}
+
+func (p *parser) errorf(t token, format string, args ...any) error {
+	return fmt.Errorf("%d:%d: %s", t.line, t.column, fmt.Sprintf(format, args...))
+}
+
+func (p *parser) missingParam(t token) error {
+	return p.errorf(t, "missing parameter of %s", t)
+}
+
+func (p *parser) extraParam(t token) error {
+	return p.errorf(t, "too many parameters in %s", t)
+}
+
+func (p *parser) invalidParam(t token, i int) error {
+	return p.errorf(t, "invalid parameter %q of %s", t.parts[i], t)
+}
+
+func (p *parser) parseImports() error {
+	for t, ok := p.tokens.read(); ok && t.parts[0] == _tokenUse; t, ok = p.tokens.read() {
+		switch len(t.parts) {
+		case 1:
+			return p.missingParam(t)
+		case 2:
+		default:
+			return p.extraParam(t)
+		}
+
+		path := t.parts[1]
+		n, err := pathComponents(path)
+		switch {
+		case err != nil:
+			return p.errorf(t, "invalid module path %q: %v", path, err)
+		case n < 2 || !isLabel(path[:strings.Index(path, _pathSeparator)]):
+			return p.errorf(t, "module path %q without a namespace", path)
+		}
+		name := path[strings.LastIndex(path, _pathSeparator)+len(_pathSeparator):]
+		if _, ok := p.imports[name]; ok {
+			return p.errorf(t, "duplicate import of module %s", name)
+		}
+
+		p.imports[name] = path
+		p.tokens.advance()
+	}
+
+	if len(p.imports) > _maxU16 {
+		return fmt.Errorf("%d imports, more than %d", len(p.imports), _maxU16)
+	}
+	return nil
+}
+
+func (p *parser) parseConstants() error {
+	for t, ok := p.tokens.read(); ok && t.parts[0] == _tokenConst; t, ok = p.tokens.read() {
+		switch len(t.parts) {
+		case 1:
+			return p.missingParam(t)
+		case 2:
+		default:
+			return p.extraParam(t)
+		}
+
+		declaration := strings.Split(t.parts[1], "=")
+		switch len(declaration) {
+		case 1:
+			return p.missingParam(t)
+		case 2:
+		default:
+			return p.extraParam(t)
+		}
+
+		name, value := declaration[0], declaration[1]
+		if !isConstantLabel(name) {
+			return p.errorf(t, "invalid constant name %q", name)
+		}
+		v, ok := parseUint(value, 64)
+		if !ok || v >= _modulus {
+			return p.errorf(t, "invalid value %q of constant %s", value, name)
+		}
+		if _, ok := p.constants[name]; ok {
+			return p.errorf(t, "duplicate constant %s", name)
+		}
+
+		p.constants[name] = v
+		p.tokens.advance()
+	}
+	return nil
+}
+
+func (p *parser) parseProcedures() error {
+	for {
+		t, ok := p.tokens.read()
+		if !ok {
+			return nil
+		}
+
+		var isReexport bool
+		switch t.parts[0] {
+		case _tokenExport:
+			if len(t.parts) < 2 {
+				return p.missingParam(t)
+			}
+			isReexport = strings.Contains(t.parts[1], _pathSeparator)
+		case _tokenProc:
+		default:
+			return nil
+		}
+
+		if isReexport {
+			r, err := p.parseReexport()
+			if err != nil {
+				return err
+			}
+			p.reexports[r.name] = r
+			continue
+		}
+
+		proc, err := p.parseProcedure()
+		if err != nil {
+			return err
+		}
+		p.procIndices[proc.name] = len(p.procs)
+		p.procs = append(p.procs, proc)
+	}
+}
+
+func (p *parser) hasProcedure(name string) bool {
+	_, local := p.procIndices[name]
+	_, reexported := p.reexports[name]
+	return local || reexported
+}
+
+func (p *parser) parseProcedure() (*procedure, error) {
+	start := p.tokens.pos
+	header, _ := p.tokens.read()
+	if len(header.parts) < 2 {
+		return nil, p.missingParam(header)
+	}
+
+	proc := &procedure{name: header.parts[1], export: header.parts[0] == _tokenExport}
+	switch len(header.parts) {
+	case 2:
+	case 3:
+		locals, ok := parseUint(header.parts[2], 64)
+		if !ok || locals > _maxU16 {
+			return nil, p.errorf(header, "invalid number of locals %q", header.parts[2])
+		}
+		proc.locals = uint16(locals)
+	default:
+		return nil, p.extraParam(header)
+	}
+
+	if !isLabel(proc.name) {
+		return nil, p.errorf(header, "invalid procedure name %q", proc.name)
+	}
+	if p.hasProcedure(proc.name) {
+		return nil, p.errorf(header, "duplicate procedure %s", proc.name)
+	}
+	p.tokens.advance()
+
+	// Only exported procedures keep their doc comments
+	if proc.export {
+		proc.docs = p.tokens.procDocs[start]
+		if len(proc.docs) > _maxU16 {
+			return nil, p.errorf(header, "docs of %d bytes, longer than %d", len(proc.docs), _maxU16)
+		}
+	}
+
+	var err error
+	if proc.body, err = p.parseBody(false); err != nil {
+		return nil, err
+	}
+	if err = p.parseEnd(start, "procedure "+proc.name); err != nil {
+		return nil, err
+	}
+	return proc, nil
+}
+
+func (p *parser) parseReexport() (reexport, error) {
+	header, _ := p.tokens.read()
+	if len(header.parts) > 2 {
+		return reexport{}, p.extraParam(header)
+	}
+
+	target := header.parts[1]
+	if strings.Count(target, _pathSeparator) != 1 {
+		return reexport{}, p.errorf(header, "invalid re-exported procedure %q", target)
+	}
+	module, ref, _ := strings.Cut(target, _pathSeparator)
+	ref, name, ok := strings.Cut(ref, _exportAliasSeparator)
+	if !ok {
+		name = ref
+	}
+
+	for _, label := range []string{ref, name} {
+		if !isLabel(label) {
+			return reexport{}, p.errorf(header, "invalid procedure name %q", label)
+		}
+	}
+	if p.hasProcedure(name) {
+		return reexport{}, p.errorf(header, "duplicate procedure %s", name)
+	}
+
+	path, ok := p.imports[module]
+	if !ok {
+		return reexport{}, p.errorf(header, "module %s not imported", module)
+	}
+	p.tokens.advance()
+
+	return reexport{id: newProcedureID(path + _pathSeparator + ref), name: name}, nil
+}
+
+// Consumes the end token of the block started at the token at start.
+func (p *parser) parseEnd(start int, block string) error {
+	t, ok := p.tokens.read()
+	switch {
+	case !ok:
+		return p.errorf(p.tokens.readAt(start), "missing end of %s", block)
+	case t.parts[0] == _tokenElse:
+		return p.errorf(t, "else without if")
+	case t.parts[0] != _tokenEnd:
+		return p.errorf(p.tokens.readAt(start), "missing end of %s", block)
+	case len(t.parts) > 1:
+		return p.extraParam(t)
+	}
+
+	p.tokens.advance()
+	return nil
+}
+
+// Parses nodes until a token ending the body: end, else if breakOnElse is
+// set, or the start of a procedure or program.
+func (p *parser) parseBody(breakOnElse bool) ([]node, error) {
+	var nodes []node
+
+loop:
+	for t, ok := p.tokens.read(); ok; t, ok = p.tokens.read() {
+		var (
+			n   node
+			err error
+		)
+
+		switch t.parts[0] {
+		case _tokenIf:
+			n, err = p.parseIf()
+		case _tokenWhile:
+			n, err = p.parseWhile()
+		case _tokenRepeat:
+			n, err = p.parseRepeat()
+		case _tokenElse:
+			if len(t.parts) > 1 {
+				return nil, p.extraParam(t)
+			}
+			if breakOnElse {
+				break loop
+			}
+			return nil, p.errorf(t, "else without if")
+		case _tokenEnd:
+			if len(t.parts) > 1 {
+				return nil, p.extraParam(t)
+			}
+			break loop
+		case _tokenUse:
+			return nil, p.errorf(t, "import inside a procedure")
+		case _tokenExport, _tokenProc, _tokenBegin:
+			break loop
+		default:
+			n, err = p.parseInstruction(t)
+			p.tokens.advance()
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		// Breakpoints are debug decorators, which are not encoded
+		if n != nil {
+			nodes = append(nodes, n)
+		}
+	}
+
+	if len(nodes) > _maxU16 {
+		return nil, fmt.Errorf("body of %d instructions, more than %d", len(nodes), _maxU16)
+	}
+	return nodes, nil
+}
+
+// Checks that the token t is a control flow keyword followed by .true, e.g.
+// if.true.
+func (p *parser) checkTrue(t token) error {
+	switch len(t.parts) {
+	case 1:
+		return p.missingParam(t)
+	case 2:
+		if t.parts[1] != "true" {
+			return p.invalidParam(t, 1)
+		}
+		return nil
+	default:
+		return p.extraParam(t)
+	}
+}
+
+func (p *parser) parseIf() (node, error) {
+	start := p.tokens.pos
+	header, _ := p.tokens.read()
+	if err := p.checkTrue(header); err != nil {
+		return nil, err
+	}
+	p.tokens.advance()
+
+	var (
+		n   ifElse
+		err error
+	)
+	if n.onTrue, err = p.parseBody(true); err != nil {
+		return nil, err
+	}
+
+	t, ok := p.tokens.read()
+	switch {
+	case ok && t.parts[0] == _tokenElse:
+		p.tokens.advance()
+		if n.onFalse, err = p.parseBody(false); err != nil {
+			return nil, err
+		}
+		if err = p.parseEnd(start, "if.true"); err != nil {
+			return nil, err
+		}
+	case ok && t.parts[0] == _tokenEnd:
+		p.tokens.advance()
+	default:
+		return nil, p.errorf(header, "missing end of if.true")
+	}
+	return n, nil
+}
+
+func (p *parser) parseWhile() (node, error) {
+	start := p.tokens.pos
+	header, _ := p.tokens.read()
+	if err := p.checkTrue(header); err != nil {
+		return nil, err
+	}
+	p.tokens.advance()
+
+	var (
+		n   while
+		err error
+	)
+	if n.body, err = p.parseBody(false); err != nil {
+		return nil, err
+	}
+	if err = p.parseEnd(start, "while.true"); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+func (p *parser) parseRepeat() (node, error) {
+	start := p.tokens.pos
+	header, _ := p.tokens.read()
+	switch len(header.parts) {
+	case 1:
+		return nil, p.missingParam(header)
+	case 2:
+	default:
+		return nil, p.extraParam(header)
+	}
+	times, ok := parseUint(header.parts[1], 32)
+	if !ok {
+		return nil, p.invalidParam(header, 1)
+	}
+	p.tokens.advance()
+
+	var (
+		n   = repeat{times: uint32(times)}
+		err error
+	)
+	if n.body, err = p.parseBody(false); err != nil {
+		return nil, err
+	}
+	if err = p.parseEnd(start, header.String()); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+// Returns the number of components of a module or procedure path.
+func pathComponents(path string) (int, error) {
+	if path == "" {
+		return 0, fmt.Errorf("empty path")
+	}
+	if len(path) > _maxPathLength {
+		return 0, fmt.Errorf("path longer than %d bytes", _maxPathLength)
+	}
+
+	n := 0
+	for _, prefix := range []string{_kernelPath, "#exec"} {
+		if strings.HasPrefix(path, prefix) {
+			if len(path) < len(prefix)+len(_pathSeparator) {
+				return 0, fmt.Errorf("invalid component %q", path)
+			}
+			path, n = path[len(prefix)+len(_pathSeparator):], 1
+			break
+		}
+	}
+
+	for _, c := range strings.Split(path, _pathSeparator) {
+		if !isLabel(c) {
+			return 0, fmt.Errorf("invalid component %q", c)
+		}
+		n++
+	}
+	return n, nil
+}
+
+// Reports whether s is a valid procedure name, namespace, or path component:
+// a letter followed by letters, digits, and underscores.
+func isLabel(s string) bool {
+	if s == "" || len(s) > _maxLabelLength || !isLetter(s[0]) {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; !isLetter(c) && !isDigit(c) && c != '_' {
+			return false
+		}
+	}
+	return true
+}
+
+// Reports whether s is a valid constant name: a label without lower case
+// letters.
+func isConstantLabel(s string) bool {
+	return isLabel(s) && !strings.ContainsFunc(s, func(r rune) bool { return 'a' <= r && r <= 'z' })
+}
+
+func isLetter(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+// Parses an unsigned integer as Rust does, accepting a leading plus sign.
+func parseUint(s string, bitSize int) (uint64, bool) {
+	return parseUintBase(s, 10, bitSize)
+}
+
+func parseUintBase(s string, base int, bitSize int) (uint64, bool) {
+	s = strings.TrimPrefix(s, "+")
+	if s == "" || s[0] == '+' || s[0] == '-' {
+		return 0, false
+	}
+	v, err := strconv.ParseUint(s, base, bitSize)
+	return v, err == nil
+}
+
+// Returns the names of a map in ascending order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
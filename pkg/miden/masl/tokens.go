@@ -0,0 +1,217 @@
+package masl
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Tokens of MASM source files
+const (
+	_tokenUse     = "use"
+	_tokenConst   = "const"
+	_tokenProc    = "proc"
+	_tokenExport  = "export"
+	_tokenBegin   = "begin"
+	_tokenEnd     = "end"
+	_tokenIf      = "if"
+	_tokenElse    = "else"
+	_tokenWhile   = "while"
+	_tokenRepeat  = "repeat"
+	_tokenExec    = "exec"
+	_tokenCall    = "call"
+	_tokenSyscall = "syscall"
+
+	_commentPrefix    = "#"
+	_docCommentPrefix = "#!"
+
+	_pathSeparator        = "::"
+	_exportAliasSeparator = "->"
+)
+
+// A token of a MASM source file, split into its dot-separated parts, e.g.
+// push.1.2 has the parts push, 1, and 2.
+type token struct {
+	parts        []string
+	line, column int
+}
+
+func (t token) String() string {
+	return strings.Join(t.parts, ".")
+}
+
+// A line of a MASM source file holding tokens, doc comments, or both.
+type sourceLine struct {
+	contents    string
+	hasContents bool
+	// Doc comment lines preceding the contents
+	docs         []string
+	line, offset int
+}
+
+// Splits source files into lines as the Miden assembler does: comment and
+// blank lines are skipped, and blocks of doc comments are attached to the
+// line of tokens following them, if any.
+type lineScanner struct {
+	lines []string
+
+	current    string
+	ok         bool
+	lineNumber int
+	offset     int
+}
+
+func newLineScanner(source string) *lineScanner {
+	lines := strings.Split(source, "\n")
+	for i, l := range lines {
+		lines[i] = strings.TrimSuffix(l, "\r")
+	}
+	return &lineScanner{lines: lines}
+}
+
+func (s *lineScanner) advance() {
+	if s.lineNumber >= len(s.lines) {
+		s.current, s.ok = "", false
+		return
+	}
+
+	line := s.lines[s.lineNumber]
+	s.current = strings.TrimLeftFunc(line, unicode.IsSpace)
+	s.offset = len(line) - len(s.current)
+	s.ok = true
+	s.lineNumber++
+}
+
+func (s *lineScanner) isToken() bool {
+	return s.ok && s.current != "" && !strings.HasPrefix(s.current, _commentPrefix)
+}
+
+func (s *lineScanner) isTokenOrDocComment() bool {
+	return s.isToken() || s.ok && strings.HasPrefix(s.current, _docCommentPrefix)
+}
+
+func (s *lineScanner) scan() (sourceLine, bool) {
+	s.advance()
+	for !s.isTokenOrDocComment() {
+		s.advance()
+		if !s.ok {
+			return sourceLine{}, false
+		}
+	}
+
+	var docs []string
+	start := s.lineNumber
+	for s.ok && strings.HasPrefix(s.current, _docCommentPrefix) {
+		doc := strings.TrimSpace(strings.TrimPrefix(s.current, _docCommentPrefix))
+		s.advance()
+		if doc != "" {
+			docs = append(docs, doc)
+		}
+	}
+	if len(docs) > 0 && !s.isToken() {
+		return sourceLine{docs: docs, line: start}, true
+	}
+
+	for !s.isToken() {
+		s.advance()
+		if !s.ok {
+			return sourceLine{}, false
+		}
+	}
+
+	l := sourceLine{
+		contents:    strings.TrimRightFunc(s.current, unicode.IsSpace),
+		hasContents: true,
+		docs:        docs,
+		line:        s.lineNumber,
+		offset:      s.offset,
+	}
+	return l, true
+}
+
+// The tokens of a MASM source file.
+type tokenStream struct {
+	tokens []token
+	pos    int
+	// Doc comments of procedures, by position of the procedure token
+	procDocs   map[int]string
+	moduleDocs string
+}
+
+func newTokenStream(source string) (*tokenStream, error) {
+	s := &tokenStream{procDocs: make(map[int]string)}
+
+	scanner := newLineScanner(source)
+	for {
+		l, ok := scanner.scan()
+		if !ok {
+			break
+		}
+
+		switch {
+		case l.hasContents:
+			if strings.HasPrefix(l.contents, _tokenExport) || strings.HasPrefix(l.contents, _tokenProc) {
+				s.procDocs[len(s.tokens)] = strings.Join(l.docs, "\n")
+			} else if len(l.docs) > 0 {
+				return nil, fmt.Errorf("%d: dangling doc comment", l.line)
+			}
+			if err := s.tokenize(l); err != nil {
+				return nil, err
+			}
+		case len(s.tokens) == 0:
+			s.moduleDocs = strings.Join(l.docs, "\n")
+		default:
+			return nil, fmt.Errorf("%d: dangling doc comment", l.line)
+		}
+	}
+
+	if len(s.tokens) == 0 {
+		return nil, fmt.Errorf("empty source")
+	}
+	return s, nil
+}
+
+// Splits a line into whitespace-separated tokens, up to a comment.
+func (s *tokenStream) tokenize(l sourceLine) error {
+	column := l.offset + 1
+	for rest := l.contents; rest != ""; {
+		if strings.HasPrefix(rest, _docCommentPrefix) {
+			return fmt.Errorf("%d:%d: dangling doc comment", l.line, column)
+		}
+		if strings.HasPrefix(rest, _commentPrefix) {
+			break
+		}
+
+		t, next := rest, len(rest)
+		if end := strings.IndexFunc(rest, unicode.IsSpace); end >= 0 {
+			t = rest[:end]
+			next = end + strings.IndexFunc(rest[end:], isNotSpace)
+		}
+
+		s.tokens = append(s.tokens, token{parts: strings.Split(t, "."), line: l.line, column: column})
+		rest = rest[next:]
+		column += next
+	}
+	return nil
+}
+
+func isNotSpace(r rune) bool {
+	return !unicode.IsSpace(r)
+}
+
+func (s *tokenStream) read() (token, bool) {
+	if s.pos >= len(s.tokens) {
+		return token{}, false
+	}
+	return s.tokens[s.pos], true
+}
+
+func (s *tokenStream) readAt(pos int) token {
+	return s.tokens[pos]
+}
+
+func (s *tokenStream) advance() {
+	if s.pos < len(s.tokens) {
+		s.pos++
+	}
+}
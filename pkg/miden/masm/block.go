@@ -0,0 +1,409 @@
+package masm
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	field "github.com/qredo/verifiable-oracles/pkg/goldilocks"
+)
+
+// Maximum number of values of a single push or adv_push instruction
+const MaxPushValues = 16
+
+// Block is a sequence of MASM instructions and control flow constructs.  The
+// zero value is an empty block ready to use.
+//
+// Block methods append to the block and return it, so calls can be chained.
+// Invalid parameters are recorded and reported by Validate.
+type Block struct {
+	nodes []node
+	errs  []error
+}
+
+type node interface {
+	render(w *writer)
+	validate(s *scope) error
+}
+
+// Instruction kinds needing validation against the enclosing scope
+type kind int
+
+const (
+	plain kind = iota
+	invoke
+	local
+	constant
+)
+
+type instruction struct {
+	kind   kind
+	op     string
+	params []string
+	// Procedure name for invoke, constant name for constant, local index for
+	// local
+	target string
+	index  int
+}
+
+type comment string
+
+type ifElse struct {
+	then, els *Block
+}
+
+type while struct {
+	body *Block
+}
+
+type repeat struct {
+	count int
+	body  *Block
+}
+
+func (b *Block) append(n node) *Block {
+	b.nodes = append(b.nodes, n)
+	return b
+}
+
+func (b *Block) errorf(format string, args ...any) *Block {
+	b.errs = append(b.errs, fmt.Errorf("masm: "+format, args...))
+	return b
+}
+
+// Op appends an arbitrary instruction, e.g. Op("dup", 2) renders dup.2.
+func (b *Block) Op(op string, params ...any) *Block {
+	ps := make([]string, len(params))
+	for i, p := range params {
+		ps[i] = fmt.Sprint(p)
+	}
+	return b.append(instruction{op: op, params: ps})
+}
+
+// Comment appends a comment.
+func (b *Block) Comment(text string) *Block {
+	return b.append(comment(text))
+}
+
+// Push pushes field elements onto the stack, the last element ending up on
+// top.  Values are split into multiple push instructions when needed.
+func (b *Block) Push(values ...field.Element) *Block {
+	if len(values) == 0 {
+		return b.errorf("push without values")
+	}
+
+	for len(values) > 0 {
+		n := min(len(values), MaxPushValues)
+		params := make([]string, n)
+		for i := range params {
+			params[i] = strconv.FormatUint(values[i].Uint64(), 10)
+		}
+		b.append(instruction{op: "push", params: params})
+		values = values[n:]
+	}
+	return b
+}
+
+// PushUint64 pushes integers onto the stack, see Push.
+func (b *Block) PushUint64(values ...uint64) *Block {
+	elems := make(field.Vector, len(values))
+	for i, v := range values {
+		if v >= field.Modulus().Uint64() {
+			return b.errorf("push of %d, which is not a field element", v)
+		}
+		elems[i].SetUint64(v)
+	}
+	return b.Push(elems...)
+}
+
+// PushConst pushes the value of a constant onto the stack.
+func (b *Block) PushConst(name string) *Block {
+	return b.append(instruction{kind: constant, op: "push", params: []string{name}, target: name})
+}
+
+// AdvPush pops n values from the advice stack and pushes them onto the
+// operand stack.
+func (b *Block) AdvPush(n int) *Block {
+	if n < 1 || n > MaxPushValues {
+		return b.errorf("adv_push.%d out of range [1, %d]", n, MaxPushValues)
+	}
+	return b.Op("adv_push", n)
+}
+
+// AdvLoadW overwrites the top word of the stack with a word from the advice
+// stack.
+func (b *Block) AdvLoadW() *Block {
+	return b.Op("adv_loadw")
+}
+
+// MemLoad pushes the first element of the word at addr onto the stack.
+func (b *Block) MemLoad(addr uint32) *Block {
+	return b.Op("mem_load", addr)
+}
+
+// MemLoadW overwrites the top word of the stack with the word at addr.
+func (b *Block) MemLoadW(addr uint32) *Block {
+	return b.Op("mem_loadw", addr)
+}
+
+// MemStore pops the top element and stores it as the first element of the
+// word at addr.
+func (b *Block) MemStore(addr uint32) *Block {
+	return b.Op("mem_store", addr)
+}
+
+// MemStoreW stores the top word of the stack at addr.
+func (b *Block) MemStoreW(addr uint32) *Block {
+	return b.Op("mem_storew", addr)
+}
+
+// LocLoad pushes the first element of local i onto the stack.
+func (b *Block) LocLoad(i int) *Block {
+	return b.local("loc_load", i)
+}
+
+// LocLoadW overwrites the top word of the stack with local i.
+func (b *Block) LocLoadW(i int) *Block {
+	return b.local("loc_loadw", i)
+}
+
+// LocStore pops the top element and stores it as the first element of local
+// i.
+func (b *Block) LocStore(i int) *Block {
+	return b.local("loc_store", i)
+}
+
+// LocStoreW stores the top word of the stack in local i.
+func (b *Block) LocStoreW(i int) *Block {
+	return b.local("loc_storew", i)
+}
+
+func (b *Block) local(op string, i int) *Block {
+	return b.append(instruction{kind: local, op: op, params: []string{strconv.Itoa(i)}, index: i})
+}
+
+// Exec executes a procedure, either a local one or module::procedure from an
+// imported module.
+func (b *Block) Exec(procedure string) *Block {
+	return b.invoke("exec", procedure)
+}
+
+// Call calls a procedure in a new execution context, see Exec.
+func (b *Block) Call(procedure string) *Block {
+	return b.invoke("call", procedure)
+}
+
+func (b *Block) invoke(op string, procedure string) *Block {
+	return b.append(instruction{kind: invoke, op: op, params: []string{procedure}, target: procedure})
+}
+
+// If pops the top of the stack and executes then if it is 1, or els if it is
+// 0.  els may be nil.
+func (b *Block) If(then func(*Block), els func(*Block)) *Block {
+	n := ifElse{then: build(then)}
+	if els != nil {
+		n.els = build(els)
+	}
+	return b.append(n)
+}
+
+// While executes body as long as the top of the stack, popped before every
+// iteration, is 1.
+func (b *Block) While(body func(*Block)) *Block {
+	return b.append(while{body: build(body)})
+}
+
+// Repeat executes body count times.
+func (b *Block) Repeat(count int, body func(*Block)) *Block {
+	if count < 1 {
+		return b.errorf("repeat.%d count must be positive", count)
+	}
+	return b.append(repeat{count: count, body: build(body)})
+}
+
+// Common instructions
+
+// Add pops a and b and pushes a + b.
+func (b *Block) Add() *Block { return b.Op("add") }
+
+// Sub pops b, then a, and pushes a - b.
+func (b *Block) Sub() *Block { return b.Op("sub") }
+
+// Mul pops a and b and pushes a * b.
+func (b *Block) Mul() *Block { return b.Op("mul") }
+
+// Eq pops a and b and pushes 1 if they are equal, 0 otherwise.
+func (b *Block) Eq() *Block { return b.Op("eq") }
+
+// Assert pops the top of the stack and fails unless it is 1.
+func (b *Block) Assert() *Block { return b.Op("assert") }
+
+// AssertZ pops the top of the stack and fails unless it is 0.
+func (b *Block) AssertZ() *Block { return b.Op("assertz") }
+
+// AssertEq pops the top two elements and fails unless they are equal.
+func (b *Block) AssertEq() *Block { return b.Op("assert_eq") }
+
+// Drop removes the top element of the stack.
+func (b *Block) Drop() *Block { return b.Op("drop") }
+
+// DropW removes the top word of the stack.
+func (b *Block) DropW() *Block { return b.Op("dropw") }
+
+// Dup pushes a copy of the element at depth n.
+func (b *Block) Dup(n int) *Block { return b.Op("dup", n) }
+
+// Swap swaps the top element with the element at depth n.
+func (b *Block) Swap(n int) *Block {
+	if n == 1 {
+		return b.Op("swap")
+	}
+	return b.Op("swap", n)
+}
+
+// MovUp moves the element at depth n to the top of the stack.
+func (b *Block) MovUp(n int) *Block { return b.Op("movup", n) }
+
+// MovDn moves the top element of the stack to depth n.
+func (b *Block) MovDn(n int) *Block { return b.Op("movdn", n) }
+
+// HPerm applies the Rescue Prime Optimized permutation to the top three words
+// of the stack.
+func (b *Block) HPerm() *Block { return b.Op("hperm") }
+
+func build(f func(*Block)) *Block {
+	b := &Block{}
+	if f != nil {
+		f(b)
+	}
+	return b
+}
+
+// Rendering
+
+func (i instruction) render(w *writer) {
+	w.line(strings.Join(append([]string{i.op}, i.params...), "."))
+}
+
+func (c comment) render(w *writer) {
+	for _, line := range strings.Split(string(c), "\n") {
+		w.line(strings.TrimRight("# "+line, " "))
+	}
+}
+
+func (n ifElse) render(w *writer) {
+	w.line("if.true")
+	w.block(n.then)
+	if n.els != nil {
+		w.line("else")
+		w.block(n.els)
+	}
+	w.line("end")
+}
+
+func (n while) render(w *writer) {
+	w.line("while.true")
+	w.block(n.body)
+	w.line("end")
+}
+
+func (n repeat) render(w *writer) {
+	w.line("repeat." + strconv.Itoa(n.count))
+	w.block(n.body)
+	w.line("end")
+}
+
+// Validation
+
+func (i instruction) validate(s *scope) error {
+	switch i.kind {
+	case invoke:
+		return s.checkInvoke(i.target)
+	case constant:
+		if !s.constants[i.target] {
+			return fmt.Errorf("masm: %s pushes undefined constant %s", s.name, i.target)
+		}
+	case local:
+		if i.index < 0 || i.index >= s.locals {
+			return fmt.Errorf("masm: %s.%d out of range of %d locals in %s", i.op, i.index, s.locals, s.name)
+		}
+	}
+	return nil
+}
+
+func (c comment) validate(*scope) error {
+	return nil
+}
+
+func (n ifElse) validate(s *scope) error {
+	if n.then.empty() {
+		return fmt.Errorf("masm: empty if.true in %s", s.name)
+	}
+	if n.els != nil && n.els.empty() {
+		return fmt.Errorf("masm: empty else in %s", s.name)
+	}
+	if err := n.then.validate(s); err != nil {
+		return err
+	}
+	if n.els != nil {
+		return n.els.validate(s)
+	}
+	return nil
+}
+
+func (n while) validate(s *scope) error {
+	if n.body.empty() {
+		return fmt.Errorf("masm: empty while.true in %s", s.name)
+	}
+	return n.body.validate(s)
+}
+
+func (n repeat) validate(s *scope) error {
+	if n.body.empty() {
+		return fmt.Errorf("masm: empty repeat.%d in %s", n.count, s.name)
+	}
+	return n.body.validate(s)
+}
+
+func (b *Block) validate(s *scope) error {
+	if len(b.errs) > 0 {
+		return b.errs[0]
+	}
+	for _, n := range b.nodes {
+		if err := n.validate(s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Miden assembly requires a non-empty body for every code block.
+func (b *Block) empty() bool {
+	for _, n := range b.nodes {
+		if _, ok := n.(comment); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+type writer struct {
+	sb     strings.Builder
+	indent int
+}
+
+const _indent = "    "
+
+func (w *writer) line(s string) {
+	w.sb.WriteString(strings.Repeat(_indent, w.indent))
+	w.sb.WriteString(s)
+	w.sb.WriteByte('\n')
+}
+
+func (w *writer) block(b *Block) {
+	w.indent++
+	for _, n := range b.nodes {
+		n.render(w)
+	}
+	w.indent--
+}
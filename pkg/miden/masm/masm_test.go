@@ -0,0 +1,344 @@
+package masm_test
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	field "github.com/qredo/verifiable-oracles/pkg/goldilocks"
+	"github.com/qredo/verifiable-oracles/pkg/miden"
+	"github.com/qredo/verifiable-oracles/pkg/miden/masm"
+)
+
+func lines(l ...string) string {
+	return strings.Join(l, "\n") + "\n"
+}
+
+func elem(v uint64) field.Element {
+	var e field.Element
+	e.SetUint64(v)
+	return e
+}
+
+var _renderTable = map[string]struct {
+	program  func() *masm.Program
+	expected string
+}{
+	"push": {
+		program: func() *masm.Program {
+			return masm.NewProgram(func(b *masm.Block) {
+				b.Push(elem(1), elem(2)).PushUint64(18446744069414584320).Add()
+			})
+		},
+		expected: lines(
+			"begin",
+			"    push.1.2",
+			"    push.18446744069414584320",
+			"    add",
+			"end",
+		),
+	},
+	"push splits": {
+		program: func() *masm.Program {
+			return masm.NewProgram(func(b *masm.Block) {
+				b.PushUint64(1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17)
+			})
+		},
+		expected: lines(
+			"begin",
+			"    push.1.2.3.4.5.6.7.8.9.10.11.12.13.14.15.16",
+			"    push.17",
+			"end",
+		),
+	},
+	"control flow": {
+		program: func() *masm.Program {
+			return masm.NewProgram(func(b *masm.Block) {
+				b.Comment("count down").AdvPush(1)
+				b.Dup(0).Op("neq", 0)
+				b.While(func(b *masm.Block) {
+					b.PushUint64(1).Sub()
+					b.Repeat(2, func(b *masm.Block) {
+						b.MemLoad(0).MemStore(1)
+					})
+					b.Dup(0).Op("neq", 0)
+				})
+				b.If(func(b *masm.Block) {
+					b.Drop()
+				}, func(b *masm.Block) {
+					b.MemStoreW(2)
+				})
+			})
+		},
+		expected: lines(
+			"begin",
+			"    # count down",
+			"    adv_push.1",
+			"    dup.0",
+			"    neq.0",
+			"    while.true",
+			"        push.1",
+			"        sub",
+			"        repeat.2",
+			"            mem_load.0",
+			"            mem_store.1",
+			"        end",
+			"        dup.0",
+			"        neq.0",
+			"    end",
+			"    if.true",
+			"        drop",
+			"    else",
+			"        mem_storew.2",
+			"    end",
+			"end",
+		),
+	},
+	"procedures": {
+		program: func() *masm.Program {
+			p := masm.NewProgram(func(b *masm.Block) {
+				b.PushConst("ONE").Exec("double").Call("u64::checked_add")
+			})
+			p.Use("std::math::u64")
+			p.Const("ONE", elem(1))
+			p.Proc("store", 1, func(b *masm.Block) {
+				b.LocStore(0).LocLoad(0)
+			})
+			p.Proc("double", 0, func(b *masm.Block) {
+				b.Exec("store").Dup(0).Add()
+			})
+			return p
+		},
+		expected: lines(
+			"use.std::math::u64",
+			"",
+			"const.ONE=1",
+			"",
+			"proc.store.1",
+			"    loc_store.0",
+			"    loc_load.0",
+			"end",
+			"",
+			"proc.double",
+			"    exec.store",
+			"    dup.0",
+			"    add",
+			"end",
+			"",
+			"begin",
+			"    push.ONE",
+			"    exec.double",
+			"    call.u64::checked_add",
+			"end",
+		),
+	},
+}
+
+func TestProgramAssembly(t *testing.T) {
+	for name, tc := range _renderTable {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			a, err := tc.program().Assembly()
+			assert.Nil(err)
+			assert.Equal(tc.expected, string(a))
+		})
+	}
+}
+
+func TestModuleAssembly(t *testing.T) {
+	assert := assert.New(t)
+
+	m := &masm.Module{}
+	m.Proc("helper", 0, func(b *masm.Block) {
+		b.Swap(1)
+	})
+	m.Export("swap_words", 0, func(b *masm.Block) {
+		b.Exec("helper").Op("swapw")
+	})
+
+	a, err := m.Assembly()
+	assert.Nil(err)
+	assert.Equal(lines(
+		"proc.helper",
+		"    swap",
+		"end",
+		"",
+		"export.swap_words",
+		"    exec.helper",
+		"    swapw",
+		"end",
+	), string(a))
+}
+
+var _validateErrorTable = map[string]func() *masm.Program{
+	"no main": func() *masm.Program {
+		return &masm.Program{}
+	},
+	"empty main": func() *masm.Program {
+		return masm.NewProgram(nil)
+	},
+	"main with only comments": func() *masm.Program {
+		return masm.NewProgram(func(b *masm.Block) {
+			b.Comment("nothing")
+		})
+	},
+	"undefined procedure": func() *masm.Program {
+		return masm.NewProgram(func(b *masm.Block) {
+			b.Exec("missing")
+		})
+	},
+	"procedure defined later": func() *masm.Program {
+		p := masm.NewProgram(func(b *masm.Block) {
+			b.Exec("second")
+		})
+		p.Proc("first", 0, func(b *masm.Block) {
+			b.Exec("second")
+		})
+		p.Proc("second", 0, func(b *masm.Block) {
+			b.Drop()
+		})
+		return p
+	},
+	"module not imported": func() *masm.Program {
+		return masm.NewProgram(func(b *masm.Block) {
+			b.Exec("u64::checked_add")
+		})
+	},
+	"invalid import": func() *masm.Program {
+		p := masm.NewProgram(nil)
+		p.Use("std::math::1u64")
+		return p
+	},
+	"duplicate procedure": func() *masm.Program {
+		p := masm.NewProgram(nil)
+		p.Proc("a", 0, func(b *masm.Block) { b.Drop() })
+		p.Proc("a", 0, func(b *masm.Block) { b.Drop() })
+		return p
+	},
+	"invalid procedure name": func() *masm.Program {
+		p := masm.NewProgram(nil)
+		p.Proc("_a", 0, func(b *masm.Block) { b.Drop() })
+		return p
+	},
+	"long procedure name": func() *masm.Program {
+		p := masm.NewProgram(nil)
+		p.Proc(strings.Repeat("a", masm.MaxLabelLength+1), 0, func(b *masm.Block) { b.Drop() })
+		return p
+	},
+	"empty procedure": func() *masm.Program {
+		p := masm.NewProgram(nil)
+		p.Proc("a", 0, func(b *masm.Block) { b.Comment("nothing") })
+		return p
+	},
+	"exported procedure": func() *masm.Program {
+		p := masm.NewProgram(nil)
+		p.Export("a", 0, func(b *masm.Block) { b.Drop() })
+		return p
+	},
+	"local out of range": func() *masm.Program {
+		p := masm.NewProgram(nil)
+		p.Proc("a", 1, func(b *masm.Block) { b.LocLoadW(1) })
+		return p
+	},
+	"local in main": func() *masm.Program {
+		return masm.NewProgram(func(b *masm.Block) {
+			b.LocStore(0)
+		})
+	},
+	"too many locals": func() *masm.Program {
+		p := masm.NewProgram(nil)
+		p.Proc("a", masm.MaxLocals+1, func(b *masm.Block) { b.Drop() })
+		return p
+	},
+	"undefined constant": func() *masm.Program {
+		return masm.NewProgram(func(b *masm.Block) {
+			b.PushConst("ONE")
+		})
+	},
+	"invalid constant name": func() *masm.Program {
+		p := masm.NewProgram(nil)
+		p.Const("one", elem(1))
+		return p
+	},
+	"push without values": func() *masm.Program {
+		return masm.NewProgram(func(b *masm.Block) {
+			b.Push()
+		})
+	},
+	"push of non-field element": func() *masm.Program {
+		return masm.NewProgram(func(b *masm.Block) {
+			b.PushUint64(18446744069414584321)
+		})
+	},
+	"adv_push out of range": func() *masm.Program {
+		return masm.NewProgram(func(b *masm.Block) {
+			b.AdvPush(17)
+		})
+	},
+	"repeat zero times": func() *masm.Program {
+		return masm.NewProgram(func(b *masm.Block) {
+			b.Repeat(0, func(b *masm.Block) { b.Drop() })
+		})
+	},
+	"empty while": func() *masm.Program {
+		return masm.NewProgram(func(b *masm.Block) {
+			b.While(nil)
+		})
+	},
+	"empty else": func() *masm.Program {
+		return masm.NewProgram(func(b *masm.Block) {
+			b.If(func(b *masm.Block) { b.Drop() }, func(b *masm.Block) {})
+		})
+	},
+	"nested error": func() *masm.Program {
+		return masm.NewProgram(func(b *masm.Block) {
+			b.If(func(b *masm.Block) {
+				b.AdvPush(0)
+			}, nil)
+		})
+	},
+}
+
+func TestProgramValidate_Error(t *testing.T) {
+	for name, program := range _validateErrorTable {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+			p := program()
+
+			assert.Error(p.Validate())
+
+			a, err := p.Assembly()
+			assert.Error(err)
+			assert.Nil(a)
+		})
+	}
+}
+
+func TestProgramCompile(t *testing.T) {
+	if _, err := exec.LookPath("miden"); err != nil {
+		t.Skip("miden not found, skipping")
+	}
+	assert := assert.New(t)
+
+	p := masm.NewProgram(func(b *masm.Block) {
+		b.AdvPush(2).Exec("double").Add()
+	})
+	p.Proc("double", 1, func(b *masm.Block) {
+		b.LocStore(0).LocLoad(0).LocLoad(0).Add()
+	})
+
+	program, err := p.Compile(context.Background())
+	if !assert.Nil(err) {
+		return
+	}
+
+	output, err := program.Run(context.Background(), miden.Input{
+		AdviceStack: field.Vector{elem(3), elem(4)},
+	})
+	assert.Nil(err)
+	assert.Equal(elem(11), output.Stack[0])
+}
@@ -0,0 +1,318 @@
+// Package masm builds Miden assembly programs and modules in Go.
+//
+// Programs are rendered to miden.Assembly, validated first, so that errors
+// such as calls to undefined procedures or out of range locals are reported
+// before invoking Miden VM.
+package masm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	field "github.com/qredo/verifiable-oracles/pkg/goldilocks"
+	"github.com/qredo/verifiable-oracles/pkg/miden"
+)
+
+// Limits imposed by Miden assembly
+const (
+	MaxLabelLength = 255
+	MaxLocals      = 1 << 16
+)
+
+// Procedure is a MASM procedure.
+type Procedure struct {
+	Name   string
+	Locals int
+	Export bool
+	Body   Block
+}
+
+// Constant is a named field element, pushed with Block.PushConst.
+type Constant struct {
+	Name  string
+	Value field.Element
+}
+
+// Module is a MASM library module: imports, constants, and procedures, some of
+// which are exported.
+type Module struct {
+	imports    []string
+	constants  []Constant
+	procedures []*Procedure
+}
+
+// Program is a MASM program: a module with a main block.
+type Program struct {
+	Module
+	main *Block
+}
+
+// Use imports a module by its path, e.g. "std::math::u64".  Procedures of the
+// module are invoked by the last component of the path, e.g.
+// "u64::checked_add".
+func (m *Module) Use(path string) *Module {
+	m.imports = append(m.imports, path)
+	return m
+}
+
+// Const defines a constant.
+func (m *Module) Const(name string, value field.Element) *Module {
+	m.constants = append(m.constants, Constant{Name: name, Value: value})
+	return m
+}
+
+// Proc defines a procedure with the specified number of locals.  Procedures
+// can only invoke procedures defined before them.
+func (m *Module) Proc(name string, locals int, body func(*Block)) *Module {
+	return m.proc(name, locals, false, body)
+}
+
+// Export defines an exported procedure, see Proc.
+func (m *Module) Export(name string, locals int, body func(*Block)) *Module {
+	return m.proc(name, locals, true, body)
+}
+
+func (m *Module) proc(name string, locals int, export bool, body func(*Block)) *Module {
+	m.procedures = append(m.procedures, &Procedure{
+		Name:   name,
+		Locals: locals,
+		Export: export,
+		Body:   *build(body),
+	})
+	return m
+}
+
+// Validate checks the module for errors.
+func (m *Module) Validate() error {
+	_, err := m.validate()
+	return err
+}
+
+// Validates the module and returns the scope after its last procedure.
+func (m *Module) validate() (*scope, error) {
+	s := &scope{
+		procedures: make(map[string]bool, len(m.procedures)),
+		modules:    make(map[string]bool, len(m.imports)),
+		constants:  make(map[string]bool, len(m.constants)),
+	}
+
+	for _, path := range m.imports {
+		components := strings.Split(path, miden.ModulePathSeparator)
+		for _, c := range components {
+			if !isLabel(c) {
+				return nil, fmt.Errorf("masm: invalid import %q", path)
+			}
+		}
+		alias := components[len(components)-1]
+		if s.modules[alias] {
+			return nil, fmt.Errorf("masm: duplicate import of module %s", alias)
+		}
+		s.modules[alias] = true
+	}
+
+	for _, c := range m.constants {
+		if !isConstantName(c.Name) {
+			return nil, fmt.Errorf("masm: invalid constant name %q", c.Name)
+		}
+		if s.constants[c.Name] {
+			return nil, fmt.Errorf("masm: duplicate constant %s", c.Name)
+		}
+		s.constants[c.Name] = true
+	}
+
+	for _, p := range m.procedures {
+		if !isLabel(p.Name) {
+			return nil, fmt.Errorf("masm: invalid procedure name %q", p.Name)
+		}
+		if s.procedures[p.Name] {
+			return nil, fmt.Errorf("masm: duplicate procedure %s", p.Name)
+		}
+		if p.Locals < 0 || p.Locals > MaxLocals {
+			return nil, fmt.Errorf("masm: procedure %s has %d locals, out of range [0, %d]", p.Name, p.Locals, MaxLocals)
+		}
+		if p.Body.empty() {
+			return nil, fmt.Errorf("masm: empty procedure %s", p.Name)
+		}
+
+		s.name, s.locals = p.Name, p.Locals
+		if err := p.Body.validate(s); err != nil {
+			return nil, err
+		}
+		s.procedures[p.Name] = true
+	}
+
+	return s, nil
+}
+
+// Assembly validates the module and renders it.
+func (m *Module) Assembly() (miden.Assembly, error) {
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+
+	w := &writer{}
+	m.render(w)
+	return []byte(w.sb.String()), nil
+}
+
+func (m *Module) render(w *writer) {
+	section := func() {
+		if w.sb.Len() > 0 {
+			w.line("")
+		}
+	}
+
+	if len(m.imports) > 0 {
+		section()
+		for _, path := range m.imports {
+			w.line("use." + path)
+		}
+	}
+	if len(m.constants) > 0 {
+		section()
+		for _, c := range m.constants {
+			w.line("const." + c.Name + "=" + strconv.FormatUint(c.Value.Uint64(), 10))
+		}
+	}
+	for _, p := range m.procedures {
+		section()
+		keyword := "proc"
+		if p.Export {
+			keyword = "export"
+		}
+		header := keyword + "." + p.Name
+		if p.Locals > 0 {
+			header += "." + strconv.Itoa(p.Locals)
+		}
+		w.line(header)
+		w.block(&p.Body)
+		w.line("end")
+	}
+}
+
+// NewProgram returns a program whose main block is built by main.
+func NewProgram(main func(*Block)) *Program {
+	return &Program{main: build(main)}
+}
+
+// Begin replaces the main block of the program.
+func (p *Program) Begin(main func(*Block)) *Program {
+	p.main = build(main)
+	return p
+}
+
+// Validate checks the program for errors.
+func (p *Program) Validate() error {
+	s, err := p.Module.validate()
+	if err != nil {
+		return err
+	}
+
+	if p.main == nil {
+		return errors.New("masm: program has no main block")
+	}
+	if p.main.empty() {
+		return errors.New("masm: empty main block")
+	}
+	for _, proc := range p.procedures {
+		if proc.Export {
+			return fmt.Errorf("masm: program exports procedure %s", proc.Name)
+		}
+	}
+
+	s.name, s.locals = "main", 0
+	return p.main.validate(s)
+}
+
+// Assembly validates the program and renders it.
+func (p *Program) Assembly() (miden.Assembly, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+
+	w := &writer{}
+	p.render(w)
+	if w.sb.Len() > 0 {
+		w.line("")
+	}
+	w.line("begin")
+	w.block(p.main)
+	w.line("end")
+	return []byte(w.sb.String()), nil
+}
+
+// String returns the rendered program, or the validation error.
+func (p *Program) String() string {
+	a, err := p.Assembly()
+	if err != nil {
+		return err.Error()
+	}
+	return string(a)
+}
+
+// Compile validates and renders the program, and compiles it using the
+// miden.DefaultProgramCache.
+func (p *Program) Compile(ctx context.Context) (*miden.Program, error) {
+	a, err := p.Assembly()
+	if err != nil {
+		return nil, err
+	}
+	return miden.NewProgram(ctx, a)
+}
+
+// Names visible to the code block being validated
+type scope struct {
+	// Enclosing procedure
+	name   string
+	locals int
+	// Procedures defined so far, aliases of imported modules, and constants
+	procedures map[string]bool
+	modules    map[string]bool
+	constants  map[string]bool
+}
+
+func (s *scope) checkInvoke(target string) error {
+	module, name, ok := strings.Cut(target, miden.ModulePathSeparator)
+	if !ok {
+		if !s.procedures[target] {
+			return fmt.Errorf("masm: %s invokes undefined procedure %s", s.name, target)
+		}
+		return nil
+	}
+
+	if !s.modules[module] {
+		return fmt.Errorf("masm: %s invokes %s of a module which is not imported", s.name, target)
+	}
+	if !isLabel(name) {
+		return fmt.Errorf("masm: %s invokes invalid procedure %s", s.name, target)
+	}
+	return nil
+}
+
+func isLabel(s string) bool {
+	if s == "" || len(s) > MaxLabelLength || !unicode.IsLetter(rune(s[0])) {
+		return false
+	}
+	for _, r := range s {
+		if r > unicode.MaxASCII || !(unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_') {
+			return false
+		}
+	}
+	return true
+}
+
+func isConstantName(s string) bool {
+	if s == "" || !unicode.IsUpper(rune(s[0])) {
+		return false
+	}
+	for _, r := range s {
+		if r > unicode.MaxASCII || !(unicode.IsUpper(r) || unicode.IsDigit(r) || r == '_') {
+			return false
+		}
+	}
+	return true
+}
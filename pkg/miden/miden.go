@@ -2,6 +2,7 @@
 package miden
 
 import (
+	"bytes"
 	"context"
 	"encoding/hex"
 	"errors"
@@ -22,6 +23,44 @@ type Proof = []byte
 // Assembly is the representation of Miden VM program
 type Assembly = []byte
 
+// Security levels of proofs generated by Miden VM
+const (
+	Security96  = "96bits"
+	Security128 = "128bits"
+)
+
+// ProofOptions specifies the parameters of proofs generated by Miden VM.  The
+// zero value uses Miden VM defaults.
+type ProofOptions struct {
+	// Security level, Security96 if empty
+	Security string
+	// Generate proofs suitable for recursive verification
+	Recursive bool
+}
+
+func (o ProofOptions) security() string {
+	if o.Security == "" {
+		return Security96
+	}
+	return o.Security
+}
+
+// String returns the canonical representation of the options.
+func (o ProofOptions) String() string {
+	if o.Recursive {
+		return o.security() + ",recursive"
+	}
+	return o.security()
+}
+
+func (o ProofOptions) args(args []string) []string {
+	args = append(args, "--security", o.security())
+	if o.Recursive {
+		args = append(args, "--recursive")
+	}
+	return args
+}
+
 // Version retuns the current version of Miden VM returned as a string.
 func Version(ctx context.Context) (string, error) {
 	out, err := exec.CommandContext(ctx, "miden", "--version").Output()
@@ -31,6 +70,13 @@ func Version(ctx context.Context) (string, error) {
 	return strings.TrimSpace(string(out)), nil
 }
 
+func libraryArgs(args []string, libraryPaths []string) []string {
+	for _, p := range libraryPaths {
+		args = append(args, "--libraries", p)
+	}
+	return args
+}
+
 func extractLine(lines []string, prefix string, suffix string) (string, bool) {
 	outputIndex := slices.IndexFunc[[]string](lines,
 		func(line string) bool { return strings.HasPrefix(line, prefix) })
@@ -88,7 +134,7 @@ func extractHashProve(outLines []string) ([]byte, error) {
 
 // Compile compiles a Miden VM program assembly from a string and returns the program's hash.
 func Compile(ctx context.Context, assembly Assembly) (ProgramHash, error) {
-	d := newTmpDirDriver()
+	d, ctx := newTmpDirDriver(ctx)
 	defer d.cleanup()
 
 	if err := d.setAssembly(assembly); err != nil {
@@ -101,73 +147,47 @@ func Compile(ctx context.Context, assembly Assembly) (ProgramHash, error) {
 // Run compiles a Miden VM program assembly from string and runs it on a specified input.
 // Run returns the program hash and execution output.
 func Run(ctx context.Context, assembly Assembly, input Input) (hash ProgramHash, output Output, err error) {
-	d := newTmpDirDriver()
+	d, ctx := newTmpDirDriver(ctx)
 	defer d.cleanup()
 
 	if err = d.setAssembly(assembly); err != nil {
 		return
 	}
-	if err = d.setInput(input); err != nil {
-		return
-	}
-
-	if hash, err = RunFile(ctx, d.assemblyPath(), d.inputPath(), d.outputPath()); err != nil {
-		return
-	}
 
-	// getting output
-	output, err = d.output()
-	return
+	return d.run(ctx, d.assemblyPath(), input)
 }
 
 // Prove compiles a Miden VM program assembly from string, runs it, and generates a zero-knowledge proof of execution.
 // Prove returns the program hash, execution output, and zero-knowledge proof.
+// If a default ProofCache is set, Prove returns the cached proof if there is one.
 func Prove(ctx context.Context, assembly Assembly, input Input) (hash ProgramHash, output Output, proof Proof, err error) {
-	d := newTmpDirDriver()
+	d, ctx := newTmpDirDriver(ctx)
 	defer d.cleanup()
 
 	if err = d.setAssembly(assembly); err != nil {
 		return
 	}
-	if err = d.setInput(input); err != nil {
-		return
-	}
-
-	if hash, err = ProveFile(ctx, d.assemblyPath(), d.inputPath(), d.outputPath(), d.proofPath()); err != nil {
-		return
-	}
-	if output, err = d.output(); err != nil {
-		return
-	}
 
-	proof, err = d.proof()
-	return
+	return d.prove(ctx, ProofOptions{}, d.assemblyPath(), input)
 }
 
 // Verify checks if the provided zero-knowledge proof matches programHash, input, and output.
-// Verify returns true, nil if verification succeeded.
-// If verification failed, Verify may return a non-nil error.
+// Verify returns true, nil if verification succeeded, and false, nil if Miden VM rejected the proof.
+// If Miden VM could not verify the proof, Verify returns a non-nil error.
 func Verify(ctx context.Context, programHash ProgramHash, proof Proof, input Input, output Output) (r bool, err error) {
-	d := newTmpDirDriver()
+	d, ctx := newTmpDirDriver(ctx)
 	defer d.cleanup()
 
-	if err = d.setInput(input); err != nil {
-		return
-	}
-	if err = d.setOutput(output); err != nil {
-		return
-	}
-	if err = d.setProof(proof); err != nil {
-		return
-	}
-
-	return VerifyFile(ctx, programHash, d.inputPath(), d.outputPath(), d.proofPath())
+	return d.verify(ctx, programHash, proof, input, output)
 }
 
-func CompileFile(ctx context.Context, assemblyPath string) (ProgramHash, error) {
-	cmd := exec.CommandContext(ctx, "miden", "compile", "--assembly", assemblyPath)
+// CompileFile compiles a Miden VM program assembly file, linked against
+// optional .masl library files, and returns the program's hash.
+func CompileFile(ctx context.Context, assemblyPath string, libraryPaths ...string) (ProgramHash, error) {
+	args := libraryArgs([]string{"compile", "--assembly", assemblyPath}, libraryPaths)
+	cmd := exec.CommandContext(ctx, "miden", args...)
 
-	out, err := cmd.Output()
+	out, err := runCommand(ctx, cmd)
 	if err != nil {
 		return nil, err
 	}
@@ -176,35 +196,39 @@ func CompileFile(ctx context.Context, assemblyPath string) (ProgramHash, error)
 	return extractHashCompile(outLines)
 }
 
-func RunFile(ctx context.Context, assemblyPath string, inputPath string, outputPath string) (ProgramHash, error) {
-	cmd := exec.CommandContext(ctx, "miden", "run", "--assembly", assemblyPath, "--input", inputPath, "--output", outputPath)
-
-	out, err := cmd.Output()
-	if err != nil {
-		return nil, err
-	}
-
-	outLines := strings.Split(string(out), "\n")
-	return extractHashRun(outLines)
+// RunFile runs a Miden VM program assembly file, linked against optional
+// .masl library files, and returns the program's hash.
+func RunFile(ctx context.Context, assemblyPath string, inputPath string, outputPath string, libraryPaths ...string) (ProgramHash, error) {
+	hash, _, err := RunFileWithSummary(ctx, assemblyPath, inputPath, outputPath, libraryPaths...)
+	return hash, err
 }
 
-func ProveFile(ctx context.Context, assemblyPath string, inputPath string, outputPath string, proofPath string) (ProgramHash, error) {
-	cmd := exec.CommandContext(ctx, "miden", "prove", "--assembly", assemblyPath, "--input", inputPath, "--output", outputPath, "--proof", proofPath)
-
-	out, err := cmd.Output()
-	if err != nil {
-		return nil, err
-	}
-
-	outLines := strings.Split(string(out), "\n")
+// ProveFile runs and proves a Miden VM program assembly file, linked against
+// optional .masl library files, and returns the program's hash.
+func ProveFile(ctx context.Context, assemblyPath string, inputPath string, outputPath string, proofPath string, libraryPaths ...string) (ProgramHash, error) {
+	return ProveFileWithOptions(ctx, ProofOptions{}, assemblyPath, inputPath, outputPath, proofPath, libraryPaths...)
+}
 
-	return extractHashProve(outLines)
+// ProveFileWithOptions is like ProveFile, but generates the proof using the
+// specified options.
+func ProveFileWithOptions(ctx context.Context, opts ProofOptions, assemblyPath string, inputPath string, outputPath string, proofPath string, libraryPaths ...string) (ProgramHash, error) {
+	hash, _, err := ProveFileWithProgress(ctx, opts, nil, assemblyPath, inputPath, outputPath, proofPath, libraryPaths...)
+	return hash, err
 }
 
+// Printed by Miden VM to stderr when it rejects a proof
+const _verificationFailed = "Program failed verification!"
+
+// VerifyFile is like Verify, but reads the input, output, and proof from
+// files.
 func VerifyFile(ctx context.Context, programHash ProgramHash, inputPath string, outputPath string, proofPath string) (bool, error) {
 	hash := hex.EncodeToString(programHash)
 	cmd := exec.CommandContext(ctx, "miden", "verify", "--program-hash", hash, "--input", inputPath, "--output", outputPath, "--proof", proofPath)
-	_, err := cmd.Output()
+	_, err := runCommand(ctx, cmd)
+	var exitError *exec.ExitError
+	if errors.As(err, &exitError) && bytes.Contains(exitError.Stderr, []byte(_verificationFailed)) {
+		return false, nil
+	}
 	if err != nil {
 		return false, err
 	}
@@ -14,6 +14,7 @@ import (
 
 	field "github.com/qredo/verifiable-oracles/pkg/goldilocks"
 	"github.com/qredo/verifiable-oracles/pkg/miden"
+	"github.com/qredo/verifiable-oracles/pkg/miden/midentest"
 )
 
 var (
@@ -112,6 +113,36 @@ func needsMiden(t *testing.T) {
 	}
 }
 
+// Runs the table tests against the fake CLI when Miden VM is not installed.
+func midenOrFake(t *testing.T) {
+	t.Helper()
+
+	if _testHasMiden {
+		return
+	}
+
+	programs := make([]midentest.Program, 0, len(midenTable))
+	for _, tc := range midenTable {
+		hash, err := hex.DecodeString(tc.hash)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := tc.expected
+		if expected == nil {
+			expected = _defaultOutput
+		}
+		programs = append(programs, midentest.Program{
+			Assembly: tc.assembly(),
+			Hash:     hash,
+			Runs: []midentest.Run{{
+				Input:  tc.inputFile,
+				Output: miden.Output{Stack: expected, OverflowAddrs: field.Vector{}},
+			}},
+		})
+	}
+	midentest.Install(t, midentest.Config{Programs: programs})
+}
+
 func handleExitError(t *testing.T, err error) bool {
 	t.Helper()
 	if err != nil {
@@ -134,7 +165,7 @@ func hashToHex(hash miden.ProgramHash) string {
 }
 
 func TestMidenRun(t *testing.T) {
-	needsMiden(t)
+	midenOrFake(t)
 
 	for name, tc := range midenTable {
 		t.Run(name, func(t *testing.T) {
@@ -158,7 +189,7 @@ func TestMidenRun(t *testing.T) {
 }
 
 func TestMidenCompile(t *testing.T) {
-	needsMiden(t)
+	midenOrFake(t)
 
 	for name, tc := range midenTable {
 		t.Run(name, func(t *testing.T) {
@@ -177,7 +208,7 @@ func TestMidenCompile(t *testing.T) {
 }
 
 func TestMidenProve(t *testing.T) {
-	needsMiden(t)
+	midenOrFake(t)
 
 	for name, tc := range midenTable {
 		t.Run(name, func(t *testing.T) {
@@ -201,7 +232,7 @@ func TestMidenProve(t *testing.T) {
 }
 
 func TestMidenVerify(t *testing.T) {
-	needsMiden(t)
+	midenOrFake(t)
 
 	for name, tc := range midenTable {
 		t.Run(name, func(t *testing.T) {
@@ -312,3 +343,87 @@ func TestMidenVerifyFile(t *testing.T) {
 		}
 	}
 }
+
+func TestMidenRunOverflow(t *testing.T) {
+	needsMiden(t)
+
+	var (
+		assert = assert.New(t)
+
+		input    miden.Input
+		expected miden.Output
+	)
+
+	data, err := os.ReadFile("testdata/overflow/input.json")
+	assert.Nil(err)
+	assert.Nil(json.Unmarshal(data, &input))
+
+	data, err = os.ReadFile("testdata/overflow/output.json")
+	assert.Nil(err)
+	assert.Nil(json.Unmarshal(data, &expected))
+
+	assembly, err := os.ReadFile("testdata/overflow/overflow.masm")
+	assert.Nil(err)
+
+	_, output, err := miden.Run(context.Background(), assembly, input)
+	if handleExitError(t, err) {
+		assert.Equal(expected, output)
+	}
+}
+
+func TestMidenTrace(t *testing.T) {
+	needsMiden(t)
+
+	for name, tc := range midenTable {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			trace, err := miden.Trace(context.Background(), tc.assembly(), tc.inputFile, 0)
+			if handleExitError(t, err) {
+				assert.Equal(tc.hash, hashToHex(trace.Hash))
+				assert.Nil(trace.Err)
+				assert.False(trace.Truncated)
+				assert.NotEmpty(trace.Steps)
+				assert.Equal(uint32(0), trace.Steps[0].Clk)
+				assert.Positive(trace.Cycles())
+			}
+		})
+	}
+}
+
+func TestMidenTrace_Truncated(t *testing.T) {
+	needsMiden(t)
+
+	trace, err := miden.Trace(context.Background(), midenTable["add one to two"].assembly(), midenTable["add one to two"].inputFile, 2)
+	if handleExitError(t, err) {
+		assert.True(t, trace.Truncated)
+		assert.Len(t, trace.Steps, 2)
+	}
+}
+
+func TestMidenTrace_Error(t *testing.T) {
+	needsMiden(t)
+
+	assembly := midenTable["assert"].assembly()
+	trace, err := miden.Trace(context.Background(), assembly, miden.Input{}, 0)
+	if handleExitError(t, err) {
+		assert.Error(t, trace.Err)
+	}
+}
+
+func TestMidenRunWithSummary(t *testing.T) {
+	midenOrFake(t)
+
+	for name, tc := range midenTable {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			hash, _, summary, err := miden.RunWithSummary(context.Background(), tc.assembly(), tc.inputFile)
+			if handleExitError(t, err) {
+				assert.Equal(tc.hash, hashToHex(hash))
+				assert.Positive(summary.TraceLength)
+				assert.Zero(summary.TraceLength & (summary.TraceLength - 1))
+			}
+		})
+	}
+}
@@ -0,0 +1,264 @@
+// Command fakemiden emulates the Miden VM CLI for the programs registered in
+// the midentest configuration file named by FAKE_MIDEN_CONFIG.
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/qredo/verifiable-oracles/pkg/miden"
+	"github.com/qredo/verifiable-oracles/pkg/miden/midentest"
+)
+
+// Repeated string flag, e.g. --libraries a.masl --libraries b.masl
+type stringsFlag []string
+
+func (f *stringsFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *stringsFlag) Set(s string) error {
+	*f = append(*f, s)
+	return nil
+}
+
+type command struct {
+	flags *flag.FlagSet
+
+	assembly, input, output, proof string
+	programHash, security          string
+	recursive                      bool
+	libraries                      stringsFlag
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		fail(errors.New("missing command"))
+	}
+	if os.Args[1] == "--version" {
+		fmt.Println(midentest.Version)
+		return
+	}
+
+	name := os.Args[1]
+	if !_commands[name] {
+		unexpectedArgument(name)
+	}
+
+	config, err := midentest.ReadConfig()
+	if err != nil {
+		fail(err)
+	}
+
+	if msg, ok := config.Fail[name]; ok {
+		fail(errors.New(msg))
+	}
+
+	c := &command{flags: flag.NewFlagSet(name, flag.ContinueOnError)}
+	c.flags.StringVar(&c.assembly, "assembly", "", "")
+	c.flags.StringVar(&c.input, "input", "", "")
+	c.flags.StringVar(&c.output, "output", "", "")
+	c.flags.StringVar(&c.proof, "proof", "", "")
+	c.flags.StringVar(&c.programHash, "program-hash", "", "")
+	c.flags.StringVar(&c.security, "security", miden.Security96, "")
+	c.flags.BoolVar(&c.recursive, "recursive", false, "")
+	c.flags.Var(&c.libraries, "libraries", "")
+	if err = c.flags.Parse(os.Args[2:]); err != nil {
+		fail(err)
+	}
+
+	switch name {
+	case "compile":
+		err = c.compile(config)
+	case "run":
+		err = c.run(config)
+	case "prove":
+		err = c.prove(config)
+	case "verify":
+		err = c.verify(config)
+	default:
+		err = fmt.Errorf("unsupported command %s", name)
+	}
+	if err != nil {
+		fail(err)
+	}
+}
+
+// Subcommands of Miden VM 0.6; the fake CLI emulates only some of them
+var _commands = map[string]bool{
+	"analyze": true,
+	"compile": true,
+	"debug":   true,
+	"example": true,
+	"help":    true,
+	"prove":   true,
+	"repl":    true,
+	"run":     true,
+	"verify":  true,
+}
+
+// Fails as the argument parser of Miden VM does on an unknown subcommand.
+func unexpectedArgument(arg string) {
+	fmt.Fprintf(os.Stderr, "error: Found argument '%s' which wasn't expected, or isn't valid in this context\n\nUSAGE:\n    miden <SUBCOMMAND>\n\nFor more information try --help\n", arg)
+	os.Exit(1)
+}
+
+func fail(err error) {
+	fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+	os.Exit(1)
+}
+
+func (c *command) program(config *midentest.Config) (*midentest.Program, error) {
+	for _, l := range c.libraries {
+		if _, err := os.ReadFile(l); err != nil {
+			return nil, err
+		}
+	}
+
+	assembly, err := os.ReadFile(c.assembly)
+	if err != nil {
+		return nil, err
+	}
+	if p, ok := config.Lookup(assembly); ok {
+		return p, nil
+	}
+	return nil, errors.New("failed to compile program: unknown program")
+}
+
+func (c *command) execute(config *midentest.Config) (*midentest.Program, *midentest.Run, miden.Input, error) {
+	var input miden.Input
+
+	p, err := c.program(config)
+	if err != nil {
+		return nil, nil, input, err
+	}
+	if err = readJSON(c.input, &input); err != nil {
+		return nil, nil, input, err
+	}
+
+	r, ok := p.Lookup(input)
+	if !ok {
+		return nil, nil, input, errors.New("failed to execute program: unknown input")
+	}
+	time.Sleep(r.Delay)
+	if r.Err != "" {
+		return nil, nil, input, fmt.Errorf("failed to execute program: %s", r.Err)
+	}
+	if err = writeJSON(c.output, r.Output); err != nil {
+		return nil, nil, input, err
+	}
+	return p, r, input, nil
+}
+
+func (c *command) compile(config *midentest.Config) error {
+	p, err := c.program(config)
+	if err != nil {
+		return err
+	}
+	time.Sleep(p.Delay)
+
+	fmt.Println("Compiling program... done (0 ms)")
+	fmt.Printf("program hash is %s\n", hex.EncodeToString(p.Hash))
+	return nil
+}
+
+func (c *command) run(config *midentest.Config) error {
+	p, r, _, err := c.execute(config)
+	if err != nil {
+		return err
+	}
+
+	steps := r.Steps
+	if steps == 0 {
+		steps = midentest.DefaultSteps
+	}
+	fmt.Printf("Executing program with hash %s... done (%d steps in 0 ms)\n", hex.EncodeToString(p.Hash), steps)
+	return nil
+}
+
+func (c *command) prove(config *midentest.Config) error {
+	p, r, input, err := c.execute(config)
+	if err != nil {
+		return err
+	}
+
+	opts := miden.ProofOptions{Security: c.security, Recursive: c.recursive}
+	proof, err := midentest.FakeProof(p.Hash, input, r.Output, opts)
+	if err != nil {
+		return err
+	}
+	if err = os.WriteFile(c.proof, proof, 0644); err != nil {
+		return err
+	}
+
+	steps := r.Steps
+	if steps == 0 {
+		steps = midentest.DefaultSteps
+	}
+
+	fmt.Printf("Proving program with hash %s...\n", hex.EncodeToString(p.Hash))
+	fmt.Fprintf(os.Stderr, "Generated execution trace of 72 columns and %d steps in 0 ms\n", steps)
+	fmt.Printf("Program with hash %s proved in 0 ms\n", hex.EncodeToString(p.Hash))
+	fmt.Printf("Writing data to proof file - size %d KB\n", len(proof)/1024)
+	return nil
+}
+
+func (c *command) verify(config *midentest.Config) error {
+	hash, err := hex.DecodeString(c.programHash)
+	if err != nil {
+		return err
+	}
+
+	var (
+		input  miden.Input
+		output miden.Output
+	)
+	if err = readJSON(c.input, &input); err != nil {
+		return err
+	}
+	if err = readJSON(c.output, &output); err != nil {
+		return err
+	}
+	proof, err := os.ReadFile(c.proof)
+	if err != nil {
+		return err
+	}
+	if p, ok := config.LookupHash(hash); ok {
+		if r, ok := p.Lookup(input); ok {
+			time.Sleep(r.Delay)
+		}
+	}
+
+	ok, err := midentest.CheckProof(hash, input, output, proof)
+	if err != nil {
+		return fmt.Errorf("Program failed verification! - %w", err)
+	}
+	if !ok {
+		return errors.New("Program failed verification! - inconsistent proof")
+	}
+
+	fmt.Println("Verification complete in 0 ms")
+	return nil
+}
+
+func readJSON(name string, v any) error {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func writeJSON(name string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(name, data, 0644)
+}
@@ -0,0 +1,232 @@
+// Package midentest provides a fake Miden VM CLI for testing code depending
+// on package miden without installing Miden VM.
+//
+// The fake CLI emulates the compile, run, prove, and verify commands for
+// registered programs, and rejects subcommands unknown to Miden VM as Miden VM
+// does.  Proofs are deterministic digests of the program hash, input, output,
+// and proof options, so they verify exactly when the real proofs would.
+package midentest
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/qredo/verifiable-oracles/pkg/miden"
+)
+
+// ConfigEnv is the environment variable pointing the fake CLI to its
+// configuration file.
+const ConfigEnv = "FAKE_MIDEN_CONFIG"
+
+// Version is reported by the fake CLI for --version.
+const Version = "miden 0.6.0-fake"
+
+// DefaultSteps is the trace length reported for runs not specifying one.
+const DefaultSteps = 1024
+
+// Config configures the fake CLI.
+type Config struct {
+	Programs []Program `json:"programs"`
+	// Commands failing regardless of their arguments, mapped to the error
+	// message printed to stderr
+	Fail map[string]string `json:"fail,omitempty"`
+}
+
+// Program is a program known to the fake CLI.  Programs are matched by their
+// assembly, ignoring leading and trailing white space.
+type Program struct {
+	Assembly miden.Assembly    `json:"assembly"`
+	Hash     miden.ProgramHash `json:"hash"`
+	Runs     []Run             `json:"runs"`
+	// Time taken to compile the program
+	Delay time.Duration `json:"delay,omitempty"`
+}
+
+// Run is an execution of a program on an input.
+type Run struct {
+	Input  miden.Input  `json:"input"`
+	Output miden.Output `json:"output"`
+	// Trace length, DefaultSteps if zero
+	Steps int `json:"steps,omitempty"`
+	// Execution error, making run and prove fail
+	Err string `json:"err,omitempty"`
+	// Time taken to run, prove, or verify the run
+	Delay time.Duration `json:"delay,omitempty"`
+}
+
+// Lookup returns the program with the specified assembly.
+func (c *Config) Lookup(assembly miden.Assembly) (*Program, bool) {
+	for i := range c.Programs {
+		if bytes.Equal(bytes.TrimSpace(c.Programs[i].Assembly), bytes.TrimSpace(assembly)) {
+			return &c.Programs[i], true
+		}
+	}
+	return nil, false
+}
+
+// LookupHash returns the program with the specified hash.
+func (c *Config) LookupHash(hash miden.ProgramHash) (*Program, bool) {
+	for i := range c.Programs {
+		if bytes.Equal(c.Programs[i].Hash, hash) {
+			return &c.Programs[i], true
+		}
+	}
+	return nil, false
+}
+
+// Lookup returns the run of the program on input.
+func (p *Program) Lookup(input miden.Input) (*Run, bool) {
+	want, err := json.Marshal(input)
+	if err != nil {
+		return nil, false
+	}
+	for i := range p.Runs {
+		got, err := json.Marshal(p.Runs[i].Input)
+		if err == nil && bytes.Equal(want, got) {
+			return &p.Runs[i], true
+		}
+	}
+	return nil, false
+}
+
+// ReadConfig reads the configuration file named by ConfigEnv.
+func ReadConfig() (*Config, error) {
+	name := os.Getenv(ConfigEnv)
+	if name == "" {
+		return nil, fmt.Errorf("fake miden: %s not set", ConfigEnv)
+	}
+
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &Config{}
+	if err = json.Unmarshal(data, config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+const _proofPrefix = "fake-miden-proof:"
+
+// FakeProof returns the proof the fake CLI generates for a run of the
+// program.
+func FakeProof(hash miden.ProgramHash, input miden.Input, output miden.Output, opts miden.ProofOptions) (miden.Proof, error) {
+	digest, err := proofDigest(hash, input, output, opts.String())
+	if err != nil {
+		return nil, err
+	}
+	return []byte(_proofPrefix + opts.String() + ":" + digest), nil
+}
+
+// CheckProof reports whether proof is a fake proof of the program run.
+func CheckProof(hash miden.ProgramHash, input miden.Input, output miden.Output, proof miden.Proof) (bool, error) {
+	rest, ok := strings.CutPrefix(string(proof), _proofPrefix)
+	if !ok {
+		return false, errors.New("fake miden: invalid proof")
+	}
+	opts, digest, ok := strings.Cut(rest, ":")
+	if !ok {
+		return false, errors.New("fake miden: invalid proof")
+	}
+
+	want, err := proofDigest(hash, input, output, opts)
+	return err == nil && want == digest, err
+}
+
+func proofDigest(hash miden.ProgramHash, input miden.Input, output miden.Output, opts string) (string, error) {
+	inputData, err := json.Marshal(input)
+	if err != nil {
+		return "", err
+	}
+	outputData, err := json.Marshal(output)
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.New()
+	for _, data := range [][]byte{hash, inputData, outputData, []byte(opts)} {
+		h.Write(data)
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+var (
+	_buildMu  sync.Mutex
+	_buildDir string
+)
+
+// Build compiles the fake CLI once per process and returns the directory
+// holding the miden executable.  The directory is removed by Main.
+func Build(t testing.TB) string {
+	t.Helper()
+
+	_buildMu.Lock()
+	defer _buildMu.Unlock()
+
+	if _buildDir != "" {
+		return _buildDir
+	}
+
+	dir, err := os.MkdirTemp("", "fake-miden*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmd := exec.Command("go", "build", "-o", path.Join(dir, "miden"),
+		"github.com/qredo/verifiable-oracles/pkg/miden/midentest/fakemiden")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		os.RemoveAll(dir)
+		t.Fatalf("fake miden: build failed: %v\n%s", err, out)
+	}
+
+	_buildDir = dir
+	return _buildDir
+}
+
+// Main runs the tests of a package installing the fake CLI, then removes the
+// fake CLI built for them.  Call it from TestMain.
+func Main(m *testing.M) {
+	code := m.Run()
+
+	_buildMu.Lock()
+	if _buildDir != "" {
+		os.RemoveAll(_buildDir)
+		_buildDir = ""
+	}
+	_buildMu.Unlock()
+
+	os.Exit(code)
+}
+
+// Install puts the fake CLI configured by config first on PATH for the
+// duration of the test.
+func Install(t testing.TB, config Config) {
+	t.Helper()
+
+	dir := Build(t)
+
+	data, err := json.Marshal(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	name := path.Join(t.TempDir(), "fake-miden.json")
+	if err = os.WriteFile(name, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv(ConfigEnv, name)
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
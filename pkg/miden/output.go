@@ -2,29 +2,150 @@ package miden
 
 import (
 	"encoding/json"
+	"errors"
 
 	field "github.com/qredo/verifiable-oracles/pkg/goldilocks"
 )
 
+// StackTopSize is the number of operand stack elements that Miden VM keeps
+// outside of the overflow table.
+const StackTopSize = 16
+
 // Output holds the output of running a Miden VM program
+//
+// Stack contains the whole operand stack at the end of the execution, top
+// first: the top StackTopSize elements followed by the values stored in the
+// overflow table, starting with the most recently added row.
+//
+// OverflowAddrs contains the addresses needed to reconstruct the overflow
+// table: the prev address of the deepest row, followed by the address (clock
+// cycle) of each row, deepest first.  It is empty when the stack did not
+// overflow.
 type Output struct {
 	Stack         field.Vector `json:"stack"`
-	OverflowAddrs []string     `json:"overflow_addrs"`
+	OverflowAddrs field.Vector `json:"overflow_addrs"`
+}
+
+// OverflowRow is a single row of the Miden VM stack overflow table.
+type OverflowRow struct {
+	Addr  field.Element
+	Value field.Element
 }
 
 func (f Output) MarshalJSON() ([]byte, error) {
 	data := make(map[string]any, 2)
 
 	data["stack"] = marshalVector(f.Stack)
-	if len(f.OverflowAddrs) > 0 {
-		data["overflow_addrs"] = f.OverflowAddrs
-	} else {
-		data["overflow_addrs"] = []string{}
-	}
+	data["overflow_addrs"] = marshalVector(f.OverflowAddrs)
 
 	return json.Marshal(data)
 }
 
+// Need to explicitly implement json.Unmarshaler, as Miden encodes field
+// elements as decimal strings.
+func (f *Output) UnmarshalJSON(data []byte) error {
+	var (
+		raw struct {
+			Stack         []string `json:"stack"`
+			OverflowAddrs []string `json:"overflow_addrs"`
+		}
+		output Output
+		err    error
+	)
+
+	if err = json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if output.Stack, err = unmarshalVector(raw.Stack); err != nil {
+		return err
+	}
+	if output.OverflowAddrs, err = unmarshalVector(raw.OverflowAddrs); err != nil {
+		return err
+	}
+
+	*f = output
+	return nil
+}
+
+// StackTop returns the top StackTopSize elements of the stack, top first.
+func (f Output) StackTop() field.Vector {
+	return f.Stack[:min(len(f.Stack), StackTopSize)]
+}
+
+// HasOverflow reports whether the overflow table was non-empty at the end of
+// the execution.
+func (f Output) HasOverflow() bool {
+	return len(f.OverflowAddrs) != 0
+}
+
+// OverflowPrev returns the prev address of the deepest row in the overflow
+// table, or zero if the table is empty.
+func (f Output) OverflowPrev() field.Element {
+	if !f.HasOverflow() {
+		return field.Element{}
+	}
+	return f.OverflowAddrs[0]
+}
+
+// StackOverflow returns the rows of the overflow table in the order in which
+// they were added to the table, i.e. deepest stack element first.
+func (f Output) StackOverflow() ([]OverflowRow, error) {
+	if err := f.checkOverflow(); err != nil {
+		return nil, err
+	}
+	if !f.HasOverflow() {
+		return nil, nil
+	}
+
+	addrs := f.OverflowAddrs[1:]
+	rows := make([]OverflowRow, len(addrs))
+	for i := range addrs {
+		rows[i] = OverflowRow{
+			Addr:  addrs[i],
+			Value: f.Stack[len(f.Stack)-1-i],
+		}
+	}
+	return rows, nil
+}
+
+// FullStack reconstructs the logical operand stack, top first, from the top
+// of the stack and the rows of the overflow table.
+func (f Output) FullStack() (field.Vector, error) {
+	rows, err := f.StackOverflow()
+	if err != nil {
+		return nil, err
+	}
+
+	top := f.StackTop()
+	stack := make(field.Vector, len(top), len(top)+len(rows))
+	copy(stack, top)
+	for i := len(rows) - 1; i >= 0; i-- {
+		stack = append(stack, rows[i].Value)
+	}
+	return stack, nil
+}
+
+// Checks that Stack and OverflowAddrs describe the same overflow table.
+func (f Output) checkOverflow() error {
+	overflow := max(len(f.Stack)-StackTopSize, 0)
+
+	if !f.HasOverflow() {
+		if overflow != 0 {
+			return errors.New("miden: stack overflows but overflow addresses are missing")
+		}
+		return nil
+	}
+
+	if len(f.Stack) < StackTopSize {
+		return errors.New("miden: overflow addresses present but stack top is incomplete")
+	}
+	if len(f.OverflowAddrs) != overflow+1 {
+		return errors.New("miden: overflow addresses do not match stack depth")
+	}
+	return nil
+}
+
 // Type Assertions
 var _ json.Marshaler = (*Output)(nil)
 var _ json.Marshaler = Output{}
+var _ json.Unmarshaler = (*Output)(nil)
@@ -28,11 +28,29 @@ var _outputUnmarshalTable = map[string]struct {
 		data: `{"overflow_addrs":[], "stack":["1", "2", "3"]}`,
 		want: miden.Output{
 			Stack:         field.Vector{field.NewElement(1), field.NewElement(2), field.NewElement(3)},
-			OverflowAddrs: []string{},
+			OverflowAddrs: field.Vector{},
+		},
+	},
+	"non empty overflow addresses": {
+		data: `{"overflow_addrs":["0", "18446744069414584320"], "stack":["1"]}`,
+		want: miden.Output{
+			Stack:         field.Vector{field.NewElement(1)},
+			OverflowAddrs: field.Vector{{}, field.NewElement(18446744069414584320)},
 		},
 	},
 }
 
+var _outputUnmarshalErrorTable = map[string]string{
+	"not a number":         `{"stack":["one"]}`,
+	"not a string":         `{"stack":[1]}`,
+	"invalid overflow":     `{"overflow_addrs":["0x"]}`,
+	"stack is not a slice": `{"stack":"1"}`,
+	"modulus":              `{"stack":["18446744069414584321"]}`,
+	"negative":             `{"stack":["-1"]}`,
+	"hexadecimal":          `{"stack":["0x10"]}`,
+	"leading zero":         `{"overflow_addrs":["01"]}`,
+}
+
 var _outputMarshalTable = map[string]struct {
 	data miden.Output
 	want string
@@ -46,6 +64,58 @@ var _outputMarshalTable = map[string]struct {
 		},
 		want: `{"overflow_addrs":[],"stack":["1","2","3"]}`,
 	},
+	"overflow addresses": {
+		data: miden.Output{
+			Stack:         field.Vector{field.NewElement(1)},
+			OverflowAddrs: field.Vector{{}, field.NewElement(18446744069414584320)},
+		},
+		want: `{"overflow_addrs":["0","18446744069414584320"],"stack":["1"]}`,
+	},
+}
+
+var _outputTestDataTable = map[string]struct {
+	path      string
+	top       field.Vector
+	overflow  []miden.OverflowRow
+	fullStack field.Vector
+}{
+	"no overflow": {
+		path:      "testdata/output.json",
+		top:       out(),
+		fullStack: out(),
+	},
+	"overflow": {
+		path: "testdata/overflow/output.json",
+		top:  descending(18, 3),
+		overflow: []miden.OverflowRow{
+			{Addr: field.NewElement(18446744069414584319), Value: field.NewElement(1)},
+			{Addr: field.NewElement(18446744069414584320), Value: field.NewElement(2)},
+		},
+		fullStack: descending(18, 1),
+	},
+}
+
+var _outputInconsistentTable = map[string]miden.Output{
+	"missing overflow addresses": {
+		Stack: make(field.Vector, 17),
+	},
+	"too many overflow addresses": {
+		Stack:         make(field.Vector, 17),
+		OverflowAddrs: make(field.Vector, 3),
+	},
+	"incomplete stack top": {
+		Stack:         make(field.Vector, 3),
+		OverflowAddrs: make(field.Vector, 2),
+	},
+}
+
+// Returns elements from down to to, inclusive
+func descending(from, to uint64) field.Vector {
+	var v field.Vector
+	for i := from; i >= to; i-- {
+		v = append(v, field.NewElement(i))
+	}
+	return v
 }
 
 func TestOutputJsonUnmarshal(t *testing.T) {
@@ -61,6 +131,17 @@ func TestOutputJsonUnmarshal(t *testing.T) {
 	}
 }
 
+func TestOutputJsonUnmarshal_Error(t *testing.T) {
+	for name, data := range _outputUnmarshalErrorTable {
+		t.Run(name, func(t *testing.T) {
+			var out miden.Output
+
+			err := json.Unmarshal([]byte(data), &out)
+			assert.Error(t, err)
+		})
+	}
+}
+
 func TestOutputJsonMarshal(t *testing.T) {
 	for name, tc := range _outputMarshalTable {
 		t.Run(name, func(t *testing.T) {
@@ -72,13 +153,56 @@ func TestOutputJsonMarshal(t *testing.T) {
 	}
 }
 
+func TestOutputJsonRoundTrip(t *testing.T) {
+	for name, tc := range _outputMarshalTable {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+			var out miden.Output
+
+			data, err := json.Marshal(tc.data)
+			assert.Nil(err)
+
+			err = json.Unmarshal(data, &out)
+			assert.Nil(err)
+			assert.Equal(tc.data.Stack.String(), out.Stack.String())
+			assert.Equal(tc.data.OverflowAddrs.String(), out.OverflowAddrs.String())
+		})
+	}
+}
+
 func TestOutputTestData(t *testing.T) {
-	assert := assert.New(t)
-	var out miden.Output
+	for name, tc := range _outputTestDataTable {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+			var out miden.Output
+
+			data, err := os.ReadFile(tc.path)
+			assert.Nil(err)
 
-	data, err := os.ReadFile("testdata/output.json")
-	assert.Nil(err)
+			err = json.Unmarshal(data, &out)
+			assert.Nil(err)
+			assert.Equal(tc.top, out.StackTop())
+			assert.Equal(tc.overflow != nil, out.HasOverflow())
 
-	err = json.Unmarshal(data, &out)
-	assert.Nil(err)
+			overflow, err := out.StackOverflow()
+			assert.Nil(err)
+			assert.Equal(tc.overflow, overflow)
+
+			fullStack, err := out.FullStack()
+			assert.Nil(err)
+			assert.Equal(tc.fullStack, fullStack)
+		})
+	}
+}
+
+func TestOutputInconsistentOverflow(t *testing.T) {
+	for name, out := range _outputInconsistentTable {
+		t.Run(name, func(t *testing.T) {
+			_, err := out.StackOverflow()
+			assert.Error(t, err)
+
+			_, err = out.FullStack()
+			assert.Error(t, err)
+		})
+	}
 }
@@ -0,0 +1,181 @@
+package miden
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrQueueFull is returned when a job is submitted to a ProverPool whose
+	// queue is full.
+	ErrQueueFull = errors.New("miden: prover queue is full")
+	// ErrPoolClosed is returned when a job is submitted to a closed
+	// ProverPool.
+	ErrPoolClosed = errors.New("miden: prover pool is closed")
+)
+
+// ProveResult holds the result of a proving job.
+type ProveResult struct {
+	Hash   ProgramHash
+	Output Output
+	Proof  Proof
+	Err    error
+}
+
+// PoolStats holds statistics of a ProverPool.
+type PoolStats struct {
+	Queued    int
+	Running   int
+	Completed int
+	Failed    int
+	// Jobs cancelled while queued, which never ran
+	Canceled int
+	// Average duration of the jobs that ran (completed or failed)
+	AverageDuration time.Duration
+}
+
+type proveFunc func(ctx context.Context, assembly Assembly, input Input) (ProgramHash, Output, Proof, error)
+
+type proveJob struct {
+	ctx      context.Context
+	assembly Assembly
+	input    Input
+	result   chan ProveResult
+}
+
+// ProverPool bounds the number of concurrently running Miden VM provers.
+// Jobs are queued in a bounded queue and executed by a fixed number of
+// workers.  ProverPool is safe for concurrent use.
+type ProverPool struct {
+	prove proveFunc
+	jobs  chan proveJob
+	wg    sync.WaitGroup
+
+	mu            sync.Mutex
+	closed        bool
+	stats         PoolStats
+	totalDuration time.Duration
+}
+
+// NewProverPool starts a ProverPool with the specified number of workers and
+// queue size.  Both are at least one.
+func NewProverPool(workers int, queueSize int) *ProverPool {
+	return newProverPool(workers, queueSize, Prove)
+}
+
+func newProverPool(workers int, queueSize int, prove proveFunc) *ProverPool {
+	p := &ProverPool{
+		prove: prove,
+		jobs:  make(chan proveJob, max(queueSize, 1)),
+	}
+
+	for i := 0; i < max(workers, 1); i++ {
+		p.wg.Add(1)
+		go p.worker()
+	}
+	return p
+}
+
+// Submit queues a proving job and returns a channel receiving its result.
+// Submit does not block: if the queue is full, it returns ErrQueueFull.
+//
+// Cancelling ctx cancels the job, killing the prover process if the job is
+// already running.
+func (p *ProverPool) Submit(ctx context.Context, assembly Assembly, input Input) (<-chan ProveResult, error) {
+	job := proveJob{
+		ctx:      ctx,
+		assembly: assembly,
+		input:    input,
+		result:   make(chan ProveResult, 1),
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return nil, ErrPoolClosed
+	}
+
+	select {
+	case p.jobs <- job:
+		p.stats.Queued++
+		return job.result, nil
+	default:
+		return nil, ErrQueueFull
+	}
+}
+
+// Prove submits a proving job and waits for its result.  See Submit.
+func (p *ProverPool) Prove(ctx context.Context, assembly Assembly, input Input) (ProgramHash, Output, Proof, error) {
+	result, err := p.Submit(ctx, assembly, input)
+	if err != nil {
+		return nil, Output{}, nil, err
+	}
+
+	select {
+	case r := <-result:
+		return r.Hash, r.Output, r.Proof, r.Err
+	case <-ctx.Done():
+		return nil, Output{}, nil, ctx.Err()
+	}
+}
+
+// Stats returns a snapshot of the pool statistics.
+func (p *ProverPool) Stats() PoolStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.stats
+}
+
+// Close stops accepting new jobs and waits for the queued jobs to finish.
+func (p *ProverPool) Close() {
+	p.mu.Lock()
+	if !p.closed {
+		p.closed = true
+		close(p.jobs)
+	}
+	p.mu.Unlock()
+
+	p.wg.Wait()
+}
+
+func (p *ProverPool) worker() {
+	defer p.wg.Done()
+
+	for job := range p.jobs {
+		job.result <- p.run(job)
+	}
+}
+
+func (p *ProverPool) run(job proveJob) (r ProveResult) {
+	p.mu.Lock()
+	p.stats.Queued--
+	// Skip jobs cancelled while waiting in the queue
+	if r.Err = job.ctx.Err(); r.Err != nil {
+		p.stats.Canceled++
+		p.mu.Unlock()
+		return r
+	}
+	p.stats.Running++
+	p.mu.Unlock()
+
+	start := time.Now()
+	r.Hash, r.Output, r.Proof, r.Err = p.prove(job.ctx, job.assembly, job.input)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.stats.Running--
+	if r.Err != nil {
+		p.stats.Failed++
+	} else {
+		p.stats.Completed++
+	}
+	p.totalDuration += time.Since(start)
+	p.stats.AverageDuration = p.totalDuration / time.Duration(p.stats.Completed+p.stats.Failed)
+
+	return r
+}
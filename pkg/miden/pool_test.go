@@ -0,0 +1,135 @@
+package miden
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var errTestProve = errors.New("prove failed")
+
+// Prover blocking until released, failing for empty assemblies
+func blockingProve(release <-chan struct{}) proveFunc {
+	return func(ctx context.Context, assembly Assembly, input Input) (ProgramHash, Output, Proof, error) {
+		select {
+		case <-release:
+		case <-ctx.Done():
+			return nil, Output{}, nil, ctx.Err()
+		}
+
+		if len(assembly) == 0 {
+			return nil, Output{}, nil, errTestProve
+		}
+		return ProgramHash{1}, Output{}, Proof{2}, nil
+	}
+}
+
+// Waits until the pool stats satisfy cond
+func waitStats(t *testing.T, p *ProverPool, cond func(PoolStats) bool) {
+	t.Helper()
+
+	for i := 0; i < 100; i++ {
+		if cond(p.Stats()) {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("unexpected stats %+v", p.Stats())
+}
+
+func TestProverPool_Prove(t *testing.T) {
+	assert := assert.New(t)
+
+	release := make(chan struct{})
+	close(release)
+	p := newProverPool(2, 2, blockingProve(release))
+	defer p.Close()
+
+	hash, _, proof, err := p.Prove(context.Background(), Assembly("begin end"), Input{})
+	assert.Nil(err)
+	assert.Equal(ProgramHash{1}, hash)
+	assert.Equal(Proof{2}, proof)
+
+	_, _, _, err = p.Prove(context.Background(), nil, Input{})
+	assert.ErrorIs(err, errTestProve)
+
+	stats := p.Stats()
+	assert.Equal(1, stats.Completed)
+	assert.Equal(1, stats.Failed)
+	assert.Equal(0, stats.Queued)
+	assert.Equal(0, stats.Running)
+}
+
+func TestProverPool_QueueFull(t *testing.T) {
+	assert := assert.New(t)
+
+	release := make(chan struct{})
+	p := newProverPool(1, 1, blockingProve(release))
+
+	running, err := p.Submit(context.Background(), Assembly("begin end"), Input{})
+	assert.Nil(err)
+	waitStats(t, p, func(s PoolStats) bool { return s.Running == 1 })
+
+	queued, err := p.Submit(context.Background(), Assembly("begin end"), Input{})
+	assert.Nil(err)
+	assert.Equal(1, p.Stats().Queued)
+
+	_, err = p.Submit(context.Background(), Assembly("begin end"), Input{})
+	assert.ErrorIs(err, ErrQueueFull)
+
+	close(release)
+	assert.Nil((<-running).Err)
+	assert.Nil((<-queued).Err)
+
+	p.Close()
+	_, err = p.Submit(context.Background(), Assembly("begin end"), Input{})
+	assert.ErrorIs(err, ErrPoolClosed)
+}
+
+func TestProverPool_Cancel(t *testing.T) {
+	assert := assert.New(t)
+
+	release := make(chan struct{})
+	p := newProverPool(1, 1, blockingProve(release))
+	defer p.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	result, err := p.Submit(ctx, Assembly("begin end"), Input{})
+	assert.Nil(err)
+	waitStats(t, p, func(s PoolStats) bool { return s.Running == 1 })
+
+	cancel()
+	assert.ErrorIs((<-result).Err, context.Canceled)
+	assert.Equal(1, p.Stats().Failed)
+}
+
+func TestProverPool_CancelQueued(t *testing.T) {
+	assert := assert.New(t)
+
+	release := make(chan struct{})
+	p := newProverPool(1, 1, blockingProve(release))
+	defer p.Close()
+
+	running, err := p.Submit(context.Background(), Assembly("begin end"), Input{})
+	assert.Nil(err)
+	waitStats(t, p, func(s PoolStats) bool { return s.Running == 1 })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	queued, err := p.Submit(ctx, Assembly("begin end"), Input{})
+	assert.Nil(err)
+	cancel()
+
+	close(release)
+	assert.Nil((<-running).Err)
+	assert.ErrorIs((<-queued).Err, context.Canceled)
+
+	stats := p.Stats()
+	assert.Equal(1, stats.Completed)
+	assert.Equal(0, stats.Failed)
+	assert.Equal(1, stats.Canceled)
+	assert.Equal(0, stats.Queued)
+	assert.Equal(0, stats.Running)
+}
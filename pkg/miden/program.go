@@ -0,0 +1,319 @@
+package miden
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path"
+	"slices"
+	"sync"
+)
+
+var (
+	// ErrProgramHashMismatch is returned when Miden VM reports a different
+	// hash than the one computed when the Program was compiled.
+	ErrProgramHashMismatch = errors.New("miden: program hash mismatch")
+	// ErrProgramCleared is returned when running or proving a Program whose
+	// ProgramCache was cleared after it was compiled.
+	ErrProgramCleared = errors.New("miden: program cache cleared")
+)
+
+// Program is a compiled Miden VM program.  A Program remembers its hash and
+// keeps its assembly in a ProgramCache, so it can be run and proved
+// repeatedly without writing and compiling the assembly for every call.
+//
+// A Program is valid until its cache is cleared, after which Run and Prove
+// return ErrProgramCleared.
+type Program struct {
+	hash         ProgramHash
+	assemblyPath string
+
+	cache      *ProgramCache
+	generation uint64
+}
+
+// Hash returns the hash of the program.
+func (p *Program) Hash() ProgramHash {
+	return slices.Clone(p.hash)
+}
+
+// AssemblyPath returns the path to the assembly file of the program.
+func (p *Program) AssemblyPath() string {
+	return p.assemblyPath
+}
+
+// Run runs the program on a specified input and returns the execution output.
+func (p *Program) Run(ctx context.Context, input Input) (output Output, err error) {
+	if err = p.checkValid(); err != nil {
+		return
+	}
+
+	d, ctx := newTmpDirDriver(ctx)
+	defer d.cleanup()
+
+	var hash ProgramHash
+	if hash, output, err = d.run(ctx, p.assemblyPath, input); err != nil {
+		return
+	}
+
+	err = p.checkHash(hash)
+	return
+}
+
+// Prove runs the program on a specified input, and generates a
+// zero-knowledge proof of execution.  Prove returns the execution output and
+// zero-knowledge proof.
+func (p *Program) Prove(ctx context.Context, input Input) (output Output, proof Proof, err error) {
+	return p.ProveWithOptions(ctx, input, ProofOptions{})
+}
+
+// ProveWithOptions is like Prove, but generates the proof using the specified
+// options.
+func (p *Program) ProveWithOptions(ctx context.Context, input Input, opts ProofOptions) (output Output, proof Proof, err error) {
+	if err = p.checkValid(); err != nil {
+		return
+	}
+
+	d, ctx := newTmpDirDriver(ctx)
+	defer d.cleanup()
+
+	var hash ProgramHash
+	if hash, output, proof, err = d.prove(ctx, opts, p.assemblyPath, input); err != nil {
+		return
+	}
+
+	err = p.checkHash(hash)
+	return
+}
+
+// Verify checks if the provided zero-knowledge proof matches the program,
+// input, and output.
+func (p *Program) Verify(ctx context.Context, proof Proof, input Input, output Output) (bool, error) {
+	return Verify(ctx, p.hash, proof, input, output)
+}
+
+// Returns ErrProgramCleared if the cache of the program was cleared since it
+// was compiled.
+func (p *Program) checkValid() error {
+	if p.cache == nil {
+		return nil
+	}
+
+	p.cache.mu.Lock()
+	defer p.cache.mu.Unlock()
+
+	if p.cache.generation != p.generation {
+		return ErrProgramCleared
+	}
+	return nil
+}
+
+func (p *Program) checkHash(hash ProgramHash) error {
+	if !bytes.Equal(p.hash, hash) {
+		return ErrProgramHashMismatch
+	}
+	return nil
+}
+
+// ProgramCache keeps the assembly files of compiled programs in a directory.
+// Assembly files are content-addressed, so compiling the same assembly twice
+// returns the same Program.  ProgramCache is safe for concurrent use.
+type ProgramCache struct {
+	dir string
+
+	mu       sync.Mutex
+	programs map[string]*Program
+	// Incremented by Clear, invalidating the programs compiled before
+	generation uint64
+}
+
+// NewProgramCache returns a ProgramCache keeping assembly files in dir.  The
+// directory is created if it does not exist.
+func NewProgramCache(dir string) (*ProgramCache, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	c := &ProgramCache{
+		dir:      dir,
+		programs: make(map[string]*Program),
+	}
+	return c, nil
+}
+
+// Dir returns the directory of the cache.
+func (c *ProgramCache) Dir() string {
+	return c.dir
+}
+
+// Compile compiles a Miden VM program assembly, or returns a previously
+// compiled Program with the same assembly.  A compilation racing with Clear
+// is retried once.
+func (c *ProgramCache) Compile(ctx context.Context, assembly Assembly) (*Program, error) {
+	p, err := c.compile(ctx, assembly)
+	if errors.Is(err, ErrProgramCleared) {
+		p, err = c.compile(ctx, assembly)
+	}
+	return p, err
+}
+
+func (c *ProgramCache) compile(ctx context.Context, assembly Assembly) (*Program, error) {
+	key := assemblyKey(assembly)
+
+	p, generation := c.lookup(key)
+	if p != nil {
+		return p, nil
+	}
+
+	// The assembly is compiled from a file of its own, moved to its
+	// content-addressed path only once compiled, so that failed compilations
+	// leave no file behind
+	f, err := os.CreateTemp(c.dir, key+"-*.masm")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err = f.Write(assembly); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if err = f.Close(); err != nil {
+		return nil, err
+	}
+
+	hash, err := CompileFile(ctx, f.Name())
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// The cache might have been cleared, removing the assembly file, or
+	// another goroutine might have compiled the same program in the meantime
+	if c.generation != generation {
+		return nil, ErrProgramCleared
+	}
+	if err != nil {
+		return nil, err
+	}
+	if p, ok := c.programs[key]; ok {
+		return p, nil
+	}
+
+	assemblyPath := path.Join(c.dir, key+".masm")
+	if err = os.Rename(f.Name(), assemblyPath); err != nil {
+		return nil, err
+	}
+
+	p = &Program{hash: hash, assemblyPath: assemblyPath, cache: c, generation: generation}
+	c.programs[key] = p
+	return p, nil
+}
+
+// CompileFile compiles a Miden VM program assembly read from a file.
+func (c *ProgramCache) CompileFile(ctx context.Context, assemblyPath string) (*Program, error) {
+	assembly, err := os.ReadFile(assemblyPath)
+	if err != nil {
+		return nil, err
+	}
+	return c.Compile(ctx, assembly)
+}
+
+// Clear forgets all compiled programs and removes the cache directory
+// contents.  Clear invalidates the Programs compiled before: their assembly
+// files are removed, so Run and Prove return ErrProgramCleared, and they must
+// be compiled again.
+func (c *ProgramCache) Clear() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.programs = make(map[string]*Program)
+	c.generation++
+
+	entries, err := os.ReadDir(c.dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if err = os.RemoveAll(path.Join(c.dir, entry.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Returns the program compiled from the assembly with key, if any, and the
+// current generation of the cache.
+func (c *ProgramCache) lookup(key string) (*Program, uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.programs[key], c.generation
+}
+
+func assemblyKey(assembly Assembly) string {
+	sum := sha256.Sum256(assembly)
+	return hex.EncodeToString(sum[:])
+}
+
+// Writes data to a temporary file first, so that concurrent readers never see
+// a partially written file.
+func writeFileAtomic(name string, data []byte) error {
+	f, err := os.CreateTemp(path.Dir(name), path.Base(name)+"*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err = f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), name)
+}
+
+var (
+	_defaultCacheOnce sync.Once
+	_defaultCache     *ProgramCache
+	_defaultCacheErr  error
+)
+
+// DefaultProgramCache returns the process-wide ProgramCache, which keeps its
+// files in a directory created under os.TempDir() for the process, only
+// accessible to the user running it.
+func DefaultProgramCache() (*ProgramCache, error) {
+	_defaultCacheOnce.Do(func() {
+		dir, err := os.MkdirTemp("", "miden-programs*")
+		if err != nil {
+			_defaultCacheErr = err
+			return
+		}
+		_defaultCache, _defaultCacheErr = NewProgramCache(dir)
+	})
+	return _defaultCache, _defaultCacheErr
+}
+
+// NewProgram compiles a Miden VM program assembly using the
+// DefaultProgramCache.
+func NewProgram(ctx context.Context, assembly Assembly) (*Program, error) {
+	c, err := DefaultProgramCache()
+	if err != nil {
+		return nil, err
+	}
+	return c.Compile(ctx, assembly)
+}
+
+// NewProgramFromFile compiles a Miden VM program assembly read from a file
+// using the DefaultProgramCache.
+func NewProgramFromFile(ctx context.Context, assemblyPath string) (*Program, error) {
+	c, err := DefaultProgramCache()
+	if err != nil {
+		return nil, err
+	}
+	return c.CompileFile(ctx, assemblyPath)
+}
@@ -0,0 +1,125 @@
+package miden_test
+
+import (
+	"context"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/qredo/verifiable-oracles/pkg/miden"
+)
+
+func newTestProgramCache(t *testing.T) *miden.ProgramCache {
+	t.Helper()
+
+	c, err := miden.NewProgramCache(path.Join(t.TempDir(), "programs"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestNewProgramCache(t *testing.T) {
+	assert := assert.New(t)
+	c := newTestProgramCache(t)
+
+	info, err := os.Stat(c.Dir())
+	assert.Nil(err)
+	assert.True(info.IsDir())
+}
+
+func TestProgramCacheClear(t *testing.T) {
+	assert := assert.New(t)
+	c := newTestProgramCache(t)
+
+	err := os.WriteFile(path.Join(c.Dir(), "stale.masm"), []byte("begin\nend"), 0644)
+	assert.Nil(err)
+
+	assert.Nil(c.Clear())
+
+	entries, err := os.ReadDir(c.Dir())
+	assert.Nil(err)
+	assert.Empty(entries)
+}
+
+func TestDefaultProgramCache(t *testing.T) {
+	assert := assert.New(t)
+
+	c, err := miden.DefaultProgramCache()
+	if !assert.Nil(err) {
+		return
+	}
+	assert.NotEqual(path.Join(os.TempDir(), "miden-programs"), c.Dir())
+
+	info, err := os.Stat(c.Dir())
+	assert.Nil(err)
+	assert.Equal(os.FileMode(0700), info.Mode().Perm())
+}
+
+func TestProgramCacheCompileFile_Missing(t *testing.T) {
+	c := newTestProgramCache(t)
+
+	_, err := c.CompileFile(context.Background(), "testdata/missing.masm")
+	assert.Error(t, err)
+}
+
+func TestProgramCacheCompile(t *testing.T) {
+	needsMiden(t)
+
+	c := newTestProgramCache(t)
+
+	for name, tc := range midenTable {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			p, err := c.Compile(context.Background(), tc.assembly())
+			if handleExitError(t, err) {
+				assert.Equal(tc.hash, hashToHex(p.Hash()))
+				assert.FileExists(p.AssemblyPath())
+
+				q, err := c.Compile(context.Background(), tc.assembly())
+				assert.Nil(err)
+				assert.Same(p, q)
+			}
+		})
+	}
+}
+
+func TestProgramRunProveVerify(t *testing.T) {
+	needsMiden(t)
+
+	c := newTestProgramCache(t)
+
+	for name, tc := range midenTable {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			p, err := c.Compile(context.Background(), tc.assembly())
+			if !handleExitError(t, err) {
+				return
+			}
+
+			expectedOutput := tc.expected
+			if expectedOutput == nil {
+				expectedOutput = _defaultOutput
+			}
+
+			output, err := p.Run(context.Background(), tc.inputFile)
+			if handleExitError(t, err) {
+				assert.Equal(expectedOutput, output.Stack)
+			}
+
+			output, proof, err := p.Prove(context.Background(), tc.inputFile)
+			if handleExitError(t, err) {
+				assert.Equal(expectedOutput, output.Stack)
+
+				result, err := p.Verify(context.Background(), proof, tc.inputFile, output)
+				if handleExitError(t, err) {
+					assert.True(result)
+				}
+			}
+		})
+	}
+}
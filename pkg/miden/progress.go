@@ -0,0 +1,192 @@
+package miden
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ProgressFunc receives the lines Miden VM prints while it runs, both to
+// stdout and stderr, as soon as they are printed.  Calls are serialised.
+type ProgressFunc func(line string)
+
+// ProveStats holds the timings and resource usage of generating a proof.
+// Fields not reported by Miden VM are zero.
+type ProveStats struct {
+	// Time taken to compile the program
+	CompileDuration time.Duration
+	// Time taken to generate the execution trace
+	ExecutionDuration time.Duration
+	// Time taken to execute the program and generate the proof, as reported
+	// by Miden VM
+	ProvingDuration time.Duration
+	// Length of the execution trace, padded to a power of two
+	TraceLength int
+	// Size of the proof in bytes, rounded down to kilobytes
+	ProofSize int
+	// Time taken by the whole Miden VM process
+	WallTime time.Duration
+	// Peak resident memory of the Miden VM process in bytes
+	PeakMemory int64
+	// The proof was read from the default ProofCache
+	Cached bool
+}
+
+// ProveFileWithProgress is like ProveFileWithOptions, but streams the
+// Miden VM output to progress, which may be nil, and returns the proving
+// statistics.  If a default ProofCache is set, a cached proof is written
+// instead of running the prover, and nothing is streamed.
+func ProveFileWithProgress(ctx context.Context, opts ProofOptions, progress ProgressFunc, assemblyPath string, inputPath string, outputPath string, proofPath string, libraryPaths ...string) (hash ProgramHash, stats ProveStats, err error) {
+	if c := GetDefaultProofCache(); c != nil {
+		return c.proveFile(ctx, opts, progress, assemblyPath, inputPath, outputPath, proofPath, libraryPaths)
+	}
+	return proveFile(ctx, opts, progress, assemblyPath, inputPath, outputPath, proofPath, libraryPaths)
+}
+
+// Runs Miden VM to prove the program at assemblyPath.
+func proveFile(ctx context.Context, opts ProofOptions, progress ProgressFunc, assemblyPath string, inputPath string, outputPath string, proofPath string, libraryPaths []string) (hash ProgramHash, stats ProveStats, err error) {
+	args := []string{"prove", "--assembly", assemblyPath, "--input", inputPath, "--output", outputPath, "--proof", proofPath}
+	args = libraryArgs(opts.args(args), libraryPaths)
+
+	start := time.Now()
+	cmd := exec.CommandContext(ctx, "miden", args...)
+	outLines, errLines, state, err := runStreaming(cmd, progress)
+	stats.WallTime = time.Since(start)
+	recordCommand(ctx, cmd, start, err)
+	if state != nil {
+		stats.PeakMemory = peakMemory(state)
+	}
+	if err != nil {
+		return
+	}
+
+	if hash, err = extractHashProve(outLines); err != nil {
+		return
+	}
+	err = extractProveStats(append(outLines, errLines...), &stats)
+	return
+}
+
+// ProveWithProgress is like Prove, but generates the proof using the
+// specified options, streams the Miden VM output to progress, which may be
+// nil, and returns the proving statistics.
+func ProveWithProgress(ctx context.Context, assembly Assembly, input Input, opts ProofOptions, progress ProgressFunc) (hash ProgramHash, output Output, proof Proof, stats ProveStats, err error) {
+	d, ctx := newTmpDirDriver(ctx)
+	defer d.cleanup()
+
+	if err = d.setAssembly(assembly); err != nil {
+		return
+	}
+
+	return d.proveWithProgress(ctx, opts, progress, d.assemblyPath(), input)
+}
+
+// Runs cmd, passing the lines it prints to progress.  On failure, the
+// returned *exec.ExitError holds the stderr output, as with cmd.Output.
+func runStreaming(cmd *exec.Cmd, progress ProgressFunc) (outLines []string, errLines []string, state *os.ProcessState, err error) {
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return
+	}
+	if err = cmd.Start(); err != nil {
+		return
+	}
+
+	var (
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+		errBuf bytes.Buffer
+	)
+	scan := func(r io.Reader, lines *[]string, raw *bytes.Buffer) {
+		defer wg.Done()
+
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(nil, 1<<20)
+		for scanner.Scan() {
+			line := scanner.Text()
+
+			mu.Lock()
+			*lines = append(*lines, line)
+			if raw != nil {
+				raw.WriteString(line + "\n")
+			}
+			if progress != nil {
+				progress(line)
+			}
+			mu.Unlock()
+		}
+		// Drain the pipe so the process does not block on a long line
+		io.Copy(io.Discard, r)
+	}
+
+	wg.Add(2)
+	go scan(stdout, &outLines, nil)
+	go scan(stderr, &errLines, &errBuf)
+	wg.Wait()
+
+	err = cmd.Wait()
+	state = cmd.ProcessState
+
+	var exitError *exec.ExitError
+	if errors.As(err, &exitError) {
+		exitError.Stderr = errBuf.Bytes()
+	}
+	return
+}
+
+// Parses the timings Miden VM prints while proving:
+//
+//	Compiling program... done (N ms)
+//	Generated execution trace of C columns and L steps in N ms
+//	Program with hash H proved in N ms
+//	Writing data to proof file - size N KB
+//
+// The execution trace line is logged by the prover to stderr, and L is the
+// padded trace length.
+func extractProveStats(lines []string, stats *ProveStats) error {
+	if line, ok := extractLine(lines, "Program with hash ", ""); ok {
+		if i := strings.LastIndex(line, " proved in "); i != -1 {
+			var ms int64
+			if _, err := fmt.Sscanf(line[i:], " proved in %d ms", &ms); err != nil {
+				return fmt.Errorf("miden: invalid proving time line: %w", err)
+			}
+			stats.ProvingDuration = time.Duration(ms) * time.Millisecond
+		}
+	}
+
+	for _, line := range lines {
+		var (
+			ms, columns int64
+			steps, kb   int
+		)
+
+		switch {
+		case strings.HasPrefix(line, "Compiling program... done ("):
+			if _, err := fmt.Sscanf(line, "Compiling program... done (%d ms)", &ms); err == nil {
+				stats.CompileDuration = time.Duration(ms) * time.Millisecond
+			}
+		case strings.HasPrefix(line, "Generated execution trace of "):
+			if _, err := fmt.Sscanf(line, "Generated execution trace of %d columns and %d steps in %d ms", &columns, &steps, &ms); err == nil {
+				stats.TraceLength = steps
+				stats.ExecutionDuration = time.Duration(ms) * time.Millisecond
+			}
+		case strings.HasPrefix(line, "Writing data to proof file - size "):
+			if _, err := fmt.Sscanf(line, "Writing data to proof file - size %d KB", &kb); err == nil {
+				stats.ProofSize = kb * 1024
+			}
+		}
+	}
+	return nil
+}
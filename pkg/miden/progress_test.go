@@ -0,0 +1,62 @@
+package miden
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var _extractProveStatsTable = map[string]struct {
+	lines []string
+	want  ProveStats
+	err   bool
+}{
+	"miden 0.6": {
+		lines: []string{
+			"============================================================",
+			"Prove program",
+			"============================================================",
+			"Reading program file `assembly.masm`",
+			"Compiling program... done (12 ms)",
+			"Proving program with hash 0a1b...",
+			"Generated execution trace of 72 columns and 1024 steps in 3 ms",
+			"Program with hash 0a1b proved in 1234 ms",
+			"Writing data to proof file - size 61 KB",
+		},
+		want: ProveStats{
+			CompileDuration:   12 * time.Millisecond,
+			ExecutionDuration: 3 * time.Millisecond,
+			ProvingDuration:   1234 * time.Millisecond,
+			TraceLength:       1024,
+			ProofSize:         61 * 1024,
+		},
+	},
+	"no stats": {
+		lines: []string{"Program with hash 0a1b"},
+	},
+	"unknown trace format": {
+		lines: []string{"Generated execution trace of many columns"},
+	},
+	"invalid proving time": {
+		lines: []string{"Program with hash 0a1b proved in a while"},
+		err:   true,
+	},
+}
+
+func TestExtractProveStats(t *testing.T) {
+	for name, tc := range _extractProveStatsTable {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			var stats ProveStats
+			err := extractProveStats(tc.lines, &stats)
+			if tc.err {
+				assert.Error(err)
+				return
+			}
+			assert.Nil(err)
+			assert.Equal(tc.want, stats)
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package miden
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// ModulePathSeparator separates the components of a module path.
+const ModulePathSeparator = "::"
+
+// Module is a MASM library module.  Programs import a module using its path,
+// e.g. `use.oracle::headers`, where the first component of the path is the
+// namespace of the library.
+type Module struct {
+	Path     string
+	Assembly Assembly
+}
+
+func (m Module) components() []string {
+	return strings.Split(m.Path, ModulePathSeparator)
+}
+
+// Namespace returns the first component of the module path.
+func (m Module) Namespace() string {
+	return m.components()[0]
+}
+
+// Validate checks if the module path is well formed.
+func (m Module) Validate() error {
+	components := m.components()
+	if len(components) < 2 {
+		return fmt.Errorf("miden: module path %q must have a namespace and a name", m.Path)
+	}
+
+	for _, c := range components {
+		if !isIdentifier(c) {
+			return fmt.Errorf("miden: invalid component %q in module path %q", c, m.Path)
+		}
+	}
+	return nil
+}
+
+// Project is a Miden VM program consisting of a main program and library
+// modules.  Modules sharing a namespace are encoded into a single .masl
+// library, which the main program is compiled against.
+type Project struct {
+	Main    Assembly
+	Modules []Module
+	// Paths to precompiled .masl library files
+	Libraries []string
+}
+
+// Validate checks if module paths are well formed and unique, and do not
+// clash with the standard library.
+func (p Project) Validate() error {
+	seen := make(map[string]bool, len(p.Modules))
+
+	for _, m := range p.Modules {
+		if err := m.Validate(); err != nil {
+			return err
+		}
+		if m.Namespace() == "std" {
+			return fmt.Errorf("miden: module %q uses the reserved namespace std", m.Path)
+		}
+		if seen[m.Path] {
+			return fmt.Errorf("miden: duplicate module %q", m.Path)
+		}
+		seen[m.Path] = true
+	}
+	return nil
+}
+
+// Namespaces returns the sorted namespaces of the project modules.
+func (p Project) Namespaces() []string {
+	var namespaces []string
+	for _, m := range p.Modules {
+		namespaces = append(namespaces, m.Namespace())
+	}
+	slices.Sort(namespaces)
+	return slices.Compact(namespaces)
+}
+
+func isIdentifier(s string) bool {
+	if s == "" || !unicode.IsLetter(rune(s[0])) {
+		return false
+	}
+	for _, r := range s {
+		if r > unicode.MaxASCII || !(unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_') {
+			return false
+		}
+	}
+	return true
+}
+
+// CompileProject compiles a Miden VM project and returns the program's hash.
+func CompileProject(ctx context.Context, project Project) (ProgramHash, error) {
+	d, ctx := newTmpDirDriver(ctx)
+	defer d.cleanup()
+
+	libraryPaths, err := d.setProject(project)
+	if err != nil {
+		return nil, err
+	}
+
+	return CompileFile(ctx, d.assemblyPath(), libraryPaths...)
+}
+
+// RunProject compiles a Miden VM project and runs it on a specified input.
+// RunProject returns the program hash and execution output.
+func RunProject(ctx context.Context, project Project, input Input) (hash ProgramHash, output Output, err error) {
+	d, ctx := newTmpDirDriver(ctx)
+	defer d.cleanup()
+
+	var libraryPaths []string
+	if libraryPaths, err = d.setProject(project); err != nil {
+		return
+	}
+
+	return d.run(ctx, d.assemblyPath(), input, libraryPaths...)
+}
+
+// ProveProject compiles a Miden VM project, runs it, and generates a
+// zero-knowledge proof of execution.  ProveProject returns the program hash,
+// execution output, and zero-knowledge proof.
+func ProveProject(ctx context.Context, project Project, input Input) (hash ProgramHash, output Output, proof Proof, err error) {
+	d, ctx := newTmpDirDriver(ctx)
+	defer d.cleanup()
+
+	var libraryPaths []string
+	if libraryPaths, err = d.setProject(project); err != nil {
+		return
+	}
+
+	return d.prove(ctx, ProofOptions{}, d.assemblyPath(), input, libraryPaths...)
+}
@@ -0,0 +1,142 @@
+package miden_test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	field "github.com/qredo/verifiable-oracles/pkg/goldilocks"
+	"github.com/qredo/verifiable-oracles/pkg/miden"
+)
+
+var _moduleValidateTable = map[string]struct {
+	path  string
+	valid bool
+}{
+	"namespace and name": {"oracle::headers", true},
+	"nested":             {"oracle::crypto::rpo", true},
+	"underscore":         {"oracle::block_headers", true},
+	"digits":             {"oracle::rpo256", true},
+	"no namespace":       {"headers", false},
+	"empty component":    {"oracle::", false},
+	"leading digit":      {"oracle::256rpo", false},
+	"invalid character":  {"oracle::rpo-256", false},
+	"single colon":       {"oracle:rpo", false},
+}
+
+func TestModuleValidate(t *testing.T) {
+	for name, tc := range _moduleValidateTable {
+		t.Run(name, func(t *testing.T) {
+			err := miden.Module{Path: tc.path}.Validate()
+			if tc.valid {
+				assert.Nil(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestProjectValidate(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Nil(miden.Project{}.Validate())
+	assert.Error(miden.Project{Modules: []miden.Module{{Path: "std::math"}}}.Validate())
+	assert.Error(miden.Project{Modules: []miden.Module{{Path: "a::b"}, {Path: "a::b"}}}.Validate())
+}
+
+func TestProjectNamespaces(t *testing.T) {
+	project := miden.Project{
+		Modules: []miden.Module{
+			{Path: "oracle::rpo"},
+			{Path: "crypto::hash"},
+			{Path: "oracle::headers"},
+		},
+	}
+
+	assert.Equal(t, []string{"crypto", "oracle"}, project.Namespaces())
+}
+
+func readProject(t *testing.T) miden.Project {
+	t.Helper()
+
+	read := func(name string) []byte {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data
+	}
+
+	return miden.Project{
+		Main: read("testdata/project/main.masm"),
+		Modules: []miden.Module{
+			{Path: "oracle::math", Assembly: read("testdata/project/oracle/math.masm")},
+			{Path: "oracle::checks", Assembly: read("testdata/project/oracle/checks.masm")},
+		},
+	}
+}
+
+func TestProjectCompile_Error(t *testing.T) {
+	project := readProject(t)
+	project.Modules[0].Assembly = []byte("export.add_one\n  exec.missing\nend\n")
+
+	_, err := miden.CompileProject(context.Background(), project)
+	assert.EqualError(t, err, "masl: module oracle::math: 2:3: undefined procedure missing")
+}
+
+// The modules of the project inlined into its main program compile to the
+// same program as the project compiled against its library.
+func TestMidenCompileProject_Inlined(t *testing.T) {
+	needsMiden(t)
+
+	hash, err := miden.CompileProject(context.Background(), readProject(t))
+	if !handleExitError(t, err) {
+		return
+	}
+
+	inlinedHash, err := miden.CompileFile(context.Background(), "testdata/project/inlined.masm")
+	if handleExitError(t, err) {
+		assert.Equal(t, inlinedHash, hash)
+	}
+}
+
+func TestMidenRunProject(t *testing.T) {
+	needsMiden(t)
+
+	var (
+		assert  = assert.New(t)
+		project = readProject(t)
+		input   = miden.Input{OperandStack: field.Vector{field.One()}}
+	)
+
+	hash, err := miden.CompileProject(context.Background(), project)
+	if !handleExitError(t, err) {
+		return
+	}
+
+	runHash, output, err := miden.RunProject(context.Background(), project, input)
+	if handleExitError(t, err) {
+		assert.Equal(hash, runHash)
+		assert.Equal(out(), output.Stack)
+	}
+}
+
+func TestMidenProveProject(t *testing.T) {
+	needsMiden(t)
+
+	var (
+		project = readProject(t)
+		input   = miden.Input{OperandStack: field.Vector{field.One()}}
+	)
+
+	hash, output, proof, err := miden.ProveProject(context.Background(), project, input)
+	if handleExitError(t, err) {
+		result, err := miden.Verify(context.Background(), hash, proof, input, output)
+		if handleExitError(t, err) {
+			assert.True(t, result)
+		}
+	}
+}
@@ -0,0 +1,389 @@
+package miden
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ProofCacheConfig configures a ProofCache.
+type ProofCacheConfig struct {
+	// Directory holding the cache entries
+	Dir string
+	// Maximum total size of the cache entries in bytes, unlimited if zero
+	MaxBytes int64
+	// Maximum number of cache entries, unlimited if zero
+	MaxEntries int
+	// Trust cached proofs without verifying them with Miden VM
+	SkipVerify bool
+}
+
+// ProofCache is a content-addressed cache of Miden VM proofs kept in a
+// directory.  Entries are keyed by the program hash, the canonical encoding of
+// the input, and the proof options.  When the cache exceeds its limits, least
+// recently used entries are evicted.  ProofCache is safe for concurrent use.
+//
+// A ProofCache is used either explicitly with its Prove method, or by all
+// proving functions of the package once set with SetDefaultProofCache.
+type ProofCache struct {
+	config ProofCacheConfig
+	mu     sync.Mutex
+}
+
+// Cache entry stored on disk
+type proofCacheEntry struct {
+	ProgramHash ProgramHash `json:"program_hash"`
+	// Digest of the assembly and library files, for entries stored by
+	// proveFile, which keys them by this digest instead of the program hash
+	Source  string `json:"source,omitempty"`
+	Input   Input  `json:"input"`
+	Options string `json:"options"`
+	Output  Output `json:"output"`
+	Proof   Proof  `json:"proof"`
+}
+
+const _proofCacheExt = ".proof.json"
+
+// NewProofCache returns a ProofCache configured by config.  The cache
+// directory is created if it does not exist.
+func NewProofCache(config ProofCacheConfig) (*ProofCache, error) {
+	if err := os.MkdirAll(config.Dir, 0755); err != nil {
+		return nil, err
+	}
+	return &ProofCache{config: config}, nil
+}
+
+var _defaultProofCache atomic.Pointer[ProofCache]
+
+// SetDefaultProofCache sets the process-wide ProofCache consulted by Prove,
+// ProveFile, and the other proving functions of the package before invoking
+// Miden VM, and storing the proofs it generates.  A nil cache, the default,
+// disables caching.
+func SetDefaultProofCache(c *ProofCache) {
+	_defaultProofCache.Store(c)
+}
+
+// GetDefaultProofCache returns the process-wide ProofCache, or nil if
+// caching is disabled.
+func GetDefaultProofCache() *ProofCache {
+	return _defaultProofCache.Load()
+}
+
+// Proves the program at assemblyPath as proveFile does, unless the proof is
+// in the cache.  The program hash is only known once the program is compiled,
+// so the entry is keyed by the digest of the assembly and library files
+// instead, and the hash is taken from the prover output.
+func (c *ProofCache) proveFile(ctx context.Context, opts ProofOptions, progress ProgressFunc, assemblyPath string, inputPath string, outputPath string, proofPath string, libraryPaths []string) (hash ProgramHash, stats ProveStats, err error) {
+	source, err := sourceDigest(assemblyPath, libraryPaths)
+	if err != nil {
+		return
+	}
+
+	var input Input
+	if err = readJSONFile(inputPath, &input); err != nil {
+		return
+	}
+
+	key, err := proofCacheKey(source, input, opts)
+	if err != nil {
+		return
+	}
+	entry, ok := c.get(ctx, key, func(e proofCacheEntry) bool {
+		return e.Source == hex.EncodeToString(source) && e.matches(e.ProgramHash, input, opts)
+	})
+	if ok {
+		if err = writeJSONFile(outputPath, entry.Output); err != nil {
+			return
+		}
+		stats.Cached = true
+		stats.ProofSize = len(entry.Proof) / 1024 * 1024
+		err = os.WriteFile(proofPath, entry.Proof, 0644)
+		return entry.ProgramHash, stats, err
+	}
+
+	if hash, stats, err = proveFile(ctx, opts, progress, assemblyPath, inputPath, outputPath, proofPath, libraryPaths); err != nil {
+		return
+	}
+
+	var output Output
+	if err = readJSONFile(outputPath, &output); err != nil {
+		return
+	}
+	proof, err := os.ReadFile(proofPath)
+	if err != nil {
+		return
+	}
+
+	// Failing to cache a valid proof is not an error for the caller
+	_ = c.put(key, proofCacheEntry{
+		ProgramHash: hash,
+		Source:      hex.EncodeToString(source),
+		Input:       input,
+		Options:     opts.String(),
+		Output:      output,
+		Proof:       proof,
+	})
+	return
+}
+
+// Computes the digest of the assembly and library files of a program.
+func sourceDigest(assemblyPath string, libraryPaths []string) ([]byte, error) {
+	h := sha256.New()
+	for _, name := range append([]string{assemblyPath}, libraryPaths...) {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			return nil, err
+		}
+		h.Write(binary.LittleEndian.AppendUint64(nil, uint64(len(data))))
+		h.Write(data)
+	}
+	return h.Sum(nil), nil
+}
+
+func readJSONFile(name string, v any) error {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func writeJSONFile(name string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(name, data, 0644)
+}
+
+// Prove returns a cached proof of executing program on input, or proves the
+// execution and stores the proof in the cache.
+func (c *ProofCache) Prove(ctx context.Context, program *Program, input Input, opts ProofOptions) (Output, Proof, error) {
+	if output, proof, ok := c.Get(ctx, program.hash, input, opts); ok {
+		return output, proof, nil
+	}
+
+	output, proof, err := program.ProveWithOptions(ctx, input, opts)
+	if err != nil {
+		return output, proof, err
+	}
+
+	// Failing to cache a valid proof is not an error for the caller
+	_ = c.Put(program.hash, input, opts, output, proof)
+	return output, proof, nil
+}
+
+// Get looks up a proof in the cache.  Unless the cache is configured with
+// SkipVerify, the cached proof is verified, and removed from the cache if
+// it is invalid.
+func (c *ProofCache) Get(ctx context.Context, programHash ProgramHash, input Input, opts ProofOptions) (Output, Proof, bool) {
+	key, err := proofCacheKey(programHash, input, opts)
+	if err != nil {
+		return Output{}, nil, false
+	}
+
+	entry, ok := c.get(ctx, key, func(e proofCacheEntry) bool {
+		return e.matches(programHash, input, opts)
+	})
+	if !ok {
+		return Output{}, nil, false
+	}
+	return entry.Output, entry.Proof, true
+}
+
+// Looks up the entry with key, if it satisfies match.
+func (c *ProofCache) get(ctx context.Context, key string, match func(proofCacheEntry) bool) (proofCacheEntry, bool) {
+	name := c.entryPath(key)
+
+	c.mu.Lock()
+	entry, err := readProofCacheEntry(name)
+	if err == nil {
+		// Mark the entry as recently used
+		now := time.Now()
+		err = os.Chtimes(name, now, now)
+	}
+	c.mu.Unlock()
+
+	if err != nil || !match(entry) {
+		return proofCacheEntry{}, false
+	}
+
+	if !c.config.SkipVerify {
+		ok, err := Verify(ctx, entry.ProgramHash, entry.Proof, entry.Input, entry.Output)
+		if !ok || err != nil {
+			// Only drop entries Miden VM rejected, not the ones it could
+			// not verify, e.g. because verification was interrupted
+			if !ok && err == nil {
+				c.remove(name)
+			}
+			return proofCacheEntry{}, false
+		}
+	}
+
+	return entry, true
+}
+
+// Put stores a proof in the cache, evicting least recently used entries if
+// the cache exceeds its limits.
+func (c *ProofCache) Put(programHash ProgramHash, input Input, opts ProofOptions, output Output, proof Proof) error {
+	key, err := proofCacheKey(programHash, input, opts)
+	if err != nil {
+		return err
+	}
+
+	return c.put(key, proofCacheEntry{
+		ProgramHash: programHash,
+		Input:       input,
+		Options:     opts.String(),
+		Output:      output,
+		Proof:       proof,
+	})
+}
+
+func (c *ProofCache) put(key string, entry proofCacheEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err = writeFileAtomic(c.entryPath(key), data); err != nil {
+		return err
+	}
+	return c.evict()
+}
+
+// Size returns the number of entries in the cache and their total size in
+// bytes.
+func (c *ProofCache) Size() (entries int, size int64, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	infos, err := c.entries()
+	for _, info := range infos {
+		size += info.Size()
+	}
+	return len(infos), size, err
+}
+
+func (c *ProofCache) entryPath(key string) string {
+	return path.Join(c.config.Dir, key+_proofCacheExt)
+}
+
+func (c *ProofCache) remove(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	os.Remove(name)
+}
+
+// Returns the cache entries, least recently used first.
+func (c *ProofCache) entries() ([]os.FileInfo, error) {
+	dirEntries, err := os.ReadDir(c.config.Dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var infos []os.FileInfo
+	for _, e := range dirEntries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), _proofCacheExt) {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			// Entry removed concurrently
+			continue
+		}
+		infos = append(infos, info)
+	}
+
+	slices.SortFunc(infos, func(a, b os.FileInfo) int {
+		return a.ModTime().Compare(b.ModTime())
+	})
+	return infos, nil
+}
+
+// Removes least recently used entries until the cache is within its limits.
+// Must be called with c.mu held.
+func (c *ProofCache) evict() error {
+	if c.config.MaxBytes <= 0 && c.config.MaxEntries <= 0 {
+		return nil
+	}
+
+	infos, err := c.entries()
+	if err != nil {
+		return err
+	}
+
+	var size int64
+	for _, info := range infos {
+		size += info.Size()
+	}
+
+	for len(infos) > 0 {
+		overEntries := c.config.MaxEntries > 0 && len(infos) > c.config.MaxEntries
+		overBytes := c.config.MaxBytes > 0 && size > c.config.MaxBytes
+		if !overEntries && !overBytes {
+			break
+		}
+
+		if err := os.Remove(path.Join(c.config.Dir, infos[0].Name())); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		size -= infos[0].Size()
+		infos = infos[1:]
+	}
+	return nil
+}
+
+func (e proofCacheEntry) matches(programHash ProgramHash, input Input, opts ProofOptions) bool {
+	if !bytes.Equal(e.ProgramHash, programHash) || e.Options != opts.String() {
+		return false
+	}
+
+	// Guard against corrupted entries by comparing canonical encodings
+	want, err := json.Marshal(input)
+	if err != nil {
+		return false
+	}
+	got, err := json.Marshal(e.Input)
+	return err == nil && bytes.Equal(want, got)
+}
+
+func readProofCacheEntry(name string) (entry proofCacheEntry, err error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(data, &entry)
+	return
+}
+
+// Computes the cache key from the program hash, or source digest, the
+// canonical JSON encoding of the input, and the proof options.
+func proofCacheKey(programHash []byte, input Input, opts ProofOptions) (string, error) {
+	data, err := json.Marshal(input)
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.New()
+	h.Write(programHash)
+	h.Write([]byte{0})
+	h.Write(data)
+	h.Write([]byte{0})
+	h.Write([]byte(opts.String()))
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,161 @@
+package miden_test
+
+import (
+	"context"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	field "github.com/qredo/verifiable-oracles/pkg/goldilocks"
+	"github.com/qredo/verifiable-oracles/pkg/miden"
+)
+
+var (
+	_cacheHash   = miden.ProgramHash{0xf0, 0xdb}
+	_cacheInput  = miden.Input{OperandStack: field.Vector{field.One()}}
+	_cacheOutput = miden.Output{Stack: out(field.One()), OverflowAddrs: field.Vector{}}
+	_cacheProof  = miden.Proof{1, 2, 3}
+)
+
+func newTestProofCache(t *testing.T, config miden.ProofCacheConfig) *miden.ProofCache {
+	t.Helper()
+
+	config.Dir = path.Join(t.TempDir(), "proofs")
+	config.SkipVerify = true
+	c, err := miden.NewProofCache(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestProofCache_PutGet(t *testing.T) {
+	assert := assert.New(t)
+	c := newTestProofCache(t, miden.ProofCacheConfig{})
+
+	_, _, ok := c.Get(context.Background(), _cacheHash, _cacheInput, miden.ProofOptions{})
+	assert.False(ok)
+
+	err := c.Put(_cacheHash, _cacheInput, miden.ProofOptions{}, _cacheOutput, _cacheProof)
+	assert.Nil(err)
+
+	output, proof, ok := c.Get(context.Background(), _cacheHash, _cacheInput, miden.ProofOptions{})
+	assert.True(ok)
+	assert.Equal(_cacheOutput, output)
+	assert.Equal(_cacheProof, proof)
+
+	entries, size, err := c.Size()
+	assert.Nil(err)
+	assert.Equal(1, entries)
+	assert.Positive(size)
+}
+
+func TestProofCache_Key(t *testing.T) {
+	assert := assert.New(t)
+	c := newTestProofCache(t, miden.ProofCacheConfig{})
+
+	err := c.Put(_cacheHash, _cacheInput, miden.ProofOptions{}, _cacheOutput, _cacheProof)
+	assert.Nil(err)
+
+	otherInput := miden.Input{OperandStack: field.Vector{field.NewElement(2)}}
+	_, _, ok := c.Get(context.Background(), _cacheHash, otherInput, miden.ProofOptions{})
+	assert.False(ok)
+
+	_, _, ok = c.Get(context.Background(), miden.ProgramHash{1}, _cacheInput, miden.ProofOptions{})
+	assert.False(ok)
+
+	_, _, ok = c.Get(context.Background(), _cacheHash, _cacheInput, miden.ProofOptions{Security: miden.Security128})
+	assert.False(ok)
+
+	// Explicit default options are the same as zero options
+	_, _, ok = c.Get(context.Background(), _cacheHash, _cacheInput, miden.ProofOptions{Security: miden.Security96})
+	assert.True(ok)
+}
+
+func TestProofCache_Corrupted(t *testing.T) {
+	assert := assert.New(t)
+
+	dir := path.Join(t.TempDir(), "proofs")
+	c, err := miden.NewProofCache(miden.ProofCacheConfig{Dir: dir, SkipVerify: true})
+	assert.Nil(err)
+
+	err = c.Put(_cacheHash, _cacheInput, miden.ProofOptions{}, _cacheOutput, _cacheProof)
+	assert.Nil(err)
+
+	entries, err := os.ReadDir(dir)
+	assert.Nil(err)
+	assert.Len(entries, 1)
+	err = os.WriteFile(path.Join(dir, entries[0].Name()), []byte("{"), 0644)
+	assert.Nil(err)
+
+	_, _, ok := c.Get(context.Background(), _cacheHash, _cacheInput, miden.ProofOptions{})
+	assert.False(ok)
+}
+
+func TestProofCache_EvictEntries(t *testing.T) {
+	assert := assert.New(t)
+	c := newTestProofCache(t, miden.ProofCacheConfig{MaxEntries: 2})
+
+	for i := uint64(0); i < 3; i++ {
+		input := miden.Input{OperandStack: field.Vector{field.NewElement(i)}}
+		err := c.Put(_cacheHash, input, miden.ProofOptions{}, _cacheOutput, _cacheProof)
+		assert.Nil(err)
+
+		// Make sure modification times differ
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	entries, _, err := c.Size()
+	assert.Nil(err)
+	assert.Equal(2, entries)
+
+	// The oldest entry was evicted
+	oldest := miden.Input{OperandStack: field.Vector{field.NewElement(0)}}
+	_, _, ok := c.Get(context.Background(), _cacheHash, oldest, miden.ProofOptions{})
+	assert.False(ok)
+}
+
+func TestProofCache_EvictBytes(t *testing.T) {
+	assert := assert.New(t)
+	c := newTestProofCache(t, miden.ProofCacheConfig{MaxBytes: 1})
+
+	err := c.Put(_cacheHash, _cacheInput, miden.ProofOptions{}, _cacheOutput, _cacheProof)
+	assert.Nil(err)
+
+	entries, size, err := c.Size()
+	assert.Nil(err)
+	assert.Equal(0, entries)
+	assert.Zero(size)
+}
+
+func TestProofCache_Prove(t *testing.T) {
+	needsMiden(t)
+
+	var (
+		assert   = assert.New(t)
+		tc       = midenTable["add one to two"]
+		programs = newTestProgramCache(t)
+	)
+
+	c, err := miden.NewProofCache(miden.ProofCacheConfig{Dir: path.Join(t.TempDir(), "proofs")})
+	assert.Nil(err)
+
+	p, err := programs.Compile(context.Background(), tc.assembly())
+	if !handleExitError(t, err) {
+		return
+	}
+
+	output, proof, err := c.Prove(context.Background(), p, tc.inputFile, miden.ProofOptions{})
+	if !handleExitError(t, err) {
+		return
+	}
+
+	// Second call is served, and verified, from the cache
+	cachedOutput, cachedProof, ok := c.Get(context.Background(), p.Hash(), tc.inputFile, miden.ProofOptions{})
+	assert.True(ok)
+	assert.Equal(output, cachedOutput)
+	assert.Equal(proof, cachedProof)
+}
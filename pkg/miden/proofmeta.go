@@ -0,0 +1,350 @@
+package miden
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"math/bits"
+	"slices"
+
+	field "github.com/qredo/verifiable-oracles/pkg/goldilocks"
+)
+
+// HashFunction is the hash function used to generate a Miden VM proof.
+type HashFunction uint8
+
+const (
+	HashBlake3_192 HashFunction = 0x00
+	HashBlake3_256 HashFunction = 0x01
+	HashRpo256     HashFunction = 0x02
+)
+
+func (h HashFunction) String() string {
+	switch h {
+	case HashBlake3_192:
+		return "blake3_192"
+	case HashBlake3_256:
+		return "blake3_256"
+	case HashRpo256:
+		return "rpo256"
+	}
+	return fmt.Sprintf("HashFunction(%d)", uint8(h))
+}
+
+// CollisionResistance returns the collision resistance of the hash function
+// in bits.
+func (h HashFunction) CollisionResistance() int {
+	if h == HashBlake3_192 {
+		return 96
+	}
+	return 128
+}
+
+// DigestSize returns the size of the hash function digests in bytes.
+func (h HashFunction) DigestSize() int {
+	if h == HashBlake3_192 {
+		return 24
+	}
+	return 32
+}
+
+func (h HashFunction) valid() bool {
+	return h <= HashRpo256
+}
+
+// ProofMetadata describes the STARK protocol parameters and commitments of a
+// Miden VM proof.  Public inputs are not part of the proof.
+type ProofMetadata struct {
+	HashFunction HashFunction
+	// Widths of the main and auxiliary trace segments
+	TraceWidth    int
+	AuxTraceWidth int
+	TraceLength   int
+	// Length of the trace metadata
+	TraceMeta int
+	// Size of the base field in bits
+	FieldBits int
+	// Degree of the field extension used for composition and FRI, 1 for none
+	FieldExtension        int
+	NumQueries            int
+	BlowupFactor          int
+	GrindingBits          int
+	FriFoldingFactor      int
+	FriRemainderMaxDegree int
+	// Roots of the trace segment, constraint evaluation, and FRI layer Merkle
+	// trees, committing to the execution and its public inputs
+	TraceCommitments     [][]byte
+	ConstraintCommitment []byte
+	FriCommitments       [][]byte
+	PowNonce             uint64
+	// Size of the proof in bytes
+	Size int
+}
+
+// SecurityLevel returns the conjectured security level of the proof in bits,
+// computed as Winterfell does.
+func (m *ProofMetadata) SecurityLevel() int {
+	// Grinding only counts for proofs with adequate query security
+	const grindingContributionFloor = 80
+
+	ldeDomainSize := uint(m.TraceLength * m.BlowupFactor)
+	fieldSecurity := m.FieldBits*m.FieldExtension - bits.TrailingZeros(ldeDomainSize)
+
+	querySecurity := bits.TrailingZeros(uint(m.BlowupFactor)) * m.NumQueries
+	if querySecurity >= grindingContributionFloor {
+		querySecurity += m.GrindingBits
+	}
+
+	return max(min(fieldSecurity, querySecurity, m.HashFunction.CollisionResistance()+1)-1, 0)
+}
+
+// ParseProofMetadata decodes the metadata of a serialized Miden VM proof.  It
+// rejects proofs whose out-of-domain frame or FRI remainder holds
+// non-canonical field elements.
+func ParseProofMetadata(proof Proof) (*ProofMetadata, error) {
+	r := &proofReader{data: proof}
+	m := &ProofMetadata{Size: len(proof)}
+
+	m.HashFunction = HashFunction(r.u8())
+	if r.err == nil && !m.HashFunction.valid() {
+		return nil, fmt.Errorf("miden: invalid proof hash function %d", m.HashFunction)
+	}
+
+	// Context: trace layout, trace length, trace metadata, field modulus, and
+	// proof options
+	m.TraceWidth = int(r.u8())
+	m.AuxTraceWidth = int(r.u8())
+	auxRands := r.u8()
+	logTraceLength := r.u8()
+	m.TraceMeta = len(r.bytes(int(r.u16())))
+	modulus := r.bytes(int(r.u8()))
+	m.NumQueries = int(r.u8())
+	m.BlowupFactor = int(r.u8())
+	m.GrindingBits = int(r.u8())
+	m.FieldExtension = int(r.u8())
+	m.FriFoldingFactor = int(r.u8())
+	m.FriRemainderMaxDegree = int(r.u8())
+	if r.err != nil {
+		return nil, r.fail("context")
+	}
+
+	if logTraceLength >= 63 {
+		return nil, fmt.Errorf("miden: invalid proof trace length 2^%d", logTraceLength)
+	}
+	m.TraceLength = 1 << logTraceLength
+	if (m.AuxTraceWidth == 0) != (auxRands == 0) {
+		return nil, errors.New("miden: invalid proof auxiliary trace layout")
+	}
+	if m.FieldBits = modulusBits(modulus); m.FieldBits == 0 {
+		return nil, fmt.Errorf("miden: proof field modulus %x is not Goldilocks", modulus)
+	}
+	if m.FieldExtension < 1 || m.FieldExtension > 3 {
+		return nil, fmt.Errorf("miden: invalid proof field extension %d", m.FieldExtension)
+	}
+	if m.BlowupFactor == 0 || bits.OnesCount(uint(m.BlowupFactor)) != 1 {
+		return nil, fmt.Errorf("miden: invalid proof blowup factor %d", m.BlowupFactor)
+	}
+
+	// Commitments: a root per trace segment, the constraint evaluations root,
+	// and the FRI layer roots
+	commitments := r.bytes(int(r.u16()))
+	if r.err != nil {
+		return nil, r.fail("commitments")
+	}
+	segments := 1
+	if m.AuxTraceWidth > 0 {
+		segments++
+	}
+	digestSize := m.HashFunction.DigestSize()
+	if len(commitments)%digestSize != 0 || len(commitments)/digestSize < segments+1 {
+		return nil, fmt.Errorf("miden: invalid proof commitments length %d", len(commitments))
+	}
+	digests := make([][]byte, len(commitments)/digestSize)
+	for i := range digests {
+		digests[i] = commitments[i*digestSize : (i+1)*digestSize]
+	}
+	m.TraceCommitments = digests[:segments]
+	m.ConstraintCommitment = digests[segments]
+	m.FriCommitments = digests[segments+1:]
+
+	// Trace and constraint queries
+	if n := int(r.u8()); r.err == nil && n != segments {
+		return nil, fmt.Errorf("miden: proof has %d trace queries, expected %d", n, segments)
+	}
+	for i := 0; i <= segments; i++ {
+		r.queries()
+	}
+	if r.err != nil {
+		return nil, r.fail("queries")
+	}
+
+	// Out-of-domain frame: trace states and constraint evaluations
+	r.elements(int(r.u16()))
+	r.elements(int(r.u16()))
+	if r.err != nil {
+		return nil, r.fail("out-of-domain frame")
+	}
+
+	// FRI proof: layers, remainder, and number of partitions
+	layers := int(r.u8())
+	for i := 0; i < layers; i++ {
+		r.queries()
+	}
+	r.elements(int(r.u16()))
+	r.u8()
+	if r.err != nil {
+		return nil, r.fail("FRI proof")
+	}
+
+	m.PowNonce = r.u64()
+	if r.err != nil {
+		return nil, r.fail("proof-of-work nonce")
+	}
+	if len(r.data) != 0 {
+		return nil, fmt.Errorf("miden: %d trailing bytes after proof", len(r.data))
+	}
+
+	return m, nil
+}
+
+// Returns the number of bits of a little-endian field modulus, or 0 if it is
+// not the Goldilocks modulus.
+func modulusBits(modulus []byte) int {
+	le := slices.Clone(modulus)
+	slices.Reverse(le)
+	if new(big.Int).SetBytes(le).Cmp(field.Modulus()) != 0 {
+		return 0
+	}
+	return field.Modulus().BitLen()
+}
+
+// Reads little-endian values from a serialized proof.  The first error is
+// sticky, and subsequent reads return zero values.
+type proofReader struct {
+	data []byte
+	err  error
+}
+
+func (r *proofReader) bytes(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n > len(r.data) {
+		r.err = errors.New("unexpected end of proof")
+		return nil
+	}
+	b := r.data[:n]
+	r.data = r.data[n:]
+	return b
+}
+
+func (r *proofReader) u8() uint8 {
+	if b := r.bytes(1); b != nil {
+		return b[0]
+	}
+	return 0
+}
+
+func (r *proofReader) u16() uint16 {
+	if b := r.bytes(2); b != nil {
+		return binary.LittleEndian.Uint16(b)
+	}
+	return 0
+}
+
+func (r *proofReader) u32() uint32 {
+	if b := r.bytes(4); b != nil {
+		return binary.LittleEndian.Uint32(b)
+	}
+	return 0
+}
+
+func (r *proofReader) u64() uint64 {
+	if b := r.bytes(8); b != nil {
+		return binary.LittleEndian.Uint64(b)
+	}
+	return 0
+}
+
+// Skips n bytes of serialized field elements, failing unless they are
+// canonical.
+func (r *proofReader) elements(n int) {
+	b := r.bytes(n)
+	if r.err != nil {
+		return
+	}
+	if n%field.Bytes != 0 {
+		r.err = fmt.Errorf("%d bytes of field elements", n)
+		return
+	}
+	for i := 0; i < n; i += field.Bytes {
+		if _, err := field.ParseLittleEndian(b[i : i+field.Bytes]); err != nil {
+			r.err = err
+			return
+		}
+	}
+}
+
+// Skips queried values and their Merkle authentication paths.
+func (r *proofReader) queries() {
+	r.bytes(int(r.u32()))
+	r.bytes(int(r.u32()))
+}
+
+func (r *proofReader) fail(part string) error {
+	return fmt.Errorf("miden: invalid proof %s: %w", part, r.err)
+}
+
+// ErrProofPolicy is returned when a proof does not meet a ProofPolicy.
+var ErrProofPolicy = errors.New("miden: proof rejected by policy")
+
+// ProofPolicy specifies the minimal parameters of acceptable proofs.  Zero
+// fields are not checked.
+type ProofPolicy struct {
+	MinSecurityLevel int
+	MinQueries       int
+	MinBlowupFactor  int
+	MinGrindingBits  int
+	MaxTraceLength   int
+	// Accepted hash functions, any if empty
+	HashFunctions []HashFunction
+}
+
+// Check returns an error wrapping ErrProofPolicy if the proof does not meet
+// the policy.
+func (p ProofPolicy) Check(m *ProofMetadata) error {
+	reject := func(format string, args ...any) error {
+		return fmt.Errorf("%w: "+format, append([]any{ErrProofPolicy}, args...)...)
+	}
+
+	switch {
+	case len(p.HashFunctions) > 0 && !slices.Contains(p.HashFunctions, m.HashFunction):
+		return reject("hash function %s not accepted", m.HashFunction)
+	case m.SecurityLevel() < p.MinSecurityLevel:
+		return reject("security level %d below %d", m.SecurityLevel(), p.MinSecurityLevel)
+	case m.NumQueries < p.MinQueries:
+		return reject("%d queries below %d", m.NumQueries, p.MinQueries)
+	case m.BlowupFactor < p.MinBlowupFactor:
+		return reject("blowup factor %d below %d", m.BlowupFactor, p.MinBlowupFactor)
+	case m.GrindingBits < p.MinGrindingBits:
+		return reject("%d grinding bits below %d", m.GrindingBits, p.MinGrindingBits)
+	case p.MaxTraceLength > 0 && m.TraceLength > p.MaxTraceLength:
+		return reject("trace length %d above %d", m.TraceLength, p.MaxTraceLength)
+	}
+	return nil
+}
+
+// VerifyWithPolicy checks the proof against policy before verifying it as
+// Verify does.
+func VerifyWithPolicy(ctx context.Context, policy ProofPolicy, programHash ProgramHash, proof Proof, input Input, output Output) (bool, error) {
+	m, err := ParseProofMetadata(proof)
+	if err != nil {
+		return false, err
+	}
+	if err = policy.Check(m); err != nil {
+		return false, err
+	}
+	return Verify(ctx, programHash, proof, input, output)
+}
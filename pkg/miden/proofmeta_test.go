@@ -0,0 +1,436 @@
+package miden_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"flag"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/qredo/verifiable-oracles/pkg/miden"
+)
+
+// Parameters of a synthetic proof serialized as by Miden VM
+type testProof struct {
+	hash           miden.HashFunction
+	width, aux     uint8
+	logTrace       uint8
+	modulus        []byte
+	queries        uint8
+	blowup         uint8
+	grinding       uint8
+	extension      uint8
+	folding        uint8
+	remainder      uint8
+	friLayers      int
+	friRemainder   []byte
+	traceQueries   int
+	trailing       []byte
+	truncateAt     int
+	commitmentSize int
+}
+
+// Parameters of Miden VM 96-bit security proofs
+func defaultTestProof() testProof {
+	return testProof{
+		hash:      miden.HashBlake3_192,
+		width:     73,
+		aux:       9,
+		logTrace:  10,
+		modulus:   []byte{1, 0, 0, 0, 0xff, 0xff, 0xff, 0xff},
+		queries:   27,
+		blowup:    8,
+		grinding:  16,
+		extension: 2,
+		folding:   8,
+		remainder: 255,
+		friLayers: 2,
+	}
+}
+
+func (p testProof) bytes() []byte {
+	var b bytes.Buffer
+	le := func(v any) {
+		binary.Write(&b, binary.LittleEndian, v)
+	}
+	chunk16 := func(data []byte) {
+		le(uint16(len(data)))
+		b.Write(data)
+	}
+	chunk32 := func(data []byte) {
+		le(uint32(len(data)))
+		b.Write(data)
+	}
+
+	b.WriteByte(byte(p.hash))
+	rands := uint8(0)
+	if p.aux > 0 {
+		rands = 16
+	}
+	b.Write([]byte{p.width, p.aux, rands, p.logTrace})
+	chunk16([]byte{0xaa, 0xbb})
+	b.WriteByte(byte(len(p.modulus)))
+	b.Write(p.modulus)
+	b.Write([]byte{p.queries, p.blowup, p.grinding, p.extension, p.folding, p.remainder})
+
+	segments := 1
+	if p.aux > 0 {
+		segments++
+	}
+	size := p.commitmentSize
+	if size == 0 {
+		size = p.hash.DigestSize() * (segments + 1 + p.friLayers)
+	}
+	commitments := make([]byte, size)
+	for i := range commitments {
+		commitments[i] = byte(i / p.hash.DigestSize())
+	}
+	chunk16(commitments)
+
+	traceQueries := p.traceQueries
+	if traceQueries == 0 {
+		traceQueries = segments
+	}
+	b.WriteByte(byte(traceQueries))
+	for i := 0; i <= traceQueries; i++ {
+		chunk32([]byte{1, 2, 3})
+		chunk32([]byte{4, 5})
+	}
+
+	chunk16(make([]byte, 16))
+	chunk16(make([]byte, 8))
+
+	b.WriteByte(byte(p.friLayers))
+	for i := 0; i < p.friLayers; i++ {
+		chunk32([]byte{6})
+		chunk32([]byte{7, 8})
+	}
+	friRemainder := p.friRemainder
+	if friRemainder == nil {
+		friRemainder = make([]byte, 16)
+	}
+	chunk16(friRemainder)
+	b.WriteByte(1)
+
+	le(uint64(0x1234))
+	b.Write(p.trailing)
+
+	data := b.Bytes()
+	if p.truncateAt > 0 {
+		data = data[:p.truncateAt]
+	}
+	return data
+}
+
+func TestParseProofMetadata(t *testing.T) {
+	assert := assert.New(t)
+	data := defaultTestProof().bytes()
+
+	m, err := miden.ParseProofMetadata(data)
+	if !assert.Nil(err) {
+		return
+	}
+
+	assert.Equal(miden.HashBlake3_192, m.HashFunction)
+	assert.Equal(73, m.TraceWidth)
+	assert.Equal(9, m.AuxTraceWidth)
+	assert.Equal(1024, m.TraceLength)
+	assert.Equal(2, m.TraceMeta)
+	assert.Equal(64, m.FieldBits)
+	assert.Equal(2, m.FieldExtension)
+	assert.Equal(27, m.NumQueries)
+	assert.Equal(8, m.BlowupFactor)
+	assert.Equal(16, m.GrindingBits)
+	assert.Equal(8, m.FriFoldingFactor)
+	assert.Equal(255, m.FriRemainderMaxDegree)
+	assert.Len(m.TraceCommitments, 2)
+	assert.Equal(bytes.Repeat([]byte{1}, 24), m.TraceCommitments[1])
+	assert.Equal(bytes.Repeat([]byte{2}, 24), m.ConstraintCommitment)
+	assert.Len(m.FriCommitments, 2)
+	assert.Equal(uint64(0x1234), m.PowNonce)
+	assert.Equal(len(data), m.Size)
+	assert.Equal(96, m.SecurityLevel())
+}
+
+// Proofs generated by the miden CLI with each preset of proof options
+var _cliProofTable = map[string]struct {
+	opts      miden.ProofOptions
+	hash      miden.HashFunction
+	blowup    int
+	grinding  int
+	extension int
+	folding   int
+	remainder int
+	security  int
+}{
+	"96 bits": {
+		hash:      miden.HashBlake3_192,
+		blowup:    8,
+		grinding:  16,
+		extension: 2,
+		folding:   8,
+		remainder: 255,
+		security:  96,
+	},
+	"96 bits recursive": {
+		opts:      miden.ProofOptions{Recursive: true},
+		hash:      miden.HashRpo256,
+		blowup:    8,
+		grinding:  16,
+		extension: 2,
+		folding:   4,
+		remainder: 7,
+		security:  96,
+	},
+	"128 bits": {
+		opts:      miden.ProofOptions{Security: miden.Security128},
+		hash:      miden.HashBlake3_256,
+		blowup:    16,
+		grinding:  21,
+		extension: 3,
+		folding:   8,
+		remainder: 255,
+		security:  128,
+	},
+	"128 bits recursive": {
+		opts:      miden.ProofOptions{Security: miden.Security128, Recursive: true},
+		hash:      miden.HashRpo256,
+		blowup:    16,
+		grinding:  21,
+		extension: 3,
+		folding:   4,
+		remainder: 7,
+		security:  128,
+	},
+}
+
+var _updateProofs = flag.Bool("update-proofs", false, "write the proofs generated by the miden CLI to testdata/proofs")
+
+// Proof generated by the miden CLI for "add one to two" with the options of
+// the _cliProofTable entry name
+func cliProofPath(name string) string {
+	return path.Join("testdata", "proofs", strings.ReplaceAll(name, " ", "_")+".proof")
+}
+
+// Checks the metadata of a proof generated by the miden CLI.  A zero
+// traceLength only checks that the trace length is valid.
+func checkCLIProofMetadata(t *testing.T, name string, proof miden.Proof, traceLength int) {
+	assert := assert.New(t)
+	p := _cliProofTable[name]
+
+	m, err := miden.ParseProofMetadata(proof)
+	if !assert.Nil(err) {
+		return
+	}
+	assert.Equal(p.hash, m.HashFunction)
+	if traceLength != 0 {
+		assert.Equal(traceLength, m.TraceLength)
+	} else {
+		// Miden VM pads traces to a power of two of at least 1024 steps
+		assert.GreaterOrEqual(m.TraceLength, 1024)
+		assert.Zero(m.TraceLength & (m.TraceLength - 1))
+	}
+	assert.Equal(64, m.FieldBits)
+	assert.Equal(p.extension, m.FieldExtension)
+	assert.Equal(27, m.NumQueries)
+	assert.Equal(p.blowup, m.BlowupFactor)
+	assert.Equal(p.grinding, m.GrindingBits)
+	assert.Equal(p.folding, m.FriFoldingFactor)
+	assert.Equal(p.remainder, m.FriRemainderMaxDegree)
+	assert.Len(m.TraceCommitments, 2)
+	assert.Equal(len(proof), m.Size)
+	assert.Equal(p.security, m.SecurityLevel())
+}
+
+// Regenerate the proofs in testdata/proofs with
+//
+//	go test -run TestParseProofMetadata_CLI -update-proofs
+func TestParseProofMetadata_CLI(t *testing.T) {
+	needsMiden(t)
+
+	tc := midenTable["add one to two"]
+	_, _, summary, err := miden.RunWithSummary(context.Background(), tc.assembly(), tc.inputFile)
+	if !handleExitError(t, err) {
+		return
+	}
+
+	for name, p := range _cliProofTable {
+		t.Run(name, func(t *testing.T) {
+			_, _, proof, _, err := miden.ProveWithProgress(context.Background(), tc.assembly(), tc.inputFile, p.opts, nil)
+			if !handleExitError(t, err) {
+				return
+			}
+			checkCLIProofMetadata(t, name, proof, summary.TraceLength)
+
+			if *_updateProofs {
+				err = os.MkdirAll(path.Dir(cliProofPath(name)), 0755)
+				if err == nil {
+					err = os.WriteFile(cliProofPath(name), proof, 0644)
+				}
+				assert.Nil(t, err)
+			}
+		})
+	}
+}
+
+func TestParseProofMetadata_CLIFixtures(t *testing.T) {
+	for name := range _cliProofTable {
+		t.Run(name, func(t *testing.T) {
+			proof, err := os.ReadFile(cliProofPath(name))
+			if errors.Is(err, fs.ErrNotExist) {
+				t.Skipf("%s not generated, see TestParseProofMetadata_CLI", cliProofPath(name))
+			}
+			if !assert.Nil(t, err) {
+				return
+			}
+			checkCLIProofMetadata(t, name, proof, 0)
+		})
+	}
+}
+
+var _securityLevelTable = map[string]struct {
+	proof    func(*testProof)
+	expected int
+}{
+	"96 bits": {
+		proof:    func(p *testProof) {},
+		expected: 96,
+	},
+	"128 bits": {
+		proof: func(p *testProof) {
+			p.hash = miden.HashBlake3_256
+			p.blowup = 16
+			p.grinding = 21
+			p.extension = 3
+		},
+		expected: 128,
+	},
+	"128 bits recursive": {
+		proof: func(p *testProof) {
+			p.hash = miden.HashRpo256
+			p.blowup = 16
+			p.grinding = 21
+			p.extension = 3
+			p.folding = 4
+			p.remainder = 7
+		},
+		expected: 128,
+	},
+	"few queries without grinding": {
+		proof: func(p *testProof) {
+			p.queries = 20
+		},
+		expected: 59,
+	},
+	"field bound": {
+		proof: func(p *testProof) {
+			p.hash = miden.HashBlake3_256
+			p.extension = 1
+			p.logTrace = 20
+			p.queries = 40
+		},
+		expected: 40,
+	},
+}
+
+func TestProofMetadataSecurityLevel(t *testing.T) {
+	for name, tc := range _securityLevelTable {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+			p := defaultTestProof()
+			tc.proof(&p)
+
+			m, err := miden.ParseProofMetadata(p.bytes())
+			if assert.Nil(err) {
+				assert.Equal(tc.expected, m.SecurityLevel())
+			}
+		})
+	}
+}
+
+var _parseProofErrorTable = map[string]func(*testProof){
+	"only hash function":      func(p *testProof) { p.truncateAt = 1 },
+	"truncated context":       func(p *testProof) { p.truncateAt = 5 },
+	"truncated nonce":         func(p *testProof) { p.truncateAt = 260 },
+	"trailing bytes":          func(p *testProof) { p.trailing = []byte{0} },
+	"invalid hash function":   func(p *testProof) { p.hash = 3 },
+	"invalid modulus":         func(p *testProof) { p.modulus = []byte{1, 0, 0, 0, 0xff, 0xff, 0xff, 0x7f} },
+	"invalid extension":       func(p *testProof) { p.extension = 4 },
+	"invalid blowup":          func(p *testProof) { p.blowup = 6 },
+	"invalid commitments":     func(p *testProof) { p.commitmentSize = 50 },
+	"missing commitments":     func(p *testProof) { p.commitmentSize = 48 },
+	"trace queries mismatch":  func(p *testProof) { p.traceQueries = 3 },
+	"trace length overflows":  func(p *testProof) { p.logTrace = 64 },
+	"truncated fri":           func(p *testProof) { p.truncateAt = 246 },
+	"truncated commitments":   func(p *testProof) { p.truncateAt = 40 },
+	"truncated trace queries": func(p *testProof) { p.truncateAt = 160 },
+	"non-canonical remainder": func(p *testProof) { p.friRemainder = bytes.Repeat([]byte{0xff}, 8) },
+	"partial remainder":       func(p *testProof) { p.friRemainder = make([]byte, 12) },
+}
+
+func TestParseProofMetadata_Error(t *testing.T) {
+	for name, modify := range _parseProofErrorTable {
+		t.Run(name, func(t *testing.T) {
+			p := defaultTestProof()
+			modify(&p)
+
+			_, err := miden.ParseProofMetadata(p.bytes())
+			assert.Error(t, err)
+		})
+	}
+}
+
+var _proofPolicyTable = map[string]struct {
+	policy miden.ProofPolicy
+	ok     bool
+}{
+	"empty":           {policy: miden.ProofPolicy{}, ok: true},
+	"security":        {policy: miden.ProofPolicy{MinSecurityLevel: 96}, ok: true},
+	"low security":    {policy: miden.ProofPolicy{MinSecurityLevel: 128}},
+	"queries":         {policy: miden.ProofPolicy{MinQueries: 28}},
+	"blowup":          {policy: miden.ProofPolicy{MinBlowupFactor: 16}},
+	"grinding":        {policy: miden.ProofPolicy{MinGrindingBits: 20}},
+	"trace length":    {policy: miden.ProofPolicy{MaxTraceLength: 512}},
+	"hash function":   {policy: miden.ProofPolicy{HashFunctions: []miden.HashFunction{miden.HashRpo256}}},
+	"hash functions":  {policy: miden.ProofPolicy{HashFunctions: []miden.HashFunction{miden.HashRpo256, miden.HashBlake3_192}}, ok: true},
+	"all constraints": {policy: miden.ProofPolicy{MinSecurityLevel: 96, MinQueries: 27, MinBlowupFactor: 8, MinGrindingBits: 16, MaxTraceLength: 1024}, ok: true},
+}
+
+func TestProofPolicyCheck(t *testing.T) {
+	m, err := miden.ParseProofMetadata(defaultTestProof().bytes())
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	for name, tc := range _proofPolicyTable {
+		t.Run(name, func(t *testing.T) {
+			err := tc.policy.Check(m)
+			if tc.ok {
+				assert.Nil(t, err)
+			} else {
+				assert.True(t, errors.Is(err, miden.ErrProofPolicy), "%v", err)
+			}
+		})
+	}
+}
+
+func TestVerifyWithPolicy_Rejected(t *testing.T) {
+	assert := assert.New(t)
+
+	policy := miden.ProofPolicy{MinSecurityLevel: 128}
+	ok, err := miden.VerifyWithPolicy(context.Background(), policy, nil, defaultTestProof().bytes(), miden.Input{}, miden.Output{})
+	assert.False(ok)
+	assert.ErrorIs(err, miden.ErrProofPolicy)
+
+	ok, err = miden.VerifyWithPolicy(context.Background(), policy, nil, []byte{0}, miden.Input{}, miden.Output{})
+	assert.False(ok)
+	assert.Error(err)
+	assert.NotErrorIs(err, miden.ErrProofPolicy)
+}
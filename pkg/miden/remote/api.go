@@ -0,0 +1,91 @@
+// Package remote offloads Miden VM proving to a remote service over HTTP.
+//
+// The service accepts POST requests on /compile, /run, /prove, and /verify,
+// which start a job and return it with status 202 Accepted.  The job is then
+// polled with GET /jobs/{id} until its status is done or failed, or cancelled
+// with DELETE /jobs/{id}, making it fail.  Inputs and outputs use the JSON
+// encoding of miden.Input and miden.Output; program hashes are hex encoded, and
+// proofs base64 encoded.
+//
+// Services configured with a token reject requests without an Authorization
+// header holding it as a bearer token with status 401 Unauthorized.
+package remote
+
+import (
+	"encoding/hex"
+	"encoding/json"
+
+	"github.com/qredo/verifiable-oracles/pkg/miden"
+)
+
+// Status is the status of a job.
+type Status string
+
+const (
+	StatusQueued  Status = "queued"
+	StatusRunning Status = "running"
+	StatusDone    Status = "done"
+	StatusFailed  Status = "failed"
+)
+
+func (s Status) finished() bool {
+	return s == StatusDone || s == StatusFailed
+}
+
+// Hash is a program hash encoded as hex in JSON.
+type Hash miden.ProgramHash
+
+func (h Hash) MarshalJSON() ([]byte, error) {
+	return json.Marshal(hex.EncodeToString(h))
+}
+
+func (h *Hash) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	b, err := hex.DecodeString(s)
+	*h = b
+	return err
+}
+
+// Request is the body of POST requests.  Fields not needed by the endpoint are
+// ignored.
+type Request struct {
+	Assembly    string       `json:"assembly,omitempty"`
+	Input       miden.Input  `json:"input"`
+	ProgramHash Hash         `json:"program_hash,omitempty"`
+	Output      miden.Output `json:"output"`
+	Proof       []byte       `json:"proof,omitempty"`
+}
+
+// Result is the result of a successful job.
+type Result struct {
+	ProgramHash Hash          `json:"program_hash,omitempty"`
+	Output      *miden.Output `json:"output,omitempty"`
+	Proof       []byte        `json:"proof,omitempty"`
+	Verified    bool          `json:"verified,omitempty"`
+}
+
+// Job is a compile, run, prove, or verify request processed by the service.
+type Job struct {
+	ID     string  `json:"id"`
+	Kind   string  `json:"kind"`
+	Status Status  `json:"status"`
+	Result *Result `json:"result,omitempty"`
+	Error  string  `json:"error,omitempty"`
+}
+
+// Body of error responses
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// Job kinds, named after the endpoints
+const (
+	_compile = "compile"
+	_run     = "run"
+	_prove   = "prove"
+	_verify  = "verify"
+)
@@ -0,0 +1,211 @@
+package remote
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/qredo/verifiable-oracles/pkg/miden"
+)
+
+// DefaultPollInterval is the interval between job status requests.
+const DefaultPollInterval = 100 * time.Millisecond
+
+// Time allowed to cancel the job of a cancelled request
+const _cancelTimeout = 5 * time.Second
+
+// Error is a job failure reported by the proving service.
+type Error struct {
+	Kind    string
+	Message string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("remote: %s failed: %s", e.Kind, e.Message)
+}
+
+// Client is a miden.Backend using a remote proving service.
+type Client struct {
+	baseURL      string
+	HTTPClient   *http.Client
+	PollInterval time.Duration
+	// Bearer token sent to services configured with a token
+	Token string
+}
+
+// NewClient returns a Client of the proving service at baseURL, e.g.
+// http://prover:8080.
+func NewClient(baseURL string) *Client {
+	return &Client{
+		baseURL:      strings.TrimSuffix(baseURL, "/"),
+		HTTPClient:   http.DefaultClient,
+		PollInterval: DefaultPollInterval,
+	}
+}
+
+// NewBackend returns the local Miden VM backend if proverURL is empty, or a
+// Client of the proving service at proverURL otherwise.
+func NewBackend(proverURL string) miden.Backend {
+	if proverURL == "" {
+		return miden.Local{}
+	}
+	return NewClient(proverURL)
+}
+
+func (c *Client) Compile(ctx context.Context, assembly miden.Assembly) (miden.ProgramHash, error) {
+	result, err := c.do(ctx, _compile, Request{Assembly: string(assembly)})
+	if err != nil {
+		return nil, err
+	}
+	return miden.ProgramHash(result.ProgramHash), nil
+}
+
+func (c *Client) Run(ctx context.Context, assembly miden.Assembly, input miden.Input) (miden.ProgramHash, miden.Output, error) {
+	result, err := c.do(ctx, _run, Request{Assembly: string(assembly), Input: input})
+	if err != nil {
+		return nil, miden.Output{}, err
+	}
+	return miden.ProgramHash(result.ProgramHash), result.output(), nil
+}
+
+func (c *Client) Prove(ctx context.Context, assembly miden.Assembly, input miden.Input) (miden.ProgramHash, miden.Output, miden.Proof, error) {
+	result, err := c.do(ctx, _prove, Request{Assembly: string(assembly), Input: input})
+	if err != nil {
+		return nil, miden.Output{}, nil, err
+	}
+	return miden.ProgramHash(result.ProgramHash), result.output(), result.Proof, nil
+}
+
+func (c *Client) Verify(ctx context.Context, programHash miden.ProgramHash, proof miden.Proof, input miden.Input, output miden.Output) (bool, error) {
+	result, err := c.do(ctx, _verify, Request{
+		ProgramHash: Hash(programHash),
+		Proof:       proof,
+		Input:       input,
+		Output:      output,
+	})
+	if err != nil {
+		return false, err
+	}
+	return result.Verified, nil
+}
+
+// Submit starts a job on the service.
+func (c *Client) Submit(ctx context.Context, kind string, req Request) (Job, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return Job{}, err
+	}
+
+	var job Job
+	err = c.request(ctx, http.MethodPost, "/"+kind, body, http.StatusAccepted, &job)
+	return job, err
+}
+
+// Job returns the current state of a job.
+func (c *Client) Job(ctx context.Context, id string) (Job, error) {
+	var job Job
+	err := c.request(ctx, http.MethodGet, "/jobs/"+url.PathEscape(id), nil, http.StatusOK, &job)
+	return job, err
+}
+
+// Cancel cancels a job and returns its state.  The job fails once the
+// service stops processing it.
+func (c *Client) Cancel(ctx context.Context, id string) (Job, error) {
+	var job Job
+	err := c.request(ctx, http.MethodDelete, "/jobs/"+url.PathEscape(id), nil, http.StatusOK, &job)
+	return job, err
+}
+
+// Wait polls a job until it finishes.  On error, Wait returns the last
+// known state of the job.
+func (c *Client) Wait(ctx context.Context, id string) (Job, error) {
+	ticker := time.NewTicker(c.PollInterval)
+	defer ticker.Stop()
+
+	var last Job
+	for {
+		job, err := c.Job(ctx, id)
+		if err != nil {
+			return last, err
+		}
+		if job.Status.finished() {
+			return job, nil
+		}
+		last = job
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return last, ctx.Err()
+		}
+	}
+}
+
+func (c *Client) do(ctx context.Context, kind string, req Request) (*Result, error) {
+	job, err := c.Submit(ctx, kind, req)
+	if err != nil {
+		return nil, err
+	}
+	id := job.ID
+	if job, err = c.Wait(ctx, id); err != nil {
+		if ctx.Err() != nil {
+			// Best effort, so that the service stops working for nobody
+			cancelCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), _cancelTimeout)
+			c.Cancel(cancelCtx, id)
+			cancel()
+		}
+		return nil, err
+	}
+
+	if job.Status == StatusFailed {
+		return nil, &Error{Kind: kind, Message: job.Error}
+	}
+	if job.Result == nil {
+		return nil, fmt.Errorf("remote: %s job %s has no result", kind, job.ID)
+	}
+	return job.Result, nil
+}
+
+func (c *Client) request(ctx context.Context, method string, path string, body []byte, status int, v any) error {
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != status {
+		var e errorResponse
+		if json.NewDecoder(resp.Body).Decode(&e) != nil || e.Error == "" {
+			e.Error = resp.Status
+		}
+		return fmt.Errorf("remote: %s %s: %s", method, path, e.Error)
+	}
+	if err = json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("remote: invalid response: %w", err)
+	}
+	return nil
+}
+
+func (r *Result) output() miden.Output {
+	if r.Output == nil {
+		return miden.Output{}
+	}
+	return *r.Output
+}
+
+// Type Assertions
+var _ miden.Backend = (*Client)(nil)
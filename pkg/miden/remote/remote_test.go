@@ -0,0 +1,319 @@
+package remote_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	field "github.com/qredo/verifiable-oracles/pkg/goldilocks"
+	"github.com/qredo/verifiable-oracles/pkg/miden"
+	"github.com/qredo/verifiable-oracles/pkg/miden/midentest"
+	"github.com/qredo/verifiable-oracles/pkg/miden/remote"
+)
+
+func TestMain(m *testing.M) {
+	midentest.Main(m)
+}
+
+var _assembly = miden.Assembly("begin\n    add\nend\n")
+
+func newTestServer(t *testing.T, backend miden.Backend, config remote.ServerConfig) *remote.Client {
+	t.Helper()
+
+	server := remote.NewServer(backend, config)
+	httpServer := httptest.NewServer(server)
+	t.Cleanup(func() {
+		httpServer.Close()
+		server.Close()
+	})
+
+	c := remote.NewClient(httpServer.URL + "/")
+	c.PollInterval = time.Millisecond
+	return c
+}
+
+func testInput() miden.Input {
+	return miden.Input{OperandStack: field.Vector{field.NewElement(1), field.NewElement(2)}}
+}
+
+func testOutput(sum uint64) miden.Output {
+	return miden.Output{Stack: miden.StackOutput(field.NewElement(sum)), OverflowAddrs: field.Vector{}}
+}
+
+func TestClientFakeMiden(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	hash := miden.ProgramHash(bytes.Repeat([]byte{0xab}, 32))
+	midentest.Install(t, midentest.Config{
+		Programs: []midentest.Program{{
+			Assembly: _assembly,
+			Hash:     hash,
+			Runs:     []midentest.Run{{Input: testInput(), Output: testOutput(3)}},
+		}},
+	})
+	c := newTestServer(t, miden.Local{}, remote.ServerConfig{Workers: 2})
+
+	h, err := c.Compile(ctx, _assembly)
+	assert.Nil(err)
+	assert.Equal(hash, h)
+
+	h, output, err := c.Run(ctx, _assembly, testInput())
+	assert.Nil(err)
+	assert.Equal(hash, h)
+	assert.Equal(testOutput(3), output)
+
+	h, output, proof, err := c.Prove(ctx, _assembly, testInput())
+	assert.Nil(err)
+	assert.Equal(hash, h)
+	assert.Equal(testOutput(3), output)
+
+	ok, err := c.Verify(ctx, hash, proof, testInput(), output)
+	assert.True(ok)
+	assert.Nil(err)
+
+	ok, err = c.Verify(ctx, hash, proof, testInput(), testOutput(4))
+	assert.False(ok)
+	var remoteError *remote.Error
+	if assert.ErrorAs(err, &remoteError) {
+		assert.Equal("verify", remoteError.Kind)
+	}
+
+	_, _, err = c.Run(ctx, miden.Assembly("begin\n    mul\nend\n"), testInput())
+	if assert.ErrorAs(err, &remoteError) {
+		assert.Contains(remoteError.Message, "unknown program")
+	}
+}
+
+// Backend blocking until released, failing with err
+type stubBackend struct {
+	release chan struct{}
+	err     error
+}
+
+func (b *stubBackend) wait(ctx context.Context) error {
+	select {
+	case <-b.release:
+		return b.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (b *stubBackend) Compile(ctx context.Context, assembly miden.Assembly) (miden.ProgramHash, error) {
+	return miden.ProgramHash{1}, b.wait(ctx)
+}
+
+func (b *stubBackend) Run(ctx context.Context, assembly miden.Assembly, input miden.Input) (miden.ProgramHash, miden.Output, error) {
+	return miden.ProgramHash{1}, testOutput(3), b.wait(ctx)
+}
+
+func (b *stubBackend) Prove(ctx context.Context, assembly miden.Assembly, input miden.Input) (miden.ProgramHash, miden.Output, miden.Proof, error) {
+	return miden.ProgramHash{1}, testOutput(3), miden.Proof{2}, b.wait(ctx)
+}
+
+func (b *stubBackend) Verify(ctx context.Context, programHash miden.ProgramHash, proof miden.Proof, input miden.Input, output miden.Output) (bool, error) {
+	return b.err == nil, b.wait(ctx)
+}
+
+func TestClientJobStatus(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	backend := &stubBackend{release: make(chan struct{})}
+	c := newTestServer(t, backend, remote.ServerConfig{Workers: 1, MaxJobs: 2})
+
+	first, err := c.Submit(ctx, "prove", remote.Request{Assembly: string(_assembly)})
+	assert.Nil(err)
+	assert.Equal(remote.StatusQueued, first.Status)
+	assert.NotEmpty(first.ID)
+
+	second, err := c.Submit(ctx, "compile", remote.Request{Assembly: string(_assembly)})
+	assert.Nil(err)
+
+	// Both workers slots and queue are taken
+	_, err = c.Submit(ctx, "run", remote.Request{Assembly: string(_assembly)})
+	assert.ErrorContains(err, "too many jobs")
+
+	waitCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	job, err := c.Wait(waitCtx, first.ID)
+	cancel()
+	assert.ErrorIs(err, context.DeadlineExceeded)
+	assert.Equal(remote.StatusRunning, job.Status)
+
+	close(backend.release)
+
+	job, err = c.Wait(ctx, first.ID)
+	assert.Nil(err)
+	assert.Equal(remote.StatusDone, job.Status)
+	assert.Equal(remote.Hash{1}, job.Result.ProgramHash)
+	assert.Equal(testOutput(3), *job.Result.Output)
+	assert.Equal([]byte{2}, job.Result.Proof)
+
+	job, err = c.Wait(ctx, second.ID)
+	assert.Nil(err)
+	assert.Equal("compile", job.Kind)
+	assert.Nil(job.Result.Output)
+}
+
+func TestClientCancel(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	backend := &stubBackend{release: make(chan struct{})}
+	c := newTestServer(t, backend, remote.ServerConfig{Workers: 1})
+
+	// Cancelling the request cancels the running job, freeing the worker
+	proveCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	_, _, _, err := c.Prove(proveCtx, _assembly, testInput())
+	cancel()
+	assert.ErrorIs(err, context.DeadlineExceeded)
+
+	job, err := c.Submit(ctx, "compile", remote.Request{Assembly: string(_assembly)})
+	assert.Nil(err)
+	for deadline := time.Now().Add(time.Second); job.Status != remote.StatusRunning && time.Now().Before(deadline); {
+		job, err = c.Job(ctx, job.ID)
+		assert.Nil(err)
+	}
+	assert.Equal(remote.StatusRunning, job.Status)
+
+	job, err = c.Cancel(ctx, job.ID)
+	assert.Nil(err)
+	assert.Equal("compile", job.Kind)
+
+	job, err = c.Wait(ctx, job.ID)
+	assert.Nil(err)
+	assert.Equal(remote.StatusFailed, job.Status)
+	assert.Contains(job.Error, "context canceled")
+
+	_, err = c.Cancel(ctx, "missing")
+	assert.ErrorContains(err, "job not found")
+}
+
+func TestClientMaxJobs(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	backend := &stubBackend{release: make(chan struct{})}
+	c := newTestServer(t, backend, remote.ServerConfig{})
+
+	var err error
+	for i := 0; err == nil && i < 1000; i++ {
+		_, err = c.Submit(ctx, "compile", remote.Request{Assembly: string(_assembly)})
+	}
+	assert.ErrorContains(err, "too many jobs")
+}
+
+func TestClientBackendError(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	backend := &stubBackend{release: make(chan struct{}), err: errors.New("out of memory")}
+	close(backend.release)
+	c := newTestServer(t, backend, remote.ServerConfig{})
+
+	_, _, _, err := c.Prove(ctx, _assembly, testInput())
+	assert.ErrorContains(err, "out of memory")
+
+	ok, err := c.Verify(ctx, miden.ProgramHash{1}, miden.Proof{2}, testInput(), testOutput(3))
+	assert.False(ok)
+	assert.ErrorContains(err, "out of memory")
+}
+
+func TestClientRetention(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	backend := &stubBackend{release: make(chan struct{})}
+	close(backend.release)
+	c := newTestServer(t, backend, remote.ServerConfig{Retention: time.Millisecond})
+
+	job, err := c.Submit(ctx, "compile", remote.Request{Assembly: string(_assembly)})
+	assert.Nil(err)
+
+	for job.Status != remote.StatusDone && err == nil {
+		job, err = c.Job(ctx, job.ID)
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	_, err = c.Job(ctx, job.ID)
+	assert.ErrorContains(err, "job not found")
+}
+
+var _serverErrorTable = map[string]struct {
+	method string
+	path   string
+	body   string
+	status int
+}{
+	"unknown job":        {method: http.MethodGet, path: "/jobs/missing", status: http.StatusNotFound},
+	"get submit":         {method: http.MethodGet, path: "/prove", status: http.StatusMethodNotAllowed},
+	"post job":           {method: http.MethodPost, path: "/jobs/missing", status: http.StatusMethodNotAllowed},
+	"delete unknown job": {method: http.MethodDelete, path: "/jobs/missing", status: http.StatusNotFound},
+	"invalid json":       {method: http.MethodPost, path: "/run", body: "{", status: http.StatusBadRequest},
+	"missing assembly":   {method: http.MethodPost, path: "/compile", body: "{}", status: http.StatusBadRequest},
+	"invalid input":      {method: http.MethodPost, path: "/run", body: `{"assembly":"begin end","input":{"operand_stack":["x"]}}`, status: http.StatusBadRequest},
+	"invalid hash":       {method: http.MethodPost, path: "/verify", body: `{"program_hash":"xyz"}`, status: http.StatusBadRequest},
+	"unknown endpoint":   {method: http.MethodPost, path: "/bundle", body: "{}", status: http.StatusNotFound},
+	"invalid proof type": {method: http.MethodPost, path: "/verify", body: `{"proof":1}`, status: http.StatusBadRequest},
+}
+
+func TestServer_Error(t *testing.T) {
+	server := remote.NewServer(&stubBackend{release: make(chan struct{})}, remote.ServerConfig{})
+	defer server.Close()
+
+	for name, tc := range _serverErrorTable {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+			w := httptest.NewRecorder()
+			server.ServeHTTP(w, req)
+			assert.Equal(t, tc.status, w.Code)
+		})
+	}
+}
+
+func TestServer_RequestTooLarge(t *testing.T) {
+	server := remote.NewServer(&stubBackend{release: make(chan struct{})}, remote.ServerConfig{MaxRequestBytes: 64})
+	defer server.Close()
+
+	body := `{"assembly":"` + strings.Repeat("add ", 64) + `"}`
+	req := httptest.NewRequest(http.MethodPost, "/compile", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+}
+
+func TestClientToken(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	backend := &stubBackend{release: make(chan struct{})}
+	close(backend.release)
+	c := newTestServer(t, backend, remote.ServerConfig{Token: "secret"})
+
+	_, err := c.Compile(ctx, _assembly)
+	assert.ErrorContains(err, "unauthorized")
+
+	c.Token = "wrong"
+	_, err = c.Compile(ctx, _assembly)
+	assert.ErrorContains(err, "unauthorized")
+
+	c.Token = "secret"
+	hash, err := c.Compile(ctx, _assembly)
+	assert.Nil(err)
+	assert.Equal(miden.ProgramHash{1}, hash)
+}
+
+func TestNewBackend(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(miden.Local{}, remote.NewBackend(""))
+	assert.IsType(&remote.Client{}, remote.NewBackend("http://localhost:8080"))
+}
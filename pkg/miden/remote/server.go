@@ -0,0 +1,317 @@
+package remote
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/qredo/verifiable-oracles/pkg/miden"
+)
+
+// ServerConfig configures a Server.
+type ServerConfig struct {
+	// Maximum number of jobs processed concurrently, 1 if zero
+	Workers int
+	// Maximum number of unfinished jobs, 64 if zero
+	MaxJobs int
+	// How long finished jobs can be polled, 10 minutes if zero
+	Retention time.Duration
+	// Maximum size of request bodies in bytes, 16 MiB if zero
+	MaxRequestBytes int64
+	// Bearer token required in the Authorization header of every request.
+	// If empty, requests are not authenticated, and the server must only be
+	// reachable from trusted hosts, e.g. on a loopback address.
+	Token string
+}
+
+// Server is an http.Handler serving the proving service API on top of a
+// miden.Backend.
+type Server struct {
+	backend miden.Backend
+	config  ServerConfig
+	mux     *http.ServeMux
+	slots   chan struct{}
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu   sync.Mutex
+	jobs map[string]*serverJob
+}
+
+type serverJob struct {
+	Job
+	finished time.Time
+	cancel   context.CancelFunc
+}
+
+// NewServer returns a Server processing jobs with backend.
+func NewServer(backend miden.Backend, config ServerConfig) *Server {
+	if config.Workers <= 0 {
+		config.Workers = 1
+	}
+	if config.MaxJobs <= 0 {
+		config.MaxJobs = 64
+	}
+	if config.Retention <= 0 {
+		config.Retention = 10 * time.Minute
+	}
+	if config.MaxRequestBytes <= 0 {
+		config.MaxRequestBytes = 16 << 20
+	}
+
+	s := &Server{
+		backend: backend,
+		config:  config,
+		mux:     http.NewServeMux(),
+		slots:   make(chan struct{}, config.Workers),
+		jobs:    make(map[string]*serverJob),
+	}
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+
+	for _, kind := range []string{_compile, _run, _prove, _verify} {
+		s.mux.HandleFunc("/"+kind, s.handleSubmit(kind))
+	}
+	s.mux.HandleFunc("/jobs/", s.handleJob)
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if s.config.Token != "" {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.config.Token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeError(w, http.StatusUnauthorized, "unauthorized")
+			return
+		}
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+// Close cancels running jobs and waits for them to finish.
+func (s *Server) Close() {
+	s.cancel()
+	s.wg.Wait()
+}
+
+func (s *Server) handleSubmit(kind string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+
+		var req Request
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, s.config.MaxRequestBytes)).Decode(&req); err != nil {
+			status := http.StatusBadRequest
+			var maxBytesError *http.MaxBytesError
+			if errors.As(err, &maxBytesError) {
+				status = http.StatusRequestEntityTooLarge
+			}
+			writeError(w, status, "invalid request: "+err.Error())
+			return
+		}
+		if kind != _verify && req.Assembly == "" {
+			writeError(w, http.StatusBadRequest, "invalid request: missing assembly")
+			return
+		}
+
+		job, err := s.submit(kind, req)
+		if err != nil {
+			writeError(w, http.StatusServiceUnavailable, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusAccepted, job)
+	}
+}
+
+func (s *Server) handleJob(w http.ResponseWriter, r *http.Request) {
+	var lookup func(id string) (Job, bool)
+	switch r.Method {
+	case http.MethodGet:
+		lookup = s.lookup
+	case http.MethodDelete:
+		lookup = s.cancelJob
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	job, ok := lookup(strings.TrimPrefix(r.URL.Path, "/jobs/"))
+	if !ok {
+		writeError(w, http.StatusNotFound, "job not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, job)
+}
+
+func (s *Server) submit(kind string, req Request) (Job, error) {
+	id, err := newJobID()
+	if err != nil {
+		return Job{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.expire()
+	if s.unfinished() >= s.config.MaxJobs {
+		return Job{}, errors.New("too many jobs")
+	}
+
+	ctx, cancel := context.WithCancel(s.ctx)
+	job := &serverJob{Job: Job{ID: id, Kind: kind, Status: StatusQueued}, cancel: cancel}
+	s.jobs[id] = job
+
+	s.wg.Add(1)
+	go s.process(ctx, job.Job, req)
+	return job.Job, nil
+}
+
+func (s *Server) process(ctx context.Context, job Job, req Request) {
+	defer s.wg.Done()
+
+	select {
+	case s.slots <- struct{}{}:
+		defer func() { <-s.slots }()
+	case <-ctx.Done():
+		s.finish(job.ID, nil, ctx.Err())
+		return
+	}
+
+	s.update(job.ID, StatusRunning)
+	result, err := s.execute(ctx, job.Kind, req)
+	s.finish(job.ID, result, err)
+}
+
+func (s *Server) execute(ctx context.Context, kind string, req Request) (*Result, error) {
+	assembly := miden.Assembly(req.Assembly)
+
+	switch kind {
+	case _compile:
+		hash, err := s.backend.Compile(ctx, assembly)
+		return &Result{ProgramHash: Hash(hash)}, err
+	case _run:
+		hash, output, err := s.backend.Run(ctx, assembly, req.Input)
+		return &Result{ProgramHash: Hash(hash), Output: &output}, err
+	case _prove:
+		hash, output, proof, err := s.backend.Prove(ctx, assembly, req.Input)
+		return &Result{ProgramHash: Hash(hash), Output: &output, Proof: proof}, err
+	default:
+		ok, err := s.backend.Verify(ctx, miden.ProgramHash(req.ProgramHash), req.Proof, req.Input, req.Output)
+		if err == nil && !ok {
+			err = errors.New("verification failed")
+		}
+		return &Result{Verified: ok}, err
+	}
+}
+
+func (s *Server) update(id string, status Status) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if job, ok := s.jobs[id]; ok {
+		job.Status = status
+	}
+}
+
+func (s *Server) finish(id string, result *Result, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, ok := s.jobs[id]
+	if !ok {
+		return
+	}
+
+	job.cancel()
+	job.finished = time.Now()
+	if err != nil {
+		job.Status, job.Error = StatusFailed, errorMessage(err)
+		return
+	}
+	job.Status, job.Result = StatusDone, result
+}
+
+func (s *Server) lookup(id string) (Job, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.expire()
+	job, ok := s.jobs[id]
+	if !ok {
+		return Job{}, false
+	}
+	return job.Job, true
+}
+
+// Cancels an unfinished job, which fails once its backend call returns.
+func (s *Server) cancelJob(id string) (Job, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.expire()
+	job, ok := s.jobs[id]
+	if !ok {
+		return Job{}, false
+	}
+	job.cancel()
+	return job.Job, true
+}
+
+// Removes finished jobs past their retention.  Must be called with s.mu held.
+func (s *Server) expire() {
+	for id, job := range s.jobs {
+		if job.Status.finished() && time.Since(job.finished) > s.config.Retention {
+			delete(s.jobs, id)
+		}
+	}
+}
+
+// Must be called with s.mu held.
+func (s *Server) unfinished() int {
+	n := 0
+	for _, job := range s.jobs {
+		if !job.Status.finished() {
+			n++
+		}
+	}
+	return n
+}
+
+// Includes the Miden VM error output, which is more useful to remote clients
+// than the exit status.
+func errorMessage(err error) string {
+	var exitError *exec.ExitError
+	if errors.As(err, &exitError) && len(exitError.Stderr) > 0 {
+		return err.Error() + ": " + strings.TrimSpace(string(exitError.Stderr))
+	}
+	return err.Error()
+}
+
+func newJobID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, errorResponse{Error: msg})
+}
@@ -0,0 +1,10 @@
+//go:build !(linux || darwin)
+
+package miden
+
+import "os"
+
+// Peak memory is not available on this platform.
+func peakMemory(state *os.ProcessState) int64 {
+	return 0
+}
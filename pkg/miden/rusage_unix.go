@@ -0,0 +1,23 @@
+//go:build linux || darwin
+
+package miden
+
+import (
+	"os"
+	"runtime"
+	"syscall"
+)
+
+// Returns the peak resident memory of a finished process in bytes.
+func peakMemory(state *os.ProcessState) int64 {
+	usage, ok := state.SysUsage().(*syscall.Rusage)
+	if !ok {
+		return 0
+	}
+
+	// Linux reports kilobytes, macOS bytes
+	if runtime.GOOS == "linux" {
+		return usage.Maxrss * 1024
+	}
+	return usage.Maxrss
+}
@@ -0,0 +1,176 @@
+package miden
+
+import (
+	"io"
+	"slices"
+
+	"github.com/qredo/verifiable-oracles/pkg/encoding/flat"
+	field "github.com/qredo/verifiable-oracles/pkg/goldilocks"
+	ffio "github.com/qredo/verifiable-oracles/pkg/io"
+)
+
+// WordSize is the number of elements in a Miden VM word.
+const WordSize = 4
+
+// OperandStack returns the Input.OperandStack placing values on the operand
+// stack top first, i.e. values[0] ends up on top.  Miden VM reads operand
+// stack inputs bottom first.
+func OperandStack(values ...field.Element) field.Vector {
+	v := slices.Clone(field.Vector(values))
+	slices.Reverse(v)
+	return v
+}
+
+// StackOutput returns the Output.Stack holding values top first, padded with
+// zeros to StackTopSize elements.
+func StackOutput(values ...field.Element) field.Vector {
+	v := make(field.Vector, max(len(values), StackTopSize))
+	copy(v, values)
+	return v
+}
+
+// StackWriter collects values in Miden VM stack order: the first value
+// written ends up on top of the stack, so that a program pops values in the
+// order they were written.
+type StackWriter struct {
+	values field.Vector
+}
+
+// WriteElement writes a single value.
+func (w *StackWriter) WriteElement(e field.Element) error {
+	w.values = append(w.values, e)
+	return nil
+}
+
+// WriteVector writes values in order.
+func (w *StackWriter) WriteVector(v field.Vector) (int, error) {
+	w.values = append(w.values, v...)
+	return len(v), nil
+}
+
+// WriteWord writes a word, so that its elements end up on the stack as
+// w[0], w[1], w[2], w[3], top first.
+func (w *StackWriter) WriteWord(word [WordSize]field.Element) {
+	w.values = append(w.values, word[:]...)
+}
+
+// Encode writes the flat encoding of x.
+func (w *StackWriter) Encode(x any) (int, error) {
+	return flat.NewEncoder(w).Encode(x)
+}
+
+// EncodeBytes writes the flat encoding of b.
+func (w *StackWriter) EncodeBytes(b []byte) (int, error) {
+	return flat.NewEncoder(w).EncodeBytes(b)
+}
+
+// Align pads the written values with zeros to a multiple of WordSize, so
+// that the next value starts at a word boundary.
+func (w *StackWriter) Align() {
+	for len(w.values)%WordSize != 0 {
+		w.values = append(w.values, field.Element{})
+	}
+}
+
+// Len returns the number of written values.
+func (w *StackWriter) Len() int {
+	return len(w.values)
+}
+
+// Stack returns the written values top first.
+func (w *StackWriter) Stack() field.Vector {
+	return slices.Clone(w.values)
+}
+
+// OperandStack returns the written values as Input.OperandStack.
+func (w *StackWriter) OperandStack() field.Vector {
+	return OperandStack(w.values...)
+}
+
+// AdviceStack returns the written values as Input.AdviceStack, which Miden
+// VM reads top first.
+func (w *StackWriter) AdviceStack() field.Vector {
+	return w.Stack()
+}
+
+// StackReader pops values from a stack given top first, such as
+// Output.Stack.
+type StackReader struct {
+	stack  field.Vector
+	offset int
+}
+
+// NewStackReader returns a StackReader popping values from stack, top first.
+func NewStackReader(stack field.Vector) *StackReader {
+	return &StackReader{stack: stack}
+}
+
+// NewOutputReader returns a StackReader popping values from the operand
+// stack at the end of an execution.
+func NewOutputReader(output Output) *StackReader {
+	return NewStackReader(output.Stack)
+}
+
+// Len returns the number of values left on the stack.
+func (r *StackReader) Len() int {
+	return len(r.stack) - r.offset
+}
+
+// ReadElement pops a single value, or returns io.EOF if the stack is empty.
+func (r *StackReader) ReadElement() (field.Element, error) {
+	if r.Len() <= 0 {
+		return field.Element{}, io.EOF
+	}
+
+	e := r.stack[r.offset]
+	r.offset++
+	return e, nil
+}
+
+// ReadVector pops up to len(v) values into v, or returns io.EOF if the stack
+// is empty.
+func (r *StackReader) ReadVector(v field.Vector) (int, error) {
+	if len(v) == 0 {
+		return 0, nil
+	}
+	if r.Len() <= 0 {
+		return 0, io.EOF
+	}
+
+	n := copy(v, r.stack[r.offset:])
+	r.offset += n
+	return n, nil
+}
+
+// ReadWord pops a word written by StackWriter.WriteWord.
+func (r *StackReader) ReadWord() (word [WordSize]field.Element, err error) {
+	if r.Len() < WordSize {
+		return word, io.ErrUnexpectedEOF
+	}
+
+	copy(word[:], r.stack[r.offset:])
+	r.offset += WordSize
+	return word, nil
+}
+
+// Decode pops the flat encoding of x.
+func (r *StackReader) Decode(x any) (int, error) {
+	return flat.NewDecoder(r).Decode(x)
+}
+
+// DecodeBytes pops the flat encoding of b.
+func (r *StackReader) DecodeBytes(b []byte) (int, error) {
+	return flat.NewDecoder(r).DecodeBytes(b)
+}
+
+// Align skips the padding StackWriter.Align added, so that the next value
+// is read from a word boundary.
+func (r *StackReader) Align() {
+	r.offset = min(len(r.stack), (r.offset+WordSize-1)/WordSize*WordSize)
+}
+
+// Type Assertions
+var _ ffio.ElementWriter = (*StackWriter)(nil)
+var _ ffio.VectorWriter = (*StackWriter)(nil)
+var _ ffio.ElementReader = (*StackReader)(nil)
+var _ ffio.VectorReader = (*StackReader)(nil)
@@ -0,0 +1,145 @@
+package miden_test
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	field "github.com/qredo/verifiable-oracles/pkg/goldilocks"
+	"github.com/qredo/verifiable-oracles/pkg/miden"
+)
+
+func elems(values ...uint64) field.Vector {
+	v := make(field.Vector, len(values))
+	for i, x := range values {
+		v[i].SetUint64(x)
+	}
+	return v
+}
+
+func TestOperandStack(t *testing.T) {
+	assert := assert.New(t)
+
+	values := elems(1, 2, 3)
+	assert.Equal(elems(3, 2, 1), miden.OperandStack(values...))
+	assert.Equal(elems(1, 2, 3), values)
+	assert.Empty(miden.OperandStack())
+}
+
+func TestStackOutput(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(out(elems(1, 2)...), miden.StackOutput(elems(1, 2)...))
+	assert.Equal(_defaultOutput, miden.StackOutput())
+
+	long := elems(1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17)
+	assert.Equal(long, miden.StackOutput(long...))
+}
+
+func TestStackWriter(t *testing.T) {
+	assert := assert.New(t)
+
+	w := &miden.StackWriter{}
+	assert.Nil(w.WriteElement(elems(1)[0]))
+	n, err := w.WriteVector(elems(2, 3))
+	assert.Equal(2, n)
+	assert.Nil(err)
+
+	assert.Equal(3, w.Len())
+	assert.Equal(elems(1, 2, 3), w.Stack())
+	assert.Equal(elems(1, 2, 3), w.AdviceStack())
+	assert.Equal(elems(3, 2, 1), w.OperandStack())
+
+	w.Align()
+	assert.Equal(elems(1, 2, 3, 0), w.Stack())
+	w.Align()
+	assert.Equal(4, w.Len())
+
+	w.WriteWord([4]field.Element(elems(5, 6, 7, 8)))
+	assert.Equal(elems(1, 2, 3, 0, 5, 6, 7, 8), w.Stack())
+}
+
+func TestStackReader(t *testing.T) {
+	assert := assert.New(t)
+
+	r := miden.NewOutputReader(miden.Output{Stack: elems(1, 2, 3, 4, 5, 6, 7, 8, 9, 10)})
+
+	e, err := r.ReadElement()
+	assert.Nil(err)
+	assert.Equal(elems(1)[0], e)
+
+	r.Align()
+	word, err := r.ReadWord()
+	assert.Nil(err)
+	assert.Equal([4]field.Element(elems(5, 6, 7, 8)), word)
+
+	r.Align()
+	_, err = r.ReadWord()
+	assert.Equal(io.ErrUnexpectedEOF, err)
+
+	v := make(field.Vector, 4)
+	n, err := r.ReadVector(v)
+	assert.Nil(err)
+	assert.Equal(elems(9, 10), v[:n])
+
+	_, err = r.ReadVector(v)
+	assert.Equal(io.EOF, err)
+}
+
+func TestStackRoundTrip(t *testing.T) {
+	assert := assert.New(t)
+
+	w := &miden.StackWriter{}
+	_, err := w.Encode(uint64(0xdeadbeefcafe))
+	assert.Nil(err)
+	_, err = w.Encode(uint8(7))
+	assert.Nil(err)
+	w.Align()
+	_, err = w.EncodeBytes([]byte("oracle"))
+	assert.Nil(err)
+
+	r := miden.NewStackReader(w.Stack())
+
+	var (
+		x uint64
+		y uint8
+	)
+	_, err = r.Decode(&x)
+	assert.Nil(err)
+	_, err = r.Decode(&y)
+	assert.Nil(err)
+	r.Align()
+	b := make([]byte, 6)
+	n, err := r.DecodeBytes(b)
+	assert.Nil(err)
+	assert.Equal(6, n)
+
+	assert.Equal(uint64(0xdeadbeefcafe), x)
+	assert.Equal(uint8(7), y)
+	assert.Equal([]byte("oracle"), b)
+	assert.Equal(0, r.Len())
+
+	_, err = r.ReadElement()
+	assert.Equal(io.EOF, err)
+}
+
+func TestStackOrder(t *testing.T) {
+	needsMiden(t)
+	assert := assert.New(t)
+
+	// sub pops b, then a, and pushes a - b
+	w := &miden.StackWriter{}
+	w.WriteVector(elems(1, 3))
+
+	_, output, err := miden.Run(context.Background(), []byte("begin\n    sub\nend"), miden.Input{OperandStack: w.OperandStack()})
+	if !handleExitError(t, err) {
+		return
+	}
+
+	r := miden.NewOutputReader(output)
+	e, err := r.ReadElement()
+	assert.Nil(err)
+	assert.Equal(elems(2)[0], e)
+}
@@ -0,0 +1,256 @@
+package miden
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// KeepPolicy specifies when the working directory of a Miden VM run is kept
+// instead of being removed.
+type KeepPolicy int
+
+const (
+	// Always remove working directories
+	KeepNever KeepPolicy = iota
+	// Keep working directories of runs where a Miden VM command failed
+	KeepOnFailure
+	// Keep all working directories
+	KeepAlways
+)
+
+// ManifestName is the name of the manifest file written to kept working
+// directories.
+const ManifestName = "manifest.json"
+
+// WorkDirConfig configures the working directories in which the assembly,
+// input, output, and proof files of Miden VM runs are written.
+type WorkDirConfig struct {
+	// Directory under which working directories are created, os.TempDir() if
+	// empty.  The directory is created if it does not exist.
+	Root string
+	// When to keep working directories, KeepNever by default
+	Keep KeepPolicy
+}
+
+var _workDirConfig atomic.Pointer[WorkDirConfig]
+
+// SetWorkDirConfig sets the process-wide working directory configuration.
+// It affects runs started afterwards.
+func SetWorkDirConfig(config WorkDirConfig) {
+	_workDirConfig.Store(&config)
+}
+
+// GetWorkDirConfig returns the process-wide working directory configuration.
+func GetWorkDirConfig() WorkDirConfig {
+	if config := _workDirConfig.Load(); config != nil {
+		return *config
+	}
+	return WorkDirConfig{}
+}
+
+// Manifest describes the Miden VM commands run in a kept working directory.
+type Manifest struct {
+	// Version of the Miden VM CLI, empty if unknown
+	MidenVersion string `json:"miden_version"`
+	// Version of the Go runtime
+	GoVersion string    `json:"go_version"`
+	Created   time.Time `json:"created"`
+	// Whether a command failed, or writing the working directory files
+	// failed
+	Failed   bool              `json:"failed"`
+	Error    string            `json:"error,omitempty"`
+	Commands []ManifestCommand `json:"commands"`
+}
+
+// ManifestCommand is a Miden VM command run in a working directory.  Paths
+// inside the working directory are relative to it.
+type ManifestCommand struct {
+	Args     []string      `json:"args"`
+	Start    time.Time     `json:"start"`
+	Duration time.Duration `json:"duration"`
+	// Exit code of the process, -1 if it did not exit normally
+	ExitCode int    `json:"exit_code"`
+	Error    string `json:"error,omitempty"`
+	Stderr   string `json:"stderr,omitempty"`
+}
+
+// ReadManifest reads the manifest of a kept working directory.
+func ReadManifest(dir string) (*Manifest, error) {
+	data, err := os.ReadFile(path.Join(dir, ManifestName))
+	if err != nil {
+		return nil, err
+	}
+
+	var m Manifest
+	if err = json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("miden: invalid manifest: %w", err)
+	}
+	return &m, nil
+}
+
+// Rerun runs the commands of a kept working directory again, in order,
+// overwriting the output and proof files in the directory.  Rerun stops at
+// the first failing command, and returns the manifest of the new run.  The
+// manifest in the directory is left unchanged.
+func Rerun(ctx context.Context, dir string) (*Manifest, error) {
+	m, err := ReadManifest(dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(m.Commands) == 0 {
+		return nil, errors.New("miden: no commands to rerun")
+	}
+
+	log, ctx := withCommandLog(ctx, dir)
+	for _, c := range m.Commands {
+		cmd := exec.CommandContext(ctx, "miden", c.Args...)
+		cmd.Dir = dir
+		if _, err = runCommand(ctx, cmd); err != nil {
+			break
+		}
+	}
+
+	return log.manifest(), err
+}
+
+// Records the Miden VM commands run on behalf of a driver.  Commands of a
+// driver started by another one, e.g. to verify a cached proof, are only
+// recorded in the log of the innermost driver: their paths are in its working
+// directory, so they could not be rerun from the enclosing one.
+type commandLog struct {
+	wd string
+
+	mu       sync.Mutex
+	failed   bool
+	commands []ManifestCommand
+}
+
+type commandLogKey struct{}
+
+func withCommandLog(ctx context.Context, wd string) (*commandLog, context.Context) {
+	log := &commandLog{wd: wd}
+	return log, context.WithValue(ctx, commandLogKey{}, log)
+}
+
+// Runs cmd and returns its standard output, as with cmd.Output, recording it
+// in the command log of ctx.
+func runCommand(ctx context.Context, cmd *exec.Cmd) ([]byte, error) {
+	start := time.Now()
+	out, err := cmd.Output()
+	recordCommand(ctx, cmd, start, err)
+	return out, err
+}
+
+func recordCommand(ctx context.Context, cmd *exec.Cmd, start time.Time, err error) {
+	log, _ := ctx.Value(commandLogKey{}).(*commandLog)
+	if log == nil {
+		return
+	}
+
+	c := ManifestCommand{
+		Start:    start,
+		Duration: time.Since(start),
+		ExitCode: -1,
+	}
+	if cmd.ProcessState != nil {
+		c.ExitCode = cmd.ProcessState.ExitCode()
+	}
+	if err != nil {
+		c.Error = err.Error()
+	}
+	var exitError *exec.ExitError
+	if errors.As(err, &exitError) {
+		c.Stderr = string(exitError.Stderr)
+	}
+
+	log.record(c, cmd.Args[1:], err != nil)
+}
+
+func (l *commandLog) record(c ManifestCommand, args []string, failed bool) {
+	c.Args = make([]string, len(args))
+	for i, arg := range args {
+		c.Args[i] = strings.TrimPrefix(arg, l.wd+"/")
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.commands = append(l.commands, c)
+	l.failed = l.failed || failed
+}
+
+func (l *commandLog) hasFailed() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.failed
+}
+
+func (l *commandLog) manifest() *Manifest {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	m := &Manifest{
+		GoVersion: runtime.Version(),
+		Created:   time.Now(),
+		Failed:    l.failed,
+		Commands:  append([]ManifestCommand(nil), l.commands...),
+	}
+
+	// The context of the run might be cancelled already
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	m.MidenVersion, _ = Version(ctx)
+
+	return m
+}
+
+// Writes the manifest of the driver commands to the working directory.
+// Assembly files outside the working directory, such as those of a
+// ProgramCache, are copied into it so that the directory is self-contained.
+func (d *driver) writeManifest() error {
+	m := d.log.manifest()
+	if d.err != nil {
+		m.Failed, m.Error = true, d.err.Error()
+	}
+
+	copied := make(map[string]bool)
+	for _, c := range m.Commands {
+		for i := 0; i+1 < len(c.Args); i++ {
+			src := c.Args[i+1]
+			if c.Args[i] != "--assembly" || !path.IsAbs(src) {
+				continue
+			}
+			if !copied[src] {
+				if _, err := os.Stat(d.assemblyPath()); err == nil {
+					continue
+				}
+				assembly, err := os.ReadFile(src)
+				if err != nil {
+					continue
+				}
+				if err = os.WriteFile(d.assemblyPath(), assembly, 0644); err != nil {
+					continue
+				}
+				copied[src] = true
+			}
+			c.Args[i+1] = path.Base(d.assemblyPath())
+		}
+	}
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path.Join(d.wd, ManifestName), data, 0644)
+}